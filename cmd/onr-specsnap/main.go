@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/r9s-ai/onr-lsp/internal/corespec"
+)
+
+var (
+	outputDir = flag.String("output-dir", "internal/corespec/snapshots", "directory that receives <version>.json")
+	core      = flag.String("core", "", "onr-core version to record (default: the linked module version)")
+)
+
+func main() {
+	flag.Parse()
+
+	spec := corespec.Current()
+	if *core != "" {
+		spec.Core = *core
+	}
+	if spec.Core == "" {
+		fatalf("cannot determine the onr-core version; pass -core vX.Y.Z")
+	}
+	if len(spec.Directives) == 0 {
+		fatalf("directive metadata is empty")
+	}
+
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		fatalf("marshal snapshot: %v", err)
+	}
+	path := filepath.Join(*outputDir, spec.Core+".json")
+	if err := os.MkdirAll(*outputDir, 0o755); err != nil {
+		fatalf("create output dir: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		fatalf("write snapshot: %v", err)
+	}
+	fmt.Printf("wrote %s (%d directives)\n", path, len(spec.Directives))
+}
+
+func fatalf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(os.Stderr, "onr-specsnap: "+format+"\n", args...)
+	os.Exit(1)
+}
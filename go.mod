@@ -5,6 +5,7 @@ go 1.25.10
 require (
 	github.com/r9s-ai/open-next-router/onr-core v1.15.4
 	github.com/spf13/cobra v1.10.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
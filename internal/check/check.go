@@ -0,0 +1,166 @@
+package check
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/r9s-ai/onr-lsp/internal/corespec"
+	"github.com/r9s-ai/onr-lsp/internal/httpheader"
+	"github.com/r9s-ai/onr-lsp/internal/jsonpath"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/onr-lsp/internal/migrate"
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// Options controls a headless check run.
+type Options struct {
+	// ConfigPath selects a lint config file for every checked file. When empty,
+	// each file uses the nearest lint.ConfigFileName above it.
+	ConfigPath string
+	// TargetCore overrides the targetCore of every lint config, e.g. "v1.14.x".
+	TargetCore string
+}
+
+// FileResult holds diagnostics collected for one DSL file.
+type FileResult struct {
+	Path        string
+	Diagnostics []lint.Diagnostic
+}
+
+// Result holds diagnostics for every checked file, ordered by path.
+type Result struct {
+	Files []FileResult
+}
+
+// Run collects DSL files from paths and returns their diagnostics after lint
+// rules and inline suppressions are applied.
+func Run(paths []string, opts Options) (Result, error) {
+	if opts.TargetCore != "" {
+		if _, err := corespec.Resolve(opts.TargetCore); err != nil {
+			return Result{}, fmt.Errorf("target onr-core: %w", err)
+		}
+	}
+	files, err := CollectFiles(paths)
+	if err != nil {
+		return Result{}, err
+	}
+	ws, err := workspace.Load(files, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	cross := ws.Diagnostics()
+	configs := newConfigLoader(opts.ConfigPath, opts.TargetCore)
+	out := Result{Files: make([]FileResult, 0, len(files))}
+	for _, path := range files {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return Result{}, fmt.Errorf("read file %q: %w", path, err)
+		}
+		cfg, err := configs.forFile(path)
+		if err != nil {
+			return Result{}, err
+		}
+		text := string(src)
+		diags := lint.Apply(text, dsllang.CollectDiagnostics(FileURI(path), text), cfg)
+		diags = migrate.Supersede(diags, lint.Filter(text, migrate.Diagnostics(text), cfg))
+		diags = append(diags, lint.Filter(text, jsonpath.Diagnostics(text), cfg)...)
+		diags = append(diags, lint.Filter(text, httpheader.Diagnostics(text), cfg)...)
+		diags = append(diags, lint.Filter(text, corespec.Diagnostics(text, cfg.TargetCore), cfg)...)
+		if abs, err := filepath.Abs(path); err == nil {
+			diags = append(diags, lint.Filter(text, cross[abs], cfg)...)
+		}
+		sortDiagnostics(diags)
+		out.Files = append(out.Files, FileResult{Path: path, Diagnostics: diags})
+	}
+	return out, nil
+}
+
+// configLoader caches lint configs by directory.
+type configLoader struct {
+	explicit string
+	// target overrides the targetCore of every config.
+	target string
+	byDir  map[string]lint.Config
+}
+
+func newConfigLoader(explicit, target string) *configLoader {
+	return &configLoader{explicit: explicit, target: target, byDir: map[string]lint.Config{}}
+}
+
+func (l *configLoader) forFile(path string) (lint.Config, error) {
+	dir := filepath.Dir(path)
+	if l.explicit != "" {
+		dir = ""
+	}
+	if cfg, ok := l.byDir[dir]; ok {
+		return cfg, nil
+	}
+	var (
+		cfg     lint.Config
+		cfgPath = l.explicit
+		err     error
+	)
+	if l.explicit != "" {
+		cfg, err = lint.LoadConfigFile(l.explicit)
+	} else {
+		cfg, cfgPath, err = lint.LoadConfig(dir)
+	}
+	if err != nil {
+		return lint.Config{}, err
+	}
+	if l.target != "" {
+		cfg.TargetCore = l.target
+	} else if cfg.TargetCore != "" {
+		if _, err := corespec.Resolve(cfg.TargetCore); err != nil {
+			return lint.Config{}, fmt.Errorf("%s: targetCore: %w", cfgPath, err)
+		}
+	}
+	l.byDir[dir] = cfg
+	return cfg, nil
+}
+
+// CollectFiles expands files and directories into a sorted list of .conf files.
+// Hidden directories are skipped when walking.
+func CollectFiles(paths []string) ([]string, error) {
+	return workspace.CollectFiles(paths)
+}
+
+// FileURI returns a file:// URI for path so contextual validation can locate
+// sibling onr.conf, providers and modes files.
+func FileURI(path string) string {
+	return workspace.FileURI(path)
+}
+
+// CountAtLeast returns the number of diagnostics at least as severe as
+// severity. Missing severities are treated as errors.
+func (r Result) CountAtLeast(severity int) int {
+	n := 0
+	for _, file := range r.Files {
+		for _, d := range file.Diagnostics {
+			if effectiveSeverity(d.Severity) <= severity {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func effectiveSeverity(severity int) int {
+	if severity <= 0 {
+		return lint.SeverityError
+	}
+	return severity
+}
+
+func sortDiagnostics(diags []lint.Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Range.Start, diags[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+}
@@ -0,0 +1,145 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+func TestCollectFilesWalksDirectoriesAndSkipsHidden(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, rel := range []string{"onr.conf", "providers/a.conf", "providers/notes.txt", ".git/x.conf"} {
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(""), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	got, err := CollectFiles([]string{dir, filepath.Join(dir, "onr.conf")})
+	if err != nil {
+		t.Fatalf("collect files: %v", err)
+	}
+	want := []string{filepath.Join(dir, "onr.conf"), filepath.Join(dir, "providers", "a.conf")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected files\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestCollectFilesMissingPath(t *testing.T) {
+	t.Parallel()
+
+	if _, err := CollectFiles([]string{filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Fatalf("expected error for missing path")
+	}
+}
+
+func TestCountAtLeast(t *testing.T) {
+	t.Parallel()
+
+	res := Result{Files: []FileResult{{
+		Path: "a.conf",
+		Diagnostics: []lint.Diagnostic{
+			{Severity: lint.SeverityError},
+			{Severity: lint.SeverityWarning},
+			{Severity: 0},
+		},
+	}}}
+	if got := res.CountAtLeast(lint.SeverityError); got != 2 {
+		t.Fatalf("expected 2 errors, got %d", got)
+	}
+	if got := res.CountAtLeast(lint.SeverityWarning); got != 3 {
+		t.Fatalf("expected 3 warnings or worse, got %d", got)
+	}
+}
+
+func TestRunAppliesProjectLintConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, lint.ConfigFileName), []byte(`{"rules": {"unknown-directive": "warning"}}`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.conf"), []byte("unknown_top foo;\n"), 0o600); err != nil {
+		t.Fatalf("write conf: %v", err)
+	}
+
+	res, err := Run([]string{dir}, Options{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Files) != 1 || len(res.Files[0].Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic, got %+v", res)
+	}
+	d := res.Files[0].Diagnostics[0]
+	if d.Code != lint.RuleUnknownDirective || d.Severity != lint.SeverityWarning {
+		t.Fatalf("unexpected diagnostic: %+v", d)
+	}
+	if res.CountAtLeast(lint.SeverityError) != 0 {
+		t.Fatalf("warning must not count as error")
+	}
+}
+
+func TestRunExplicitConfigOverridesProjectConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.conf"), []byte("unknown_top foo;\n"), 0o600); err != nil {
+		t.Fatalf("write conf: %v", err)
+	}
+	cfgPath := filepath.Join(t.TempDir(), "lint.json")
+	if err := os.WriteFile(cfgPath, []byte(`{"rules": {"unknown-directive": "off"}}`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	res, err := Run([]string{dir}, Options{ConfigPath: cfgPath})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Files) != 1 || len(res.Files[0].Diagnostics) != 0 {
+		t.Fatalf("expected rule disabled by explicit config, got %+v", res)
+	}
+}
+
+func TestRunReportsCrossFileDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	providers := filepath.Join(dir, "providers")
+	if err := os.MkdirAll(providers, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, name := range []string{"a.conf", "b.conf"} {
+		if err := os.WriteFile(filepath.Join(providers, name), []byte("provider \"openai\" {\n}\n"), 0o600); err != nil {
+			t.Fatalf("write conf: %v", err)
+		}
+	}
+	// Only a.conf is checked; the root config brings b.conf into context.
+	if err := os.WriteFile(filepath.Join(dir, "onr.conf"), []byte("include providers;\n"), 0o600); err != nil {
+		t.Fatalf("write root: %v", err)
+	}
+
+	res, err := Run([]string{filepath.Join(providers, "a.conf")}, Options{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Files) != 1 {
+		t.Fatalf("expected only the requested file, got %+v", res)
+	}
+	var found bool
+	for _, d := range res.Files[0].Diagnostics {
+		if d.Code == lint.RuleDuplicateProvider && len(d.RelatedInformation) == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected duplicate-provider diagnostic, got %+v", res.Files[0].Diagnostics)
+	}
+}
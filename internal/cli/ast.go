@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/r9s-ai/onr-lsp/internal/dsltree"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+	"github.com/spf13/cobra"
+)
+
+type astOptions struct {
+	format   string
+	noRanges bool
+}
+
+// newASTCmd returns a non-nil ast command.
+func newASTCmd(opts Options) *cobra.Command {
+	astOpts := astOptions{format: "json"}
+	cmd := &cobra.Command{
+		Use:   "ast [file|-]",
+		Short: "Print the syntax tree of an ONR DSL document as JSON or YAML",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("ast accepts at most one file path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if astOpts.format != "json" && astOpts.format != "yaml" {
+				return fmt.Errorf("unsupported ast format %q (want json, yaml)", astOpts.format)
+			}
+			path := "-"
+			if len(args) == 1 {
+				path = args[0]
+			}
+			src, err := readFormatSource(path, opts.Stdin)
+			if err != nil {
+				return err
+			}
+			tree := dsltree.Parse(string(src))
+			if astOpts.noRanges {
+				tree.StripRanges()
+			}
+			if astOpts.format == "yaml" {
+				return dsltree.WriteYAML(opts.Stdout, tree)
+			}
+			return dsltree.WriteJSON(opts.Stdout, tree)
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&astOpts.format, "format", "json", "output format: json|yaml")
+	fs.BoolVar(&astOpts.noRanges, "no-ranges", false, "omit source ranges")
+	return cmd
+}
+
+type renderOptions struct {
+	format  string
+	tabSize int
+	useTabs bool
+	output  string
+}
+
+// newRenderCmd returns a non-nil render command.
+func newRenderCmd(opts Options) *cobra.Command {
+	renderOpts := renderOptions{tabSize: 2}
+	cmd := &cobra.Command{
+		Use:   "render [tree.json|tree.yaml|-]",
+		Short: "Render a JSON or YAML syntax tree back into formatted DSL",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("render accepts at most one tree path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "-"
+			if len(args) == 1 {
+				path = args[0]
+			}
+			format := renderOpts.format
+			if format == "" {
+				format = "json"
+				if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+					format = "yaml"
+				}
+			}
+			if format != "json" && format != "yaml" {
+				return fmt.Errorf("unsupported tree format %q (want json, yaml)", format)
+			}
+			src, err := readFormatSource(path, opts.Stdin)
+			if err != nil {
+				return err
+			}
+			read := dsltree.ReadJSON
+			if format == "yaml" {
+				read = dsltree.ReadYAML
+			}
+			tree, err := read(bytes.NewReader(src))
+			if err != nil {
+				return err
+			}
+			text, err := dsltree.Render(tree, dsllang.FormatOptions{
+				TabSize:      renderOpts.tabSize,
+				InsertSpaces: !renderOpts.useTabs,
+			})
+			if err != nil {
+				return err
+			}
+			if renderOpts.output != "" {
+				return os.WriteFile(renderOpts.output, []byte(text), 0o644)
+			}
+			_, err = io.WriteString(opts.Stdout, text)
+			return err
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&renderOpts.format, "format", "", "input format: json|yaml (default from the file extension, else json)")
+	fs.IntVar(&renderOpts.tabSize, "tab-size", 2, "tab size when using spaces")
+	fs.BoolVar(&renderOpts.useTabs, "tabs", false, "use tabs for indentation")
+	fs.StringVarP(&renderOpts.output, "output", "o", "", "write the rendered config to a file instead of stdout")
+	return cmd
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+func TestASTAndRenderRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	src := "provider \"p\" { # main\n  defaults { request { req_map openai_chat; } }\n}\n"
+	var tree bytes.Buffer
+	err := Run([]string{"ast", "--no-ranges"}, Options{
+		Stdin:       strings.NewReader(src),
+		Stdout:      &tree,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("ast: %v", err)
+	}
+	if strings.Contains(tree.String(), `"range"`) || !strings.Contains(tree.String(), `"trailingComment"`) {
+		t.Fatalf("unexpected tree:\n%s", tree.String())
+	}
+
+	var out bytes.Buffer
+	err = Run([]string{"render"}, Options{
+		Stdin:       &tree,
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	want := "provider \"p\" {\n  # main\n  defaults {\n    request {\n      req_map openai_chat;\n    }\n  }\n}\n"
+	if out.String() != want {
+		t.Fatalf("unexpected render:\n%s", out.String())
+	}
+}
+
+func TestASTAndRenderRoundTripCorpus(t *testing.T) {
+	t.Parallel()
+
+	files, err := filepath.Glob(filepath.Join("..", "dsltree", "testdata", "*.conf"))
+	nested, _ := filepath.Glob(filepath.Join("..", "dsltree", "testdata", "*", "*.conf"))
+	files = append(files, nested...)
+	if err != nil || len(files) == 0 {
+		t.Fatalf("glob corpus: %v (%d files)", err, len(files))
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		want := dsllang.FormatText(string(src), dsllang.FormatOptions{TabSize: 2, InsertSpaces: true})
+		for _, format := range []string{"json", "yaml"} {
+			var tree, out bytes.Buffer
+			err := Run([]string{"ast", "--format", format, file}, Options{
+				Stdout:      &tree,
+				Stderr:      &bytes.Buffer{},
+				ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+			})
+			if err != nil {
+				t.Fatalf("%s: ast --format %s: %v", file, format, err)
+			}
+			err = Run([]string{"render", "--format", format}, Options{
+				Stdin:       &tree,
+				Stdout:      &out,
+				Stderr:      &bytes.Buffer{},
+				ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+			})
+			if err != nil {
+				t.Fatalf("%s: render --format %s: %v", file, format, err)
+			}
+			if out.String() != want {
+				t.Fatalf("%s: %s round trip differs from format:\n%s", file, format, out.String())
+			}
+		}
+	}
+}
+
+func TestRenderReadsYAMLByExtension(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "tree.yml")
+	if err := os.WriteFile(path, []byte("version: 1\nstatements:\n  - name: syntax\n    args: [{value: next-router/0.1, quoted: true}]\n"), 0o600); err != nil {
+		t.Fatalf("write tree: %v", err)
+	}
+	var out bytes.Buffer
+	err := Run([]string{"render", path}, Options{
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if out.String() != "syntax \"next-router/0.1\";\n" {
+		t.Fatalf("unexpected render:\n%s", out.String())
+	}
+}
+
+func TestASTRejectsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	err := Run([]string{"ast", "--format", "toml"}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported ast format") {
+		t.Fatalf("expected format error, got: %v", err)
+	}
+}
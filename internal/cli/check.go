@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/onr-lsp/internal/report"
+	"github.com/spf13/cobra"
+)
+
+type checkOptions struct {
+	failOn       string
+	outputFormat string
+	configPath   string
+	targetCore   string
+}
+
+// newCheckCmd returns a non-nil check command.
+func newCheckCmd(opts Options) *cobra.Command {
+	checkOpts := checkOptions{failOn: "error", outputFormat: "text"}
+	cmd := &cobra.Command{
+		Use:   "check [paths...]",
+		Short: "Validate ONR DSL files and print diagnostics",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			threshold, ok := lint.ParseSeverity(checkOpts.failOn)
+			if !ok || threshold == lint.SeverityOff {
+				return fmt.Errorf("invalid --fail-on value %q (want error, warning, info or hint)", checkOpts.failOn)
+			}
+			if !report.Supported(checkOpts.outputFormat) {
+				return fmt.Errorf("unsupported output format %q (want %s)", checkOpts.outputFormat, strings.Join(report.Formats(), ", "))
+			}
+			res, err := check.Run(args, check.Options{ConfigPath: checkOpts.configPath, TargetCore: checkOpts.targetCore})
+			if err != nil {
+				return err
+			}
+			if err := report.Write(opts.Stdout, checkOpts.outputFormat, res); err != nil {
+				return err
+			}
+			if n := res.CountAtLeast(threshold); n > 0 {
+				return fmt.Errorf("check found %d problem(s)", n)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&checkOpts.failOn, "fail-on", "error", "lowest severity that fails the check: error, warning, info or hint")
+	fs.StringVar(&checkOpts.outputFormat, "output-format", "text", "diagnostic output format: "+strings.Join(report.Formats(), "|"))
+	fs.StringVar(&checkOpts.configPath, "config", "", "lint config file (default: nearest "+lint.ConfigFileName+" above each file)")
+	fs.StringVar(&checkOpts.targetCore, "target-core", "", "onr-core release the configs must run on, e.g. v1.14.x (default: targetCore from the lint config, else derived from each file's syntax directive)")
+	return cmd
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckReportsDiagnosticsAndFails(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bad.conf")
+	if err := os.WriteFile(path, []byte("unknown_top foo;\n"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	var out bytes.Buffer
+	err := Run([]string{"check", dir}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "problem(s)") {
+		t.Fatalf("expected check failure, got: %v", err)
+	}
+	want := path + ":1:1: error: unknown top-level directive: unknown_top"
+	if !strings.Contains(out.String(), want) {
+		t.Fatalf("unexpected check output\n--- got ---\n%s\n--- want line ---\n%s", out.String(), want)
+	}
+}
+
+func TestCheckCleanFileSucceeds(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "onr.conf")
+	if err := os.WriteFile(path, []byte("syntax \"next-router/0.1\";\n"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	var out bytes.Buffer
+	err := Run([]string{"check", path}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("run check command: %v\n%s", err, out.String())
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no output, got: %q", out.String())
+	}
+}
+
+func TestCheckCorpusHasNoDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	err := Run([]string{"check", "--fail-on", "hint", filepath.Join("..", "dsltree", "testdata")}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil || out.Len() != 0 {
+		t.Fatalf("expected a clean corpus, got %v:\n%s", err, out.String())
+	}
+}
+
+func TestCheckRejectsInvalidFailOn(t *testing.T) {
+	t.Parallel()
+
+	err := Run([]string{"check", "--fail-on", "fatal", "."}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid --fail-on") {
+		t.Fatalf("expected --fail-on error, got: %v", err)
+	}
+}
+
+func TestCheckOutputFormatGitHub(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bad.conf")
+	if err := os.WriteFile(path, []byte("unknown_top foo;\n"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	var out bytes.Buffer
+	_ = Run([]string{"check", "--output-format", "github", path}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if !strings.HasPrefix(out.String(), "::error file=") || !strings.Contains(out.String(), "unknown top-level directive") {
+		t.Fatalf("unexpected github output: %q", out.String())
+	}
+}
+
+func TestCheckRejectsUnknownOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	err := Run([]string{"check", "--output-format", "xml", dir}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported output format") {
+		t.Fatalf("expected output format error, got: %v", err)
+	}
+}
+
+func TestCheckTargetCore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "onr.conf")
+	if err := os.WriteFile(path, []byte("syntax \"next-router/0.1\";\n"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	var stdout bytes.Buffer
+	run := func(target string) error {
+		stdout.Reset()
+		return Run([]string{"check", "--target-core", target, path}, Options{
+			Stdin:       strings.NewReader(""),
+			Stdout:      &stdout,
+			Stderr:      &bytes.Buffer{},
+			ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+		})
+	}
+	if err := run("v1.15.x"); err != nil {
+		t.Fatalf("check against the linked release: %v", err)
+	}
+	err := run("v1.0.x")
+	if err == nil || err.Error() != "target onr-core: no dslspec snapshot for onr-core v1.0.x (known: v1.15.4)" {
+		t.Fatalf("an unknown target must fail once, got %v", err)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("an unknown target must not produce diagnostics:\n%s", stdout.String())
+	}
+
+	cfgPath := filepath.Join(dir, ".onr-lsp.json")
+	if err := os.WriteFile(cfgPath, []byte(`{"targetCore": "v1.0.x"}`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	err = Run([]string{"check", path}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &stdout,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), cfgPath+": targetCore: no dslspec snapshot for onr-core v1.0.x") {
+		t.Fatalf("an unknown config target must fail once, got %v", err)
+	}
+	if err := run("v1.15.x"); err != nil {
+		t.Fatalf("--target-core must override the config target: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+type diffOptions struct {
+	outputFormat string
+	exitCode     bool
+}
+
+// newDiffCmd returns a non-nil diff command.
+func newDiffCmd(opts Options) *cobra.Command {
+	diffOpts := diffOptions{outputFormat: "text"}
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Compare two configs by structure instead of text",
+		Long: "Compare two config files, or two directories file by file, by parsed structure.\n" +
+			"Either side may be a git revision and path such as HEAD~1:config/, which is\n" +
+			"exported to a temporary directory first.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("diff expects an old and a new path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if diffOpts.outputFormat != "text" && diffOpts.outputFormat != "json" {
+				return fmt.Errorf("unsupported output format %q (want text, json)", diffOpts.outputFormat)
+			}
+			tmp, err := os.MkdirTemp("", "onr-lsp-diff-")
+			if err != nil {
+				return err
+			}
+			defer func() { _ = os.RemoveAll(tmp) }()
+			oldPath, err := resolveDiffSide(args[0], filepath.Join(tmp, "old"))
+			if err != nil {
+				return err
+			}
+			newPath, err := resolveDiffSide(args[1], filepath.Join(tmp, "new"))
+			if err != nil {
+				return err
+			}
+			files, err := diff.Paths(oldPath, newPath)
+			if err != nil {
+				return err
+			}
+			if diffOpts.outputFormat == "json" {
+				err = writeDiffJSON(opts.Stdout, files)
+			} else {
+				err = writeDiffText(opts.Stdout, files)
+			}
+			if err != nil {
+				return err
+			}
+			if diffOpts.exitCode && len(files) > 0 {
+				return errors.New("configurations differ")
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&diffOpts.outputFormat, "output-format", "text", "output format: text|json")
+	fs.BoolVar(&diffOpts.exitCode, "exit-code", false, "exit non-zero when the configurations differ")
+	return cmd
+}
+
+// resolveDiffSide returns arg when it exists on disk; otherwise a REV:PATH
+// argument is exported from git into dst.
+func resolveDiffSide(arg, dst string) (string, error) {
+	if _, err := os.Stat(arg); err == nil {
+		return arg, nil
+	}
+	rev, target, ok := strings.Cut(arg, ":")
+	if !ok || rev == "" {
+		return arg, nil
+	}
+	return diff.ExportGitPath(rev, target, dst)
+}
+
+func writeDiffText(w io.Writer, files []diff.FileDiff) error {
+	var b strings.Builder
+	for _, f := range files {
+		indent := ""
+		if f.Path != "" {
+			indent = "  "
+			b.WriteString(f.Path)
+			if f.Status != "" {
+				b.WriteString(" (" + string(f.Status) + ")")
+			}
+			b.WriteByte('\n')
+		}
+		for _, c := range f.Changes {
+			b.WriteString(indent + c.String() + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeDiffJSON(w io.Writer, files []diff.FileDiff) error {
+	if files == nil {
+		files = []diff.FileDiff{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{"files": files})
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffPrintsStructuralChanges(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.conf")
+	newPath := filepath.Join(dir, "new.conf")
+	if err := os.WriteFile(oldPath, []byte("provider \"p\" {\n  defaults { request { req_map openai_chat; } }\n}\n"), 0o600); err != nil {
+		t.Fatalf("write old: %v", err)
+	}
+	if err := os.WriteFile(newPath, []byte("provider \"p\" {\n  defaults {\n    request {\n      req_map anthropic_to_openai_chat;\n    }\n  }\n}\n"), 0o600); err != nil {
+		t.Fatalf("write new: %v", err)
+	}
+
+	var out bytes.Buffer
+	err := Run([]string{"diff", "--exit-code", oldPath, newPath}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "differ") {
+		t.Fatalf("expected --exit-code error, got: %v", err)
+	}
+	want := "~ provider \"p\" > defaults > request > req_map: openai_chat → anthropic_to_openai_chat\n"
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestResolveDiffSideExportsGitRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	gitRun := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	gitRun("init", "-q")
+	if err := os.MkdirAll(filepath.Join(repo, "config", "providers"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for name, text := range map[string]string{
+		"p.conf":           "provider \"p\" {\n}\n",
+		"my provider.conf": "provider \"q\" {\n}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(repo, "config", "providers", name), []byte(text), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	gitRun("add", ".")
+	gitRun("commit", "-q", "-m", "init")
+
+	t.Chdir(filepath.Join(repo, "config"))
+
+	dst := filepath.Join(t.TempDir(), "old")
+	got, err := resolveDiffSide("HEAD:providers", dst)
+	if err != nil {
+		t.Fatalf("resolveDiffSide: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(got, "p.conf"))
+	if err != nil || string(data) != "provider \"p\" {\n}\n" {
+		t.Fatalf("unexpected export %q: %v %q", got, err, data)
+	}
+	data, err = os.ReadFile(filepath.Join(got, "my provider.conf"))
+	if err != nil || string(data) != "provider \"q\" {\n}\n" {
+		t.Fatalf("file with a space in its name not exported: %v %q", err, data)
+	}
+}
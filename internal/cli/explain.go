@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/r9s-ai/onr-lsp/internal/corespec"
+	"github.com/spf13/cobra"
+)
+
+type explainOptions struct {
+	block  string
+	list   bool
+	format string
+}
+
+// newExplainCmd returns a non-nil explain command.
+func newExplainCmd(opts Options) *cobra.Command {
+	explainOpts := explainOptions{format: "text"}
+	cmd := &cobra.Command{
+		Use:   "explain <directive|mode>",
+		Short: "Print the documentation of a directive or mode",
+		Long: "Print the hover documentation of a directive together with the blocks it is\n" +
+			"allowed in, its modes, argument values and an example. A mode value lists the\n" +
+			"directives that accept it. Use --list to browse all directives by block.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if explainOpts.list && len(args) > 0 {
+				return errors.New("explain --list takes no arguments")
+			}
+			if !explainOpts.list && len(args) != 1 {
+				return errors.New("explain expects one directive or mode")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if explainOpts.format != "text" && explainOpts.format != "markdown" {
+				return fmt.Errorf("unsupported format %q (want text, markdown)", explainOpts.format)
+			}
+			spec := corespec.Current()
+			var md string
+			if explainOpts.list {
+				md = spec.List()
+			} else {
+				topic, err := spec.Explain(args[0], explainOpts.block)
+				if err != nil {
+					return err
+				}
+				md = topic.Markdown(spec)
+			}
+			if explainOpts.format == "text" {
+				md = corespec.PlainText(md)
+			}
+			_, err := io.WriteString(opts.Stdout, md)
+			return err
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&explainOpts.block, "block", "", "only explain the directive in this block, e.g. request")
+	fs.BoolVar(&explainOpts.list, "list", false, "list all directives grouped by block")
+	fs.StringVar(&explainOpts.format, "format", "text", "output format: text|markdown")
+	return cmd
+}
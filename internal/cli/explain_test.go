@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runExplain(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := Run(append([]string{"explain"}, args...), Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	return out.String(), err
+}
+
+func TestExplainDirective(t *testing.T) {
+	t.Parallel()
+
+	out, err := runExplain(t, "balance_unit", "--block", "balance")
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	for _, want := range []string{
+		"balance_unit in balance\n",
+		"Allowed in: balance_mode, balance.",
+		"Argument 1 (unit): one of USD, CNY.",
+		"Example: balance_unit USD;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("explain output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "`") {
+		t.Fatalf("text output contains markdown:\n%s", out)
+	}
+
+	md, err := runExplain(t, "req_map", "--format", "markdown")
+	if err != nil || !strings.HasPrefix(md, "## `req_map` in `request`\n") || !strings.Contains(md, "Example: `req_map openai_chat_to_openai_responses;`") {
+		t.Fatalf("markdown explain: %v\n%s", err, md)
+	}
+}
+
+func TestExplainModeAndList(t *testing.T) {
+	t.Parallel()
+
+	out, err := runExplain(t, "openai_chat_to_openai_responses")
+	if err != nil || !strings.Contains(out, "- req_map in request: req_map openai_chat_to_openai_responses;") {
+		t.Fatalf("explain mode: %v\n%s", err, out)
+	}
+
+	list, err := runExplain(t, "--list", "--format", "markdown")
+	if err != nil || !strings.HasPrefix(list, "## `top`\n") || !strings.Contains(list, "- `req_map`: Map request JSON between API schemas.") {
+		t.Fatalf("explain --list: %v\n%s", err, list)
+	}
+}
+
+func TestExplainErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := runExplain(t, "req_map", "--block", "auth"); err == nil || !strings.Contains(err.Error(), "req_map is not allowed in auth (allowed in: request)") {
+		t.Fatalf("unexpected block error: %v", err)
+	}
+	if _, err := runExplain(t, "nope"); err == nil || !strings.Contains(err.Error(), `unknown directive or mode "nope"`) {
+		t.Fatalf("unexpected unknown error: %v", err)
+	}
+	if _, err := runExplain(t, "--list", "req_map"); err == nil {
+		t.Fatal("expected error for --list with an argument")
+	}
+	if _, err := runExplain(t, "req_map", "--format", "html"); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/graph"
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+type graphOptions struct {
+	format string
+}
+
+// newGraphCmd returns a non-nil graph command.
+func newGraphCmd(opts Options) *cobra.Command {
+	graphOpts := graphOptions{format: "dot"}
+	cmd := &cobra.Command{
+		Use:   "graph [root.conf|dir]",
+		Short: "Print the include and preset usage graph",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("graph accepts at most one root config or directory")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !graph.Supported(graphOpts.format) {
+				return fmt.Errorf("unsupported graph format %q (want %s)", graphOpts.format, strings.Join(graph.Formats(), ", "))
+			}
+			target := "."
+			if len(args) == 1 {
+				target = args[0]
+			}
+			g, err := buildGraph(target)
+			if err != nil {
+				return err
+			}
+			return graph.Write(opts.Stdout, graphOpts.format, g)
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&graphOpts.format, "format", "dot", "output format: "+strings.Join(graph.Formats(), "|"))
+	return cmd
+}
+
+// buildGraph graphs a root config and everything it includes, or every
+// config file under a directory.
+func buildGraph(target string) (graph.Graph, error) {
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return graph.Graph{}, err
+	}
+	st, err := os.Stat(abs)
+	if err != nil {
+		return graph.Graph{}, fmt.Errorf("stat %q: %w", target, err)
+	}
+	if st.IsDir() {
+		ws, err := workspace.Load([]string{abs}, nil)
+		if err != nil {
+			return graph.Graph{}, err
+		}
+		return graph.Build(ws, ws.Documents(), abs), nil
+	}
+	ws := workspace.New(nil)
+	ws.Add(abs)
+	doc, ok := ws.Document(abs)
+	if !ok {
+		return graph.Graph{}, fmt.Errorf("read file %q", target)
+	}
+	return graph.Build(ws, ws.Reachable(doc), filepath.Dir(abs)), nil
+}
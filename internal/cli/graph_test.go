@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGraphPrintsMermaidForRoot(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "onr.conf"), []byte("include p.conf;\n"), 0o600); err != nil {
+		t.Fatalf("write root: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "p.conf"), []byte("provider \"p\" {\n}\n"), 0o600); err != nil {
+		t.Fatalf("write provider: %v", err)
+	}
+
+	var out bytes.Buffer
+	err := Run([]string{"graph", "--format", "mermaid", filepath.Join(dir, "onr.conf")}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("graph: %v", err)
+	}
+	for _, want := range []string{"flowchart LR", `n0[/"onr.conf"/]`, "n0 --> n1", `n2["provider #quot;p#quot;"]`} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestGraphRejectsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	err := Run([]string{"graph", "--format", "png", t.TempDir()}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported graph format") {
+		t.Fatalf("expected format error, got: %v", err)
+	}
+}
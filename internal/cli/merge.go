@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/r9s-ai/onr-lsp/internal/merge"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+	"github.com/spf13/cobra"
+)
+
+type mergeOptions struct {
+	tabSize int
+	useTabs bool
+	output  string
+}
+
+// newMergeCmd returns a non-nil merge command.
+func newMergeCmd(opts Options) *cobra.Command {
+	mergeOpts := mergeOptions{tabSize: 2}
+	cmd := &cobra.Command{
+		Use:   "merge <root.conf>",
+		Short: "Print a root config with every include inlined",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("merge expects exactly one root config path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			merged, err := merge.Merge(args[0], merge.Options{Format: dsllang.FormatOptions{
+				TabSize:      mergeOpts.tabSize,
+				InsertSpaces: !mergeOpts.useTabs,
+			}})
+			if err != nil {
+				return err
+			}
+			if mergeOpts.output != "" {
+				return os.WriteFile(mergeOpts.output, []byte(merged), 0o644)
+			}
+			_, err = io.WriteString(opts.Stdout, merged)
+			return err
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.IntVar(&mergeOpts.tabSize, "tab-size", 2, "tab size when using spaces")
+	fs.BoolVar(&mergeOpts.useTabs, "tabs", false, "use tabs for indentation")
+	fs.StringVarP(&mergeOpts.output, "output", "o", "", "write the merged config to a file instead of stdout")
+	return cmd
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergePrintsFlattenedConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "providers"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "onr.conf"), []byte("include providers;\n"), 0o600); err != nil {
+		t.Fatalf("write root: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "providers", "p.conf"), []byte("provider \"p\" { defaults { auth { auth_bearer; } } }\n"), 0o600); err != nil {
+		t.Fatalf("write provider: %v", err)
+	}
+
+	var out bytes.Buffer
+	err := Run([]string{"merge", "--tabs", filepath.Join(dir, "onr.conf")}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	want := "# from: providers/p.conf\nprovider \"p\" {\n\tdefaults {\n\t\tauth {\n\t\t\tauth_bearer;\n\t\t}\n\t}\n}\n"
+	if out.String() != want {
+		t.Fatalf("unexpected merge output:\n%q", out.String())
+	}
+}
+
+func TestMergeRequiresOnePath(t *testing.T) {
+	t.Parallel()
+
+	err := Run([]string{"merge"}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "exactly one") {
+		t.Fatalf("expected argument error, got: %v", err)
+	}
+}
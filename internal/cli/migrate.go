@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/migrate"
+	"github.com/r9s-ai/onr-lsp/internal/rewrite"
+	"github.com/spf13/cobra"
+)
+
+type migrateOptions struct {
+	target string
+	write  bool
+	list   bool
+}
+
+// newMigrateCmd returns a non-nil migrate command.
+func newMigrateCmd(opts Options) *cobra.Command {
+	migrateOpts := migrateOptions{}
+	cmd := &cobra.Command{
+		Use:   "migrate [paths...]",
+		Short: "Rewrite directives removed in newer DSL syntax versions",
+		Long: "Rewrite directives that newer DSL syntax versions removed, such as header_set to set_header.\n" +
+			"A diff is printed unless -w is given. Statements that cannot be migrated automatically are\n" +
+			"reported on stderr and make the command fail.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if migrateOpts.list {
+				return writeMigrateTable(opts.Stdout, migrateOpts.target)
+			}
+			if _, err := migrate.Table(migrateOpts.target); err != nil {
+				return err
+			}
+			files, err := check.CollectFiles(args)
+			if err != nil {
+				return err
+			}
+			migrated, manual := 0, 0
+			for _, path := range files {
+				src, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("read file %q: %w", path, err)
+				}
+				text, n, rest, err := migrate.Apply(string(src), migrateOpts.target)
+				if err != nil {
+					return err
+				}
+				migrated += n
+				manual += len(rest)
+				for _, f := range rest {
+					pos := f.Stmt.NameRange.Start
+					if _, err := fmt.Fprintf(opts.Stderr, "%s:%d:%d: %s (cannot migrate: %s)\n", path, pos.Line+1, pos.Character+1, f.Message(), f.Problem); err != nil {
+						return err
+					}
+				}
+				if text == string(src) {
+					continue
+				}
+				if migrateOpts.write {
+					if err := writeFormattedOutput(path, src, text); err != nil {
+						return err
+					}
+					continue
+				}
+				if _, err := io.WriteString(opts.Stdout, rewrite.Unified(path, string(src), text)); err != nil {
+					return err
+				}
+			}
+			if migrateOpts.write {
+				if _, err := fmt.Fprintf(opts.Stderr, "migrated %d statement(s)\n", migrated); err != nil {
+					return err
+				}
+			}
+			if manual > 0 {
+				return fmt.Errorf("%d statement(s) need manual migration", manual)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&migrateOpts.target, "to", migrate.Latest(), "syntax version to migrate to")
+	fs.BoolVarP(&migrateOpts.write, "write", "w", false, "write results back to files instead of printing a diff")
+	fs.BoolVar(&migrateOpts.list, "list", false, "print the migration table and exit")
+	return cmd
+}
+
+func writeMigrateTable(w io.Writer, target string) error {
+	entries, err := migrate.Table(target)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		block := e.Block
+		if block == "" {
+			block = "*"
+		}
+		replacement := e.Replacement
+		if replacement == "" {
+			replacement = "-"
+		}
+		line := fmt.Sprintf("%s\t%s\t%s -> %s", e.Syntax, block, e.Name, replacement)
+		if e.Note != "" {
+			line += "\t" + e.Note
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigratePrintsDiffWritesAndReportsManualWork(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "p.conf")
+	src := "provider \"p\" {\n  defaults {\n    request {\n      header_set \"X-A\" \"1\";\n    }\n    balance {\n      used = $.used;\n      used_expr = $.total;\n    }\n  }\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out, stderr bytes.Buffer
+	err := Run([]string{"migrate", dir}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &stderr,
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || err.Error() != "1 statement(s) need manual migration" {
+		t.Fatalf("expected manual migration error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "-      header_set \"X-A\" \"1\";\n+      set_header \"X-A\" \"1\";\n") {
+		t.Fatalf("unexpected diff:\n%s", out.String())
+	}
+	want := path + ":7:7: used was removed in next-router/0.1; use used_expr (cannot migrate: used_expr is already set in this balance block)\n"
+	if stderr.String() != want {
+		t.Fatalf("unexpected report:\n%s", stderr.String())
+	}
+	if data, _ := os.ReadFile(path); string(data) != src {
+		t.Fatalf("diff mode must not write:\n%s", data)
+	}
+
+	stderr.Reset()
+	err = Run([]string{"migrate", "-w", path}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &stderr,
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil {
+		t.Fatalf("expected manual migration error")
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "set_header \"X-A\" \"1\";") || !strings.HasSuffix(stderr.String(), "migrated 1 statement(s)\n") {
+		t.Fatalf("unexpected write result %q:\n%s", stderr.String(), data)
+	}
+}
+
+func TestMigrateListAndUnknownTarget(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	err := Run([]string{"migrate", "--list"}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("migrate --list: %v", err)
+	}
+	if !strings.Contains(out.String(), "next-router/0.1\tbalance\tused -> used_expr\n") {
+		t.Fatalf("unexpected table:\n%s", out.String())
+	}
+
+	err = Run([]string{"migrate", "--to", "next-router/9.9", t.TempDir()}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown syntax version") {
+		t.Fatalf("expected unknown version error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+type newProviderOptions struct {
+	family       string
+	baseURL      string
+	dir          string
+	templateDirs []string
+	force        bool
+	stdout       bool
+}
+
+// newNewCmd returns a non-nil new command.
+func newNewCmd(opts Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "Generate DSL files from templates",
+	}
+	cmd.AddCommand(newNewProviderCmd(opts))
+	return cmd
+}
+
+// newNewProviderCmd returns a non-nil new provider command.
+func newNewProviderCmd(opts Options) *cobra.Command {
+	providerOpts := newProviderOptions{family: "openai", dir: "providers"}
+	cmd := &cobra.Command{
+		Use:   "provider <name>",
+		Short: "Generate providers/<name>.conf from a family template",
+		Long: "Render the template of a provider family, format it and validate it before\n" +
+			"writing <dir>/<name>.conf. Built-in families are openai, anthropic and gemini;\n" +
+			"--template-dir adds directories of <family>.conf.tmpl files that take precedence.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("new provider expects a provider name")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.TrimSpace(args[0])
+			path := filepath.Join(providerOpts.dir, name+".conf")
+			if !providerOpts.stdout && !providerOpts.force {
+				if _, err := os.Stat(path); err == nil {
+					return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+				}
+			}
+			text, err := scaffold.Render(scaffold.Options{
+				Name:         name,
+				Family:       providerOpts.family,
+				BaseURL:      providerOpts.baseURL,
+				TemplateDirs: providerOpts.templateDirs,
+			})
+			if err != nil {
+				return err
+			}
+			if diags := scaffold.Validate(check.FileURI(path), text); len(diags) > 0 {
+				for _, d := range diags {
+					if _, err := fmt.Fprintf(opts.Stderr, "%s:%d:%d: %s\n", path, d.Range.Start.Line+1, d.Range.Start.Character+1, d.Message); err != nil {
+						return err
+					}
+				}
+				return fmt.Errorf("generated provider %q is invalid; fix the %s template", name, providerOpts.family)
+			}
+			if providerOpts.stdout {
+				_, err := io.WriteString(opts.Stdout, text)
+				return err
+			}
+			if err := os.MkdirAll(providerOpts.dir, 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(opts.Stderr, "created %s\n", path)
+			return err
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&providerOpts.family, "family", "openai", "provider family template: openai|anthropic|gemini or a user template name")
+	fs.StringVar(&providerOpts.baseURL, "base-url", "", "upstream base URL (default: the family's public API)")
+	fs.StringVar(&providerOpts.dir, "dir", "providers", "directory to write <name>.conf into")
+	fs.StringArrayVar(&providerOpts.templateDirs, "template-dir", nil, "directory of <family>.conf.tmpl templates searched before the built-ins (repeatable)")
+	fs.BoolVar(&providerOpts.force, "force", false, "overwrite an existing file")
+	fs.BoolVar(&providerOpts.stdout, "stdout", false, "print the provider instead of writing it")
+	return cmd
+}
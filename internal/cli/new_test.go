@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runNew(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	err := Run(append([]string{"new"}, args...), Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &errOut,
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	return out.String(), errOut.String(), err
+}
+
+func TestNewProviderWritesValidFile(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "providers")
+	_, stderr, err := runNew(t, "provider", "claude", "--family", "anthropic", "--dir", dir)
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	path := filepath.Join(dir, "claude.conf")
+	if stderr != "created "+path+"\n" {
+		t.Fatalf("unexpected stderr %q", stderr)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `provider "claude" {`) || !strings.Contains(string(data), "req_map openai_chat_to_anthropic_messages;") {
+		t.Fatalf("unexpected provider:\n%s", data)
+	}
+	if err := Run([]string{"check", path}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	}); err != nil {
+		t.Fatalf("generated provider fails check: %v", err)
+	}
+
+	if _, _, err := runNew(t, "provider", "claude", "--family", "anthropic", "--dir", dir); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected already exists error, got %v", err)
+	}
+	if _, _, err := runNew(t, "provider", "claude", "--family", "gemini", "--dir", dir, "--force"); err != nil {
+		t.Fatalf("new provider --force: %v", err)
+	}
+}
+
+func TestNewProviderStdoutAndTemplateDir(t *testing.T) {
+	t.Parallel()
+
+	out, _, err := runNew(t, "provider", "azure", "--stdout", "--base-url", "https://example.openai.azure.com")
+	if err != nil || !strings.Contains(out, `base_url = "https://example.openai.azure.com";`) {
+		t.Fatalf("new provider --stdout: %v\n%s", err, out)
+	}
+
+	dir := t.TempDir()
+	bad := "syntax \"next-router/0.1\";\nprovider \"{{.Name}}\" {\n  defaults { request { req_map bogus; } }\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "broken.conf.tmpl"), []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, stderr, err := runNew(t, "provider", "acme", "--family", "broken", "--template-dir", dir, "--stdout")
+	if err == nil || !strings.Contains(err.Error(), "fix the broken template") || !strings.Contains(stderr, `unsupported req_map mode "bogus"`) {
+		t.Fatalf("expected validation error, got %v\n%s", err, stderr)
+	}
+}
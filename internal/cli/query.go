@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/query"
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+	"github.com/spf13/cobra"
+)
+
+type queryOptions struct {
+	outputFormat string
+}
+
+// queryResult is one match in JSON output. Line and column are 1-based;
+// range is a zero-based LSP range.
+type queryResult struct {
+	Path    string        `json:"path"`
+	Line    int           `json:"line"`
+	Column  int           `json:"column"`
+	Range   dsllang.Range `json:"range"`
+	Context []string      `json:"context"`
+	Name    string        `json:"name"`
+	Args    []string      `json:"args"`
+}
+
+// newQueryCmd returns a non-nil query command.
+func newQueryCmd(opts Options) *cobra.Command {
+	queryOpts := queryOptions{outputFormat: "text"}
+	cmd := &cobra.Command{
+		Use:   "query <selector> [paths...]",
+		Short: "Find statements matching a structural selector",
+		Long: "Find statements matching a selector such as\n" +
+			"  provider[*].defaults.auth.oauth_content_type[form]\n" +
+			"  provider[openai].match[api=\"chat.*\"].upstream.set_path\n" +
+			"  **.usage_extract[1!=openai]\n" +
+			"in config files or directories (default: current directory).",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("query expects a selector")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if queryOpts.outputFormat != "text" && queryOpts.outputFormat != "json" {
+				return fmt.Errorf("unsupported output format %q (want text, json)", queryOpts.outputFormat)
+			}
+			sel, err := query.Parse(args[0])
+			if err != nil {
+				return err
+			}
+			results, err := runQuery(sel, args[1:])
+			if err != nil {
+				return err
+			}
+			if queryOpts.outputFormat == "json" {
+				enc := json.NewEncoder(opts.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(results)
+			}
+			var b strings.Builder
+			for _, r := range results {
+				fmt.Fprintf(&b, "%s:%d:%d: %s\n", r.Path, r.Line, r.Column, strings.Join(r.Context, " > "))
+			}
+			_, err = io.WriteString(opts.Stdout, b.String())
+			return err
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&queryOpts.outputFormat, "output-format", "text", "output format: text|json")
+	return cmd
+}
+
+func runQuery(sel *query.Selector, paths []string) ([]queryResult, error) {
+	files, err := workspace.CollectFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	results := []queryResult{}
+	for _, path := range files {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read file %q: %w", path, err)
+		}
+		for _, m := range sel.Select(dslast.Parse(string(src))) {
+			r := queryResult{
+				Path:   path,
+				Line:   m.Stmt.Range.Start.Line + 1,
+				Column: m.Stmt.Range.Start.Character + 1,
+				Range:  m.Stmt.Range,
+				Name:   m.Stmt.Name,
+				Args:   m.Stmt.ArgValues(),
+			}
+			for _, p := range m.Parents {
+				r.Context = append(r.Context, query.Label(p))
+			}
+			r.Context = append(r.Context, query.Label(m.Stmt))
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQueryPrintsMatchesWithLocation(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "p.conf")
+	src := "provider \"p\" {\n  defaults {\n    auth {\n      oauth_content_type form;\n    }\n  }\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out bytes.Buffer
+	err := Run([]string{"query", "provider[*].defaults.auth.oauth_content_type[form]", dir}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	want := path + ":4:7: provider \"p\" > defaults > auth > oauth_content_type form\n"
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	err = Run([]string{"query", "--output-format", "json", "**.oauth_content_type", path}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("query json: %v", err)
+	}
+	var results []queryResult
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out.String())
+	}
+	if len(results) != 1 || results[0].Line != 4 || results[0].Args[0] != "form" || len(results[0].Context) != 4 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/rewrite"
+	"github.com/spf13/cobra"
+)
+
+type rewriteOptions struct {
+	rules []string
+	write bool
+}
+
+// newRewriteCmd returns a non-nil rewrite command.
+func newRewriteCmd(opts Options) *cobra.Command {
+	rewriteOpts := rewriteOptions{}
+	cmd := &cobra.Command{
+		Use:   "rewrite -r 'pattern -> replacement' [paths...]",
+		Short: "Apply structural rewrite rules and print a diff or write in place",
+		Long: "Apply structural rewrite rules, e.g.\n" +
+			"  onr-lsp rewrite -r 'req_map openai_chat -> req_map openai_chat_to_openai_responses' providers/\n" +
+			"  onr-lsp rewrite -r 'provider[*].match.request.set_header $k $v... -> set_header $k $v...' providers/\n" +
+			"$x matches one argument and $x... the remaining ones. Rules run in order.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(rewriteOpts.rules) == 0 {
+				return errors.New("rewrite needs at least one -r rule")
+			}
+			rules := make([]*rewrite.Rule, 0, len(rewriteOpts.rules))
+			for _, src := range rewriteOpts.rules {
+				rule, err := rewrite.ParseRule(src)
+				if err != nil {
+					return err
+				}
+				rules = append(rules, rule)
+			}
+			files, err := check.CollectFiles(args)
+			if err != nil {
+				return err
+			}
+			total := 0
+			for _, path := range files {
+				src, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("read file %q: %w", path, err)
+				}
+				text := string(src)
+				for _, rule := range rules {
+					var n int
+					text, n = rule.Apply(text)
+					total += n
+				}
+				if text == string(src) {
+					continue
+				}
+				if rewriteOpts.write {
+					if err := writeFormattedOutput(path, src, text); err != nil {
+						return err
+					}
+					continue
+				}
+				if _, err := io.WriteString(opts.Stdout, rewrite.Unified(path, string(src), text)); err != nil {
+					return err
+				}
+			}
+			if rewriteOpts.write {
+				_, err = fmt.Fprintf(opts.Stderr, "rewrote %d statement(s)\n", total)
+			}
+			return err
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringArrayVarP(&rewriteOpts.rules, "rule", "r", nil, "rewrite rule 'pattern -> replacement' (repeatable)")
+	fs.BoolVarP(&rewriteOpts.write, "write", "w", false, "write results back to files instead of printing a diff")
+	return cmd
+}
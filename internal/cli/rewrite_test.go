@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRewritePrintsDiffOrWrites(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "p.conf")
+	src := "provider \"p\" {\n  defaults {\n    request {\n      req_map openai_chat;\n    }\n  }\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	rule := "req_map openai_chat -> req_map openai_chat_to_openai_responses"
+
+	var out bytes.Buffer
+	err := Run([]string{"rewrite", "-r", rule, dir}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if !strings.Contains(out.String(), "-      req_map openai_chat;\n+      req_map openai_chat_to_openai_responses;\n") {
+		t.Fatalf("unexpected diff:\n%s", out.String())
+	}
+	if data, _ := os.ReadFile(path); string(data) != src {
+		t.Fatalf("diff mode must not write:\n%s", data)
+	}
+
+	var stderr bytes.Buffer
+	err = Run([]string{"rewrite", "-w", "-r", rule, path}, Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &stderr,
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("rewrite -w: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "req_map openai_chat_to_openai_responses;") || stderr.String() != "rewrote 1 statement(s)\n" {
+		t.Fatalf("unexpected write result %q:\n%s", stderr.String(), data)
+	}
+}
@@ -61,6 +61,20 @@ func newRootCmd(opts Options) *cobra.Command {
 	cmd.AddCommand(
 		newServeCmd(opts),
 		newFormatCmd(opts),
+		newCheckCmd(opts),
+		newMergeCmd(opts),
+		newGraphCmd(opts),
+		newDiffCmd(opts),
+		newASTCmd(opts),
+		newRenderCmd(opts),
+		newQueryCmd(opts),
+		newSetCmd(opts),
+		newUnsetCmd(opts),
+		newRewriteCmd(opts),
+		newMigrateCmd(opts),
+		newSpecCmd(opts),
+		newExplainCmd(opts),
+		newNewCmd(opts),
 		newVersionCmd(opts),
 	)
 	return cmd
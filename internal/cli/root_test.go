@@ -21,6 +21,51 @@ func TestRootCmdHasSubcommands(t *testing.T) {
 	if _, _, err := root.Find([]string{"format"}); err != nil {
 		t.Fatalf("find format subcommand: %v", err)
 	}
+	if _, _, err := root.Find([]string{"check"}); err != nil {
+		t.Fatalf("find check subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"merge"}); err != nil {
+		t.Fatalf("find merge subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"graph"}); err != nil {
+		t.Fatalf("find graph subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"diff"}); err != nil {
+		t.Fatalf("find diff subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"ast"}); err != nil {
+		t.Fatalf("find ast subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"render"}); err != nil {
+		t.Fatalf("find render subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"query"}); err != nil {
+		t.Fatalf("find query subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"set"}); err != nil {
+		t.Fatalf("find set subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"unset"}); err != nil {
+		t.Fatalf("find unset subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"rewrite"}); err != nil {
+		t.Fatalf("find rewrite subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"migrate"}); err != nil {
+		t.Fatalf("find migrate subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"spec", "export"}); err != nil {
+		t.Fatalf("find spec export subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"spec", "diff"}); err != nil {
+		t.Fatalf("find spec diff subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"explain"}); err != nil {
+		t.Fatalf("find explain subcommand: %v", err)
+	}
+	if _, _, err := root.Find([]string{"new", "provider"}); err != nil {
+		t.Fatalf("find new provider subcommand: %v", err)
+	}
 	if _, _, err := root.Find([]string{"version"}); err != nil {
 		t.Fatalf("find version subcommand: %v", err)
 	}
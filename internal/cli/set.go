@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/edit"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/onr-lsp/internal/report"
+	"github.com/spf13/cobra"
+)
+
+type setOptions struct {
+	dryRun bool
+}
+
+// newSetCmd returns a non-nil set command.
+func newSetCmd(opts Options) *cobra.Command {
+	setOpts := setOptions{}
+	cmd := &cobra.Command{
+		Use:   "set <file> <path> <value...>",
+		Short: "Set a directive by structural path, keeping comments and formatting",
+		Long: "Set a directive by structural path, e.g.\n" +
+			"  onr-lsp set providers/openai.conf 'provider[openai].defaults.auth.oauth_content_type' form\n" +
+			"Missing blocks on the path are created. The file is left unchanged when the\n" +
+			"edit would introduce new diagnostics.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 3 {
+				return errors.New("set expects a file, a path and at least one value")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEdit(opts, args[0], setOpts.dryRun, func(src string) (string, error) {
+				return edit.Set(src, args[1], args[2:])
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&setOpts.dryRun, "dry-run", false, "print the edited file instead of writing it")
+	return cmd
+}
+
+// newUnsetCmd returns a non-nil unset command.
+func newUnsetCmd(opts Options) *cobra.Command {
+	setOpts := setOptions{}
+	cmd := &cobra.Command{
+		Use:   "unset <file> <path>",
+		Short: "Remove the statements matching a structural path",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("unset expects a file and a path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEdit(opts, args[0], setOpts.dryRun, func(src string) (string, error) {
+				return edit.Unset(src, args[1])
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&setOpts.dryRun, "dry-run", false, "print the edited file instead of writing it")
+	return cmd
+}
+
+// runEdit applies change to a file and writes it back unless the result has
+// diagnostics the original did not.
+func runEdit(opts Options, path string, dryRun bool, change func(string) (string, error)) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file %q: %w", path, err)
+	}
+	edited, err := change(string(src))
+	if err != nil {
+		return err
+	}
+	cfg, _, err := lint.LoadConfig(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	if diags := edit.NewDiagnostics(check.FileURI(path), string(src), edited, cfg); len(diags) > 0 {
+		res := check.Result{Files: []check.FileResult{{Path: path, Diagnostics: diags}}}
+		if err := report.Write(opts.Stderr, "text", res); err != nil {
+			return err
+		}
+		return fmt.Errorf("refusing to write %s: the edit introduces %d new diagnostic(s)", path, len(diags))
+	}
+	if dryRun {
+		_, err = io.WriteString(opts.Stdout, edited)
+		return err
+	}
+	if edited == string(src) {
+		return nil
+	}
+	return writeFormattedOutput(path, src, edited)
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetWritesFileAndUnsetRemoves(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "p.conf")
+	src := "provider \"p\" {\n  defaults {\n    upstream_config {\n      base_url = \"https://example.com\";\n    }\n    request {\n      # keep this\n      req_map openai_chat;\n    }\n  }\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	run := func(args ...string) (string, error) {
+		var stderr bytes.Buffer
+		err := Run(args, Options{
+			Stdin:       strings.NewReader(""),
+			Stdout:      &bytes.Buffer{},
+			Stderr:      &stderr,
+			ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+		})
+		return stderr.String(), err
+	}
+
+	if _, err := run("set", path, "provider[p].defaults.request.req_map", "openai_chat_to_openai_responses"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if want := strings.Replace(src, "req_map openai_chat;", "req_map openai_chat_to_openai_responses;", 1); string(data) != want {
+		t.Fatalf("unexpected file after set:\n%s", data)
+	}
+
+	stderr, err := run("set", path, "provider[p].defaults.request.req_map", "bogus_mode")
+	if err == nil || !strings.Contains(err.Error(), "refusing to write") || !strings.Contains(stderr, "[unsupported-mode]") {
+		t.Fatalf("expected refusal with diagnostics, got %v\n%s", err, stderr)
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(data) {
+		t.Fatalf("refused edit must not change the file:\n%s", after)
+	}
+
+	if _, err := run("unset", path, "provider[p].defaults.request.req_map"); err != nil {
+		t.Fatalf("unset: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "req_map") || !strings.Contains(string(data), "# keep this") {
+		t.Fatalf("unexpected file after unset:\n%s", data)
+	}
+}
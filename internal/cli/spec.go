@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/r9s-ai/onr-lsp/internal/corespec"
+	"github.com/spf13/cobra"
+)
+
+type specExportOptions struct {
+	format string
+	core   string
+}
+
+type specDiffOptions struct {
+	outputFormat string
+	exitCode     bool
+}
+
+// newSpecCmd returns a non-nil spec command.
+func newSpecCmd(opts Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spec",
+		Short: "Export and compare onr-core DSL directive metadata",
+	}
+	cmd.AddCommand(newSpecExportCmd(opts), newSpecDiffCmd(opts))
+	return cmd
+}
+
+// newSpecExportCmd returns a non-nil spec export command.
+func newSpecExportCmd(opts Options) *cobra.Command {
+	exportOpts := specExportOptions{format: "json"}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print dslspec metadata as JSON or as a Markdown reference manual",
+		Long: "Print the directive metadata of the linked onr-core, or of an embedded snapshot\n" +
+			"with --core, as JSON (the model documented in the README) or Markdown.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec := corespec.Current()
+			if exportOpts.core != "" {
+				var err error
+				if spec, err = corespec.Resolve(exportOpts.core); err != nil {
+					return err
+				}
+			}
+			switch exportOpts.format {
+			case "json":
+				return writeSpecJSON(opts.Stdout, spec)
+			case "markdown":
+				_, err := io.WriteString(opts.Stdout, corespec.Markdown(spec))
+				return err
+			default:
+				return fmt.Errorf("unsupported format %q (want json, markdown)", exportOpts.format)
+			}
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&exportOpts.format, "format", "json", "output format: json|markdown")
+	fs.StringVar(&exportOpts.core, "core", "", "export an embedded onr-core release, e.g. v1.14.x (default: the linked onr-core)")
+	return cmd
+}
+
+// newSpecDiffCmd returns a non-nil spec diff command.
+func newSpecDiffCmd(opts Options) *cobra.Command {
+	diffOpts := specDiffOptions{outputFormat: "text"}
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Report directives, modes and enum values added, removed or changed between two specs",
+		Long: "Compare two `spec export --format json` files. Either side may instead name an\n" +
+			"embedded onr-core release such as v1.15.4.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("spec diff expects an old and a new spec")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if diffOpts.outputFormat != "text" && diffOpts.outputFormat != "json" && diffOpts.outputFormat != "markdown" {
+				return fmt.Errorf("unsupported output format %q (want text, json, markdown)", diffOpts.outputFormat)
+			}
+			oldSpec, err := loadSpec(args[0])
+			if err != nil {
+				return err
+			}
+			newSpec, err := loadSpec(args[1])
+			if err != nil {
+				return err
+			}
+			changes := corespec.Diff(oldSpec, newSpec)
+			switch diffOpts.outputFormat {
+			case "json":
+				if changes == nil {
+					changes = []corespec.Change{}
+				}
+				enc := json.NewEncoder(opts.Stdout)
+				enc.SetIndent("", "  ")
+				err = enc.Encode(changes)
+			case "markdown":
+				_, err = io.WriteString(opts.Stdout, corespec.ChangesMarkdown(oldSpec, newSpec, changes))
+			default:
+				for _, c := range changes {
+					if _, err = fmt.Fprintln(opts.Stdout, c.String()); err != nil {
+						break
+					}
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if diffOpts.exitCode && len(changes) > 0 {
+				return errors.New("specs differ")
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&diffOpts.outputFormat, "output-format", "text", "output format: text|json|markdown")
+	fs.BoolVar(&diffOpts.exitCode, "exit-code", false, "exit non-zero when the specs differ")
+	return cmd
+}
+
+func writeSpecJSON(w io.Writer, spec corespec.Spec) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(spec)
+}
+
+// loadSpec reads a JSON export, or resolves arg as an onr-core release when no
+// such file exists.
+func loadSpec(arg string) (corespec.Spec, error) {
+	data, err := os.ReadFile(arg)
+	if errors.Is(err, os.ErrNotExist) {
+		if spec, rerr := corespec.Resolve(arg); rerr == nil {
+			return spec, nil
+		}
+	}
+	if err != nil {
+		return corespec.Spec{}, fmt.Errorf("read spec %q: %w", arg, err)
+	}
+	var spec corespec.Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return corespec.Spec{}, fmt.Errorf("parse spec %q: %w", arg, err)
+	}
+	return spec, nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runSpec(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := Run(append([]string{"spec"}, args...), Options{
+		Stdin:       strings.NewReader(""),
+		Stdout:      &out,
+		Stderr:      &bytes.Buffer{},
+		ServeRunner: func(opts ServeRuntimeOptions) error { return nil },
+	})
+	return out.String(), err
+}
+
+func TestSpecExportAndDiff(t *testing.T) {
+	t.Parallel()
+
+	exported, err := runSpec(t, "export")
+	if err != nil {
+		t.Fatalf("spec export: %v", err)
+	}
+	var spec map[string]any
+	if err := json.Unmarshal([]byte(exported), &spec); err != nil {
+		t.Fatalf("export is not JSON: %v", err)
+	}
+	if spec["syntax"] != "next-router/0.1" || len(spec["directives"].([]any)) == 0 {
+		t.Fatalf("unexpected export: %v", spec["syntax"])
+	}
+
+	md, err := runSpec(t, "export", "--format", "markdown", "--core", "v1.15.x")
+	if err != nil || !strings.HasPrefix(md, "# ONR DSL reference (onr-core v1.15.4)\n") {
+		t.Fatalf("unexpected markdown export (%v):\n%.200s", err, md)
+	}
+
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.json")
+	edited := strings.Replace(exported, `"name": "oauth_content_type"`, `"name": "oauth_content_kind"`, 1)
+	if err := os.WriteFile(oldPath, []byte(edited), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out, err := runSpec(t, "diff", "--exit-code", oldPath, "v1.15.4")
+	if err == nil || err.Error() != "specs differ" {
+		t.Fatalf("expected specs differ, got %v", err)
+	}
+	if out != "- auth.oauth_content_kind\n+ auth.oauth_content_type\n" {
+		t.Fatalf("unexpected diff:\n%s", out)
+	}
+
+	out, err = runSpec(t, "diff", "--output-format", "json", "v1.15.4", "v1.15.4")
+	if err != nil || strings.TrimSpace(out) != "[]" {
+		t.Fatalf("unexpected json diff %q: %v", out, err)
+	}
+}
+
+func TestSpecRejectsBadInput(t *testing.T) {
+	t.Parallel()
+
+	if _, err := runSpec(t, "export", "--format", "yaml"); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Fatalf("expected unsupported format error, got %v", err)
+	}
+	if _, err := runSpec(t, "diff", "missing.json", "v1.15.4"); err == nil || !strings.Contains(err.Error(), "read spec") {
+		t.Fatalf("expected read error, got %v", err)
+	}
+}
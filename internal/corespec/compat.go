@@ -0,0 +1,100 @@
+package corespec
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+// Target returns the release text must stay compatible with: the configured
+// target when set, otherwise the oldest release documenting the file's
+// `syntax` version. ok is false when there is nothing older than the linked
+// onr-core to check against.
+func Target(text, configured string) (Spec, bool, error) {
+	var target Spec
+	if configured != "" {
+		s, err := Resolve(configured)
+		if err != nil {
+			return Spec{}, false, err
+		}
+		target = s
+	} else {
+		syntax := declaredSyntax(dslast.Parse(text))
+		s, ok := ForSyntax(syntax)
+		if syntax == "" || !ok {
+			return Spec{}, false, nil
+		}
+		target = s
+	}
+	if cur := CoreVersion(); cur != "" && target.Core == cur {
+		return Spec{}, false, nil
+	}
+	return target, true, nil
+}
+
+func declaredSyntax(f *dslast.File) string {
+	for _, stmt := range f.Statements {
+		if stmt.Name == "syntax" && !stmt.IsBlock() && len(stmt.Args) > 0 {
+			return stmt.Args[0].Value()
+		}
+	}
+	return ""
+}
+
+// Diagnostics reports directives, modes and enum values of text that the
+// linked onr-core knows but the target release does not. A configured target
+// that cannot be resolved yields nothing; callers report it once with
+// Resolve when the target is set. Severities are left to lint.Filter.
+func Diagnostics(text, configured string) []lint.Diagnostic {
+	target, ok, err := Target(text, configured)
+	if err != nil || !ok {
+		return nil
+	}
+	return Compare(text, Current(), target)
+}
+
+// Compare reports what text uses from cur that target lacks.
+func Compare(text string, cur, target Spec) []lint.Diagnostic {
+	var out []lint.Diagnostic
+	report := func(stmt *dslast.Statement, arg int, msg string) {
+		rng := stmt.NameRange
+		if arg >= 0 {
+			rng = stmt.Args[arg].Range
+		}
+		out = append(out, lint.Diagnostic{
+			Range:   rng,
+			Code:    lint.RuleUnavailableInTarget,
+			Source:  "onr-lsp",
+			Message: fmt.Sprintf("%s is not available in onr-core %s", msg, target.Core),
+		})
+	}
+	dslast.Walk(dslast.Parse(text), func(stmt *dslast.Statement, parents []*dslast.Statement) bool {
+		block := dslast.BlockName(parents)
+		have, ok := cur.Lookup(stmt.Name, block)
+		if !ok {
+			// Unknown directives are reported by the other rules.
+			return true
+		}
+		want, ok := target.Lookup(stmt.Name, block)
+		if !ok {
+			report(stmt, -1, fmt.Sprintf("directive %s in %s block", stmt.Name, block))
+			return false
+		}
+		if len(have.Modes) > 0 && len(stmt.Args) > 0 {
+			mode := stmt.Args[0].Value()
+			if slices.Contains(have.Modes, mode) && !slices.Contains(want.Modes, mode) {
+				report(stmt, 0, fmt.Sprintf("%s mode %q", stmt.Name, mode))
+			}
+		}
+		for i, arg := range stmt.Args {
+			v := arg.Value()
+			if slices.Contains(have.Enum(i), v) && !slices.Contains(want.Enum(i), v) {
+				report(stmt, i, fmt.Sprintf("%s value %q", stmt.Name, v))
+			}
+		}
+		return true
+	})
+	return out
+}
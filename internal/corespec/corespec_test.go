@@ -0,0 +1,183 @@
+package corespec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+func TestSnapshotOfLinkedCoreIsCurrent(t *testing.T) {
+	t.Parallel()
+
+	cur := Current()
+	if cur.Core == "" {
+		t.Skip("onr-core is replaced by a local checkout")
+	}
+	snaps, err := Snapshots()
+	if err != nil {
+		t.Fatalf("Snapshots: %v", err)
+	}
+	i := slices.IndexFunc(snaps, func(s Spec) bool { return s.Core == cur.Core })
+	if i < 0 {
+		t.Fatalf("no snapshot for linked onr-core %s; run make spec-snapshot CORE=%s", cur.Core, cur.Core)
+	}
+	data, _ := json.Marshal(cur)
+	var want Spec
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("round-trip: %v", err)
+	}
+	if !reflect.DeepEqual(snaps[i], want) {
+		t.Fatalf("snapshot %s is stale; run make spec-snapshot CORE=%s", cur.Core, cur.Core)
+	}
+	if snaps[i].Syntax != "next-router/0.1" {
+		t.Fatalf("unexpected syntax version %q", snaps[i].Syntax)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	for _, target := range []string{"v1.15.x", "1.15", "v1.15.4", "v1"} {
+		s, err := Resolve(target)
+		if err != nil || s.Core != "v1.15.4" {
+			t.Fatalf("Resolve(%q) = %q, %v", target, s.Core, err)
+		}
+	}
+	if _, err := Resolve("v1.0.x"); !errors.Is(err, ErrNoSnapshot) || !strings.Contains(err.Error(), "no dslspec snapshot for onr-core v1.0.x (known: ") {
+		t.Fatalf("expected missing snapshot error, got %v", err)
+	}
+	if _, err := Resolve("latest"); err == nil || !strings.Contains(err.Error(), "invalid onr-core version") {
+		t.Fatalf("expected invalid version error, got %v", err)
+	}
+}
+
+func TestTargetFromSyntaxSkipsLinkedCore(t *testing.T) {
+	t.Parallel()
+
+	if _, ok, err := Target(`syntax "next-router/0.1";`, ""); ok || err != nil {
+		t.Fatalf("syntax of the linked core needs no check, got ok=%v err=%v", ok, err)
+	}
+	if _, ok, err := Target("provider \"p\" {}\n", ""); ok || err != nil {
+		t.Fatalf("files without syntax need no check, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestCompareReportsMissingDirectivesModesAndEnums(t *testing.T) {
+	t.Parallel()
+
+	cur := Current()
+	old := Spec{Core: "v1.14.0", Blocks: cur.Blocks}
+	for _, d := range cur.Directives {
+		switch {
+		case d.Name == "oauth_content_type":
+			continue
+		case d.Name == "req_map" && d.Block == "request":
+			d.Modes = slices.DeleteFunc(slices.Clone(d.Modes), func(m string) bool { return m == "openai_chat_to_openai_responses" })
+		case d.Name == "balance_unit":
+			d.Args = []Arg{{Name: "unit", Kind: "enum", Enum: []string{"USD"}}}
+		}
+		old.Directives = append(old.Directives, d)
+	}
+	text := "provider \"p\" {\n" +
+		"  defaults {\n" +
+		"    auth {\n" +
+		"      oauth_content_type form;\n" +
+		"    }\n" +
+		"    request {\n" +
+		"      req_map openai_chat_to_openai_responses;\n" +
+		"    }\n" +
+		"    balance {\n" +
+		"      balance_unit CNY;\n" +
+		"      balance_unit USD;\n" +
+		"    }\n" +
+		"  }\n" +
+		"}\n"
+	diags := Compare(text, cur, old)
+	var got []string
+	for _, d := range diags {
+		if d.Code != lint.RuleUnavailableInTarget {
+			t.Fatalf("unexpected code %q", d.Code)
+		}
+		got = append(got, d.Message)
+	}
+	want := []string{
+		"directive oauth_content_type in auth block is not available in onr-core v1.14.0",
+		`req_map mode "openai_chat_to_openai_responses" is not available in onr-core v1.14.0`,
+		`balance_unit value "CNY" is not available in onr-core v1.14.0`,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected diagnostics:\n%s", strings.Join(got, "\n"))
+	}
+	if r := diags[1].Range; r.Start.Line != 6 || r.Start.Character != 14 {
+		t.Fatalf("mode diagnostic must cover the argument: %+v", r)
+	}
+}
+
+func TestDiagnosticsIgnoreUnresolvedTarget(t *testing.T) {
+	t.Parallel()
+
+	text := "syntax \"next-router/0.1\";\nprovider \"p\" {\n  defaults {\n    auth {\n      oauth_content_type form;\n    }\n  }\n}\n"
+	for _, target := range []string{"v1.0.x", "latest"} {
+		if diags := Diagnostics(text, target); len(diags) != 0 {
+			t.Fatalf("%s: an unresolved target is reported by the caller, got %+v", target, diags)
+		}
+	}
+}
+
+// TestOlderSnapshotsFlagNewerDirectives checks every embedded release older
+// than the linked onr-core, plus testdata/older-core.json, against a request
+// directive it lacks. The fixture is the linked snapshot without the
+// json_del_* directives, so the check always runs.
+func TestOlderSnapshotsFlagNewerDirectives(t *testing.T) {
+	t.Parallel()
+
+	cur := Current()
+	data, err := os.ReadFile(filepath.Join("testdata", "older-core.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var fixture Spec
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+	olds := []Spec{fixture}
+	snaps, err := Snapshots()
+	if err != nil {
+		t.Fatalf("Snapshots: %v", err)
+	}
+	if curVersion, ok := parseVersion(cur.Core); ok {
+		for _, s := range snaps {
+			if v, _ := parseVersion(s.Core); v.less(curVersion) {
+				olds = append(olds, s)
+			}
+		}
+	}
+	for _, old := range olds {
+		i := slices.IndexFunc(cur.Directives, func(d Directive) bool {
+			_, ok := old.Lookup(d.Name, d.Block)
+			return !ok && d.Block == "request" && !d.IsBlock
+		})
+		if i < 0 {
+			t.Errorf("%s: expected a request directive missing from the snapshot", old.Core)
+			continue
+		}
+		d := cur.Directives[i]
+		text := "provider \"p\" {\n  defaults {\n    request {\n      " + d.Name + " x;\n    }\n  }\n}\n"
+		diags := Compare(text, cur, old)
+		if old.Core != fixture.Core {
+			diags = Diagnostics(text, old.Core)
+		}
+		want := fmt.Sprintf("directive %s in request block is not available in onr-core %s", d.Name, old.Core)
+		if !slices.ContainsFunc(diags, func(d lint.Diagnostic) bool { return d.Message == want }) {
+			t.Errorf("%s: expected %q, got %+v", old.Core, want, diags)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package corespec
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Change kinds.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is one difference between two specs. Block and Name identify the
+// directive; Block alone is used for block directive names.
+type Change struct {
+	Kind   string `json:"kind"`
+	Block  string `json:"block"`
+	Name   string `json:"name,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// String renders the change as `+ request.set_header`,
+// `- request.header_set` or `~ request.req_map: mode "x" added`.
+func (c Change) String() string {
+	subject := c.Block
+	if c.Name != "" {
+		subject += "." + c.Name
+	} else {
+		subject = "block " + subject
+	}
+	switch c.Kind {
+	case Added:
+		return "+ " + subject
+	case Removed:
+		return "- " + subject
+	default:
+		return "~ " + subject + ": " + c.Detail
+	}
+}
+
+// Diff reports what changed from old to new, ordered by block and name.
+func Diff(old, new Spec) []Change {
+	var out []Change
+	for _, b := range new.Blocks {
+		if !slices.Contains(old.Blocks, b) {
+			out = append(out, Change{Kind: Added, Block: b})
+		}
+	}
+	for _, b := range old.Blocks {
+		if !slices.Contains(new.Blocks, b) {
+			out = append(out, Change{Kind: Removed, Block: b})
+		}
+	}
+	for _, d := range old.Directives {
+		if _, ok := new.Lookup(d.Name, d.Block); !ok {
+			out = append(out, Change{Kind: Removed, Block: d.Block, Name: d.Name})
+		}
+	}
+	for _, d := range new.Directives {
+		prev, ok := old.Lookup(d.Name, d.Block)
+		if !ok {
+			out = append(out, Change{Kind: Added, Block: d.Block, Name: d.Name})
+			continue
+		}
+		for _, detail := range directiveChanges(prev, d) {
+			out = append(out, Change{Kind: Changed, Block: d.Block, Name: d.Name, Detail: detail})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Block != out[j].Block {
+			return out[i].Block < out[j].Block
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func directiveChanges(old, new Directive) []string {
+	var out []string
+	if old.IsBlock != new.IsBlock {
+		out = append(out, fmt.Sprintf("isBlock %v → %v", old.IsBlock, new.IsBlock))
+	}
+	if old.BlockHeader != new.BlockHeader {
+		out = append(out, fmt.Sprintf("blockHeader %v → %v", old.BlockHeader, new.BlockHeader))
+	}
+	out = append(out, valueChanges("mode", old.Modes, new.Modes)...)
+	if old.ModeRegistryBlock != new.ModeRegistryBlock {
+		out = append(out, fmt.Sprintf("modeRegistryBlock %q → %q", old.ModeRegistryBlock, new.ModeRegistryBlock))
+	}
+	for i := 0; i < max(len(old.Args), len(new.Args)); i++ {
+		switch {
+		case i >= len(old.Args):
+			out = append(out, fmt.Sprintf("argument %d (%s) added", i+1, new.Args[i].Name))
+		case i >= len(new.Args):
+			out = append(out, fmt.Sprintf("argument %d (%s) removed", i+1, old.Args[i].Name))
+		default:
+			a, b := old.Args[i], new.Args[i]
+			if a.Name != b.Name || a.Kind != b.Kind {
+				out = append(out, fmt.Sprintf("argument %d %s %s → %s %s", i+1, a.Name, a.Kind, b.Name, b.Kind))
+			}
+			out = append(out, valueChanges(fmt.Sprintf("argument %d value", i+1), a.Enum, b.Enum)...)
+		}
+	}
+	if old.Hover != new.Hover {
+		out = append(out, "documentation changed")
+	}
+	return out
+}
+
+// valueChanges lists values added to and removed from a list.
+func valueChanges(what string, old, new []string) []string {
+	var added, removed []string
+	for _, v := range new {
+		if !slices.Contains(old, v) {
+			added = append(added, fmt.Sprintf("%q", v))
+		}
+	}
+	for _, v := range old {
+		if !slices.Contains(new, v) {
+			removed = append(removed, fmt.Sprintf("%q", v))
+		}
+	}
+	var out []string
+	if len(added) > 0 {
+		out = append(out, fmt.Sprintf("%s %s added", what, strings.Join(added, ", ")))
+	}
+	if len(removed) > 0 {
+		out = append(out, fmt.Sprintf("%s %s removed", what, strings.Join(removed, ", ")))
+	}
+	return out
+}
+
+// ChangesMarkdown renders changes as release-note sections.
+func ChangesMarkdown(old, new Spec, changes []Change) string {
+	var b strings.Builder
+	title := "## DSL changes"
+	if old.Core != "" && new.Core != "" {
+		title += fmt.Sprintf(" (onr-core %s → %s)", old.Core, new.Core)
+	}
+	b.WriteString(title + "\n")
+	if len(changes) == 0 {
+		b.WriteString("\nNo changes.\n")
+		return b.String()
+	}
+	for _, section := range []struct{ kind, title string }{{Added, "Added"}, {Removed, "Removed"}, {Changed, "Changed"}} {
+		var lines []string
+		for _, c := range changes {
+			if c.Kind != section.kind {
+				continue
+			}
+			line := "- block `" + c.Block + "`"
+			if c.Name != "" {
+				line = "- `" + c.Block + "." + c.Name + "`"
+			}
+			if c.Detail != "" {
+				line += ": " + c.Detail
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&b, "\n### %s\n\n%s\n", section.title, strings.Join(lines, "\n"))
+		}
+	}
+	return b.String()
+}
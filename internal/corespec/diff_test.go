@@ -0,0 +1,85 @@
+package corespec
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	old := Spec{
+		Core:   "v1.14.0",
+		Blocks: []string{"request", "legacy"},
+		Directives: []Directive{
+			{Name: "req_map", Block: "request", Modes: []string{"a", "b"}, Hover: "old"},
+			{Name: "header_set", Block: "request"},
+			{Name: "balance_unit", Block: "balance", Args: []Arg{{Name: "unit", Kind: "enum", Enum: []string{"USD"}}}},
+		},
+	}
+	new := Spec{
+		Core:   "v1.15.0",
+		Blocks: []string{"request", "auth"},
+		Directives: []Directive{
+			{Name: "req_map", Block: "request", Modes: []string{"a", "c"}, Hover: "new"},
+			{Name: "set_header", Block: "request"},
+			{Name: "balance_unit", Block: "balance", Args: []Arg{{Name: "unit", Kind: "enum", Enum: []string{"USD", "CNY"}}}},
+		},
+	}
+	var got []string
+	for _, c := range Diff(old, new) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"+ block auth",
+		`~ balance.balance_unit: argument 1 value "CNY" added`,
+		"- block legacy",
+		"- request.header_set",
+		`~ request.req_map: mode "c" added`,
+		`~ request.req_map: mode "b" removed`,
+		"~ request.req_map: documentation changed",
+		"+ request.set_header",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected diff:\n%s", strings.Join(got, "\n"))
+	}
+
+	md := ChangesMarkdown(old, new, Diff(old, new))
+	for _, s := range []string{"## DSL changes (onr-core v1.14.0 → v1.15.0)\n", "### Added\n\n- block `auth`\n- `request.set_header`\n", "### Removed\n"} {
+		if !strings.Contains(md, s) {
+			t.Fatalf("missing %q in:\n%s", s, md)
+		}
+	}
+	if len(Diff(new, new)) != 0 {
+		t.Fatalf("identical specs must not differ")
+	}
+}
+
+func TestMarkdownListsBlocksAndFacts(t *testing.T) {
+	t.Parallel()
+
+	md := Markdown(Current())
+	for _, s := range []string{
+		"# ONR DSL reference",
+		"- [`top`](#top)",
+		"\n## `balance`\n",
+		"### `balance_unit`\n\n`balance_unit <unit>;`",
+		"- Argument 1 (`unit`): one of `USD`, `CNY`.",
+		"Also accepts presets declared in top-level `usage_mode` blocks.",
+	} {
+		if !strings.Contains(md, s) {
+			t.Fatalf("missing %q in reference manual", s)
+		}
+	}
+}
+
+func TestPlainTextKeepsFencedBlocks(t *testing.T) {
+	t.Parallel()
+
+	md := "## `json_set` in `request`\n\nSets a field; a lone ` stays.\n\n```conf\n# set the model\njson_set \"$.model\" \"`gpt`\";\n\n```\n- Example: `json_set \"$.a\" 1;`\n"
+	want := "json_set in request\n\nSets a field; a lone ` stays.\n\n    # set the model\n    json_set \"$.model\" \"`gpt`\";\n\n- Example: json_set \"$.a\" 1;\n"
+	if got := PlainText(md); got != want {
+		t.Fatalf("unexpected plain text:\n%s", got)
+	}
+}
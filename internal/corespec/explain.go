@@ -0,0 +1,164 @@
+package corespec
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Topic is what a spec documents about one word: the directives of that name
+// and the directives that accept it as a built-in mode.
+type Topic struct {
+	Word       string
+	Directives []Directive
+	ModeOf     []Directive
+}
+
+// Explain looks word up as a directive and as a mode value. A non-empty
+// block restricts both to directives in that block.
+func (s Spec) Explain(word, block string) (Topic, error) {
+	t := Topic{Word: word}
+	for _, d := range s.Directives {
+		if block != "" && d.Block != block {
+			continue
+		}
+		if d.Name == word {
+			t.Directives = append(t.Directives, d)
+		}
+		if slices.Contains(d.Modes, word) {
+			t.ModeOf = append(t.ModeOf, d)
+		}
+	}
+	if len(t.Directives) > 0 || len(t.ModeOf) > 0 {
+		return t, nil
+	}
+	if allowed := s.AllowedBlocks(word); block != "" && len(allowed) > 0 {
+		return t, fmt.Errorf("%s is not allowed in %s (allowed in: %s)", word, block, strings.Join(allowed, ", "))
+	}
+	return t, fmt.Errorf("unknown directive or mode %q (see onr-lsp explain --list)", word)
+}
+
+// AllowedBlocks returns the blocks a directive may appear in, in spec order.
+func (s Spec) AllowedBlocks(name string) []string {
+	var out []string
+	for _, d := range s.Directives {
+		if d.Name == name && !slices.Contains(out, d.Block) {
+			out = append(out, d.Block)
+		}
+	}
+	return out
+}
+
+// Markdown renders the topic like an editor hover, followed by where the
+// directive is allowed, its modes, argument values and an example.
+func (t Topic) Markdown(s Spec) string {
+	var sections []string
+	for _, d := range t.Directives {
+		var parts []string
+		parts = append(parts, fmt.Sprintf("## `%s` in `%s`", d.Name, d.Block))
+		if hover := strings.TrimSpace(d.Hover); hover != "" {
+			parts = append(parts, hover)
+		}
+		facts := append([]string{"Allowed in: " + codeList(s.AllowedBlocks(d.Name)) + "."}, d.facts()...)
+		if ex := d.example(); ex != "" {
+			facts = append(facts, "Example: `"+ex+"`")
+		}
+		parts = append(parts, "- "+strings.Join(facts, "\n- "))
+		sections = append(sections, strings.Join(parts, "\n\n"))
+	}
+	if len(t.ModeOf) > 0 {
+		lines := make([]string, 0, len(t.ModeOf))
+		for _, d := range t.ModeOf {
+			lines = append(lines, fmt.Sprintf("- `%s` in `%s`: `%s %s;`", d.Name, d.Block, d.Name, t.Word))
+		}
+		sections = append(sections, fmt.Sprintf("## Mode `%s`\n\nBuilt-in mode accepted by:\n\n%s", t.Word, strings.Join(lines, "\n")))
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+// example returns a statement using the first mode or enum values, or ""
+// when the hover usage line is all there is.
+func (d Directive) example() string {
+	switch {
+	case d.IsBlock:
+		return ""
+	case len(d.Modes) > 0:
+		return d.Name + " " + d.Modes[0] + ";"
+	}
+	var args []string
+	for i := range d.Args {
+		enum := d.Enum(i)
+		if len(enum) == 0 {
+			return ""
+		}
+		args = append(args, enum[0])
+	}
+	if len(args) == 0 {
+		return ""
+	}
+	return d.Name + " " + strings.Join(args, " ") + ";"
+}
+
+// List renders every directive grouped by block with the first sentence of
+// its description.
+func (s Spec) List() string {
+	var b strings.Builder
+	blocks, byBlock := s.groupByBlock()
+	for i, block := range blocks {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## `%s`\n\n", block)
+		for _, d := range byBlock[block] {
+			line := "- `" + d.Name + "`"
+			if sum := d.summary(); sum != "" {
+				line += ": " + sum
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// summary returns the first sentence after the usage line of the hover.
+func (d Directive) summary() string {
+	paras := strings.Split(strings.TrimSpace(d.Hover), "\n\n")
+	if len(paras) < 2 {
+		return ""
+	}
+	text := strings.Join(strings.Fields(paras[1]), " ")
+	if i := strings.Index(text, ". "); i >= 0 {
+		text = text[:i+1]
+	}
+	return text
+}
+
+var (
+	markdownHeading  = regexp.MustCompile(`^#{1,6} `)
+	markdownCodeSpan = regexp.MustCompile("`([^`\n]+)`")
+)
+
+// PlainText strips heading markers and code span delimiters. Fenced code
+// blocks lose their fences and are indented by four spaces, with their
+// content kept as is.
+func PlainText(md string) string {
+	var b strings.Builder
+	fenced := false
+	for _, line := range strings.SplitAfter(md, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			if strings.TrimSpace(line) != "" {
+				b.WriteString("    ")
+			}
+			b.WriteString(line)
+			continue
+		}
+		line = markdownHeading.ReplaceAllString(line, "")
+		b.WriteString(markdownCodeSpan.ReplaceAllString(line, "$1"))
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package corespec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown renders s as a reference manual: one section per block, listing
+// each directive with its documentation, modes and argument values.
+func Markdown(s Spec) string {
+	var b strings.Builder
+	title := "# ONR DSL reference"
+	if s.Core != "" {
+		title += " (onr-core " + s.Core + ")"
+	}
+	b.WriteString(title + "\n\n")
+	if s.Syntax != "" {
+		fmt.Fprintf(&b, "Syntax version: `%s`\n\n", s.Syntax)
+	}
+
+	blocks, byBlock := s.groupByBlock()
+	b.WriteString("## Blocks\n\n`top` holds file-level statements.\n\n")
+	for _, block := range blocks {
+		fmt.Fprintf(&b, "- [`%s`](#%s) (%d directive(s))\n", block, anchor(block), len(byBlock[block]))
+	}
+	for _, block := range blocks {
+		fmt.Fprintf(&b, "\n## `%s`\n", block)
+		for _, d := range byBlock[block] {
+			var parts []string
+			if hover := strings.TrimSpace(d.Hover); hover != "" {
+				parts = append(parts, hover)
+			}
+			if facts := d.facts(); len(facts) > 0 {
+				parts = append(parts, "- "+strings.Join(facts, "\n- "))
+			}
+			fmt.Fprintf(&b, "\n### `%s`\n\n%s\n", d.Name, strings.Join(parts, "\n\n"))
+		}
+	}
+	return b.String()
+}
+
+// groupByBlock returns block names, "top" first and the rest in order of
+// appearance, with their directives.
+func (s Spec) groupByBlock() ([]string, map[string][]Directive) {
+	blocks := []string{}
+	byBlock := map[string][]Directive{}
+	for _, d := range s.Directives {
+		if _, ok := byBlock[d.Block]; !ok {
+			if d.Block == "top" {
+				blocks = append([]string{d.Block}, blocks...)
+			} else {
+				blocks = append(blocks, d.Block)
+			}
+		}
+		byBlock[d.Block] = append(byBlock[d.Block], d)
+	}
+	return blocks, byBlock
+}
+
+func (d Directive) facts() []string {
+	var out []string
+	switch {
+	case d.IsBlock && d.BlockHeader:
+		out = append(out, "Block with arguments before `{`.")
+	case d.IsBlock:
+		out = append(out, "Block.")
+	}
+	if len(d.Modes) > 0 {
+		out = append(out, "Built-in modes: "+codeList(d.Modes)+".")
+	}
+	if d.ModeRegistryBlock != "" {
+		out = append(out, fmt.Sprintf("Also accepts presets declared in top-level `%s` blocks.", d.ModeRegistryBlock))
+	}
+	for i, a := range d.Args {
+		if len(a.Enum) > 0 {
+			out = append(out, fmt.Sprintf("Argument %d (`%s`): one of %s.", i+1, a.Name, codeList(a.Enum)))
+		}
+	}
+	return out
+}
+
+func anchor(block string) string {
+	return strings.ToLower(strings.ReplaceAll(block, " ", "-"))
+}
+
+func codeList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, "`"+v+"`")
+	}
+	return strings.Join(quoted, ", ")
+}
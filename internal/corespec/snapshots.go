@@ -0,0 +1,114 @@
+package corespec
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Snapshots are generated with `make spec-snapshot` (cmd/onr-specsnap) while
+// go.mod points at the onr-core release to capture.
+//
+//go:embed snapshots/*.json
+var snapshotFS embed.FS
+
+// ErrNoSnapshot is returned by Resolve when no embedded snapshot matches the
+// target release.
+var ErrNoSnapshot = errors.New("no dslspec snapshot")
+
+// Snapshots returns the embedded specs, oldest release first.
+func Snapshots() ([]Spec, error) {
+	entries, err := snapshotFS.ReadDir("snapshots")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Spec, 0, len(entries))
+	for _, e := range entries {
+		data, err := snapshotFS.ReadFile(path.Join("snapshots", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var s Spec
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", e.Name(), err)
+		}
+		if _, ok := parseVersion(s.Core); !ok {
+			return nil, fmt.Errorf("snapshot %s: invalid core version %q", e.Name(), s.Core)
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, _ := parseVersion(out[i].Core)
+		b, _ := parseVersion(out[j].Core)
+		return a.less(b)
+	})
+	return out, nil
+}
+
+// candidates returns the embedded snapshots plus the linked onr-core when it
+// is a release without a snapshot, oldest first.
+func candidates() ([]Spec, error) {
+	snaps, err := Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	cur := Current()
+	v, ok := parseVersion(cur.Core)
+	if !ok {
+		return snaps, nil
+	}
+	for i, s := range snaps {
+		sv, _ := parseVersion(s.Core)
+		if sv == v {
+			snaps[i] = cur
+			return snaps, nil
+		}
+		if v.less(sv) {
+			return append(snaps[:i], append([]Spec{cur}, snaps[i:]...)...), nil
+		}
+	}
+	return append(snaps, cur), nil
+}
+
+// Resolve returns the newest known release matching target, such as
+// "v1.14.x" or "v1.15.4".
+func Resolve(target string) (Spec, error) {
+	pattern, err := parseVersionPattern(target)
+	if err != nil {
+		return Spec{}, err
+	}
+	specs, err := candidates()
+	if err != nil {
+		return Spec{}, err
+	}
+	for i := len(specs) - 1; i >= 0; i-- {
+		v, _ := parseVersion(specs[i].Core)
+		if pattern.matches(v) {
+			return specs[i], nil
+		}
+	}
+	known := make([]string, 0, len(specs))
+	for _, s := range specs {
+		known = append(known, s.Core)
+	}
+	return Spec{}, fmt.Errorf("%w for onr-core %s (known: %s)", ErrNoSnapshot, target, strings.Join(known, ", "))
+}
+
+// ForSyntax returns the oldest known release that documents the syntax
+// version, i.e. the oldest router a file declaring it may run on.
+func ForSyntax(syntax string) (Spec, bool) {
+	specs, err := candidates()
+	if err != nil {
+		return Spec{}, false
+	}
+	for _, s := range specs {
+		if s.Syntax == syntax {
+			return s, true
+		}
+	}
+	return Spec{}, false
+}
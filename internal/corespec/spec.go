@@ -0,0 +1,180 @@
+// Package corespec captures onr-core dslspec metadata as plain data, so the
+// directives of one onr-core release can be embedded, exported and compared
+// with another's.
+package corespec
+
+import (
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dslspec"
+)
+
+// CoreModule is the module path of onr-core.
+const CoreModule = "github.com/r9s-ai/open-next-router/onr-core"
+
+// Spec is the directive metadata of one onr-core release.
+type Spec struct {
+	// Core is the onr-core version, e.g. "v1.15.4"; empty when unknown.
+	Core string `json:"core,omitempty"`
+	// Syntax is the DSL syntax version the release documents for `syntax`.
+	Syntax string `json:"syntax,omitempty"`
+	// Blocks lists the directive names that open a block.
+	Blocks     []string    `json:"blocks"`
+	Directives []Directive `json:"directives"`
+}
+
+// Directive is one directive in one parent block.
+type Directive struct {
+	Name  string `json:"name"`
+	Block string `json:"block"`
+	// IsBlock is set for directives written as `name { ... }`.
+	IsBlock bool `json:"isBlock,omitempty"`
+	// BlockHeader is set for blocks that take arguments before `{`.
+	BlockHeader bool `json:"blockHeader,omitempty"`
+	// Modes are the built-in mode values of a mode directive.
+	Modes []string `json:"modes,omitempty"`
+	// ModeRegistryBlock names the top-level block that declares user presets
+	// for the mode directive.
+	ModeRegistryBlock string `json:"modeRegistryBlock,omitempty"`
+	Args              []Arg  `json:"args,omitempty"`
+	Hover             string `json:"hover,omitempty"`
+}
+
+// Arg is one positional argument.
+type Arg struct {
+	Name string   `json:"name"`
+	Kind string   `json:"kind"`
+	Enum []string `json:"enum,omitempty"`
+}
+
+// Current returns the metadata of the linked onr-core.
+func Current() Spec {
+	return FromMetadata(CoreVersion(), dslspec.DirectiveMetadataList(), dslspec.BlockDirectiveNames())
+}
+
+// CoreVersion returns the linked onr-core release, or "" for development
+// builds that replace it with a local checkout.
+func CoreVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path != CoreModule {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		if _, ok := parseVersion(dep.Version); ok {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+var syntaxVersionPattern = regexp.MustCompile(`"([a-z-]+/[0-9][0-9.]*)"`)
+
+// FromMetadata converts dslspec metadata into a Spec.
+func FromMetadata(core string, meta []dslspec.DirectiveMetadata, blocks []string) Spec {
+	s := Spec{Core: core, Blocks: append([]string{}, blocks...), Directives: make([]Directive, 0, len(meta))}
+	for _, m := range meta {
+		d := Directive{
+			Name:              m.Name,
+			Block:             m.Block,
+			IsBlock:           m.IsBlock,
+			BlockHeader:       m.BlockHeader,
+			Modes:             m.Modes,
+			ModeRegistryBlock: m.ModeRegistryBlock,
+			Hover:             m.Hover,
+		}
+		for _, a := range m.Args {
+			d.Args = append(d.Args, Arg{Name: a.Name, Kind: a.Kind, Enum: a.Enum})
+		}
+		if m.Name == "syntax" && m.Block == "top" {
+			if sm := syntaxVersionPattern.FindStringSubmatch(m.Hover); sm != nil {
+				s.Syntax = sm[1]
+			}
+		}
+		s.Directives = append(s.Directives, d)
+	}
+	return s
+}
+
+// Lookup returns the directive name in block.
+func (s Spec) Lookup(name, block string) (Directive, bool) {
+	for _, d := range s.Directives {
+		if d.Name == name && d.Block == block {
+			return d, true
+		}
+	}
+	return Directive{}, false
+}
+
+// Enum returns the enum values of argument i, or nil.
+func (d Directive) Enum(i int) []string {
+	if i < 0 || i >= len(d.Args) || d.Args[i].Kind != "enum" {
+		return nil
+	}
+	return d.Args[i].Enum
+}
+
+// version is a parsed vMAJOR.MINOR.PATCH.
+type version [3]int
+
+func parseVersion(s string) (version, bool) {
+	var v version
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+func (v version) less(o version) bool {
+	return slices.Compare(v[:], o[:]) < 0
+}
+
+// versionPattern matches "v1.14.x", "v1.14", "1.14.2" and the like; -1
+// components are wildcards.
+type versionPattern [3]int
+
+func parseVersionPattern(s string) (versionPattern, error) {
+	p := versionPattern{-1, -1, -1}
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "v"), ".")
+	if len(parts) == 0 || len(parts) > 3 || parts[0] == "" {
+		return p, fmt.Errorf("invalid onr-core version %q (want e.g. v1.14.x or v1.14.2)", s)
+	}
+	for i, part := range parts {
+		if part == "x" || part == "*" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid onr-core version %q (want e.g. v1.14.x or v1.14.2)", s)
+		}
+		p[i] = n
+	}
+	return p, nil
+}
+
+func (p versionPattern) matches(v version) bool {
+	for i := range p {
+		if p[i] >= 0 && p[i] != v[i] {
+			return false
+		}
+	}
+	return true
+}
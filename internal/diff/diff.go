@@ -0,0 +1,186 @@
+// Package diff compares ONR DSL configurations by parsed structure, so that
+// formatting, comment and ordering changes do not show up as differences.
+package diff
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+)
+
+// Kind classifies a change.
+type Kind string
+
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Change is one structural difference. Path holds the labels of the enclosing
+// blocks followed by the changed statement, e.g.
+// [`provider "openai"`, "defaults", "request", "req_map"].
+type Change struct {
+	Kind Kind     `json:"kind"`
+	Path []string `json:"path"`
+	Old  string   `json:"old,omitempty"`
+	New  string   `json:"new,omitempty"`
+}
+
+// String renders the change as `~ a > b > name: old → new`, `+ a > b` or
+// `- a > b: old`.
+func (c Change) String() string {
+	path := strings.Join(c.Path, " > ")
+	switch c.Kind {
+	case Added:
+		return "+ " + withValue(path, c.New)
+	case Removed:
+		return "- " + withValue(path, c.Old)
+	default:
+		return "~ " + path + ": " + c.Old + " → " + c.New
+	}
+}
+
+func withValue(path, value string) string {
+	if value == "" {
+		return path
+	}
+	return path + ": " + value
+}
+
+// Text compares two DSL documents.
+func Text(oldText, newText string) []Change {
+	return Files(dslast.Parse(oldText), dslast.Parse(newText))
+}
+
+// Files compares two parsed DSL documents.
+func Files(oldFile, newFile *dslast.File) []Change {
+	var out []Change
+	compare(nil, statementsOf(oldFile), statementsOf(newFile), &out)
+	return out
+}
+
+func statementsOf(f *dslast.File) []*dslast.Statement {
+	if f == nil {
+		return nil
+	}
+	return f.Statements
+}
+
+// entry is a statement keyed for matching against the other side.
+type entry struct {
+	key   string
+	label string
+	value string
+	// args are the arguments that make up value.
+	args []*dslast.Arg
+	stmt *dslast.Statement
+}
+
+// entries keys the statements of one block. Blocks are identified by name and
+// arguments; directives by name plus the arguments that identify them (see
+// workspace.DirectiveKeyArgs). Repeated keys are numbered in source order.
+func entries(stmts []*dslast.Statement) []entry {
+	seen := map[string]int{}
+	out := make([]entry, 0, len(stmts))
+	for _, stmt := range stmts {
+		if stmt.Name == "" {
+			continue
+		}
+		e := keyed(stmt)
+		seen[e.key]++
+		if n := seen[e.key]; n > 1 {
+			e.key += " #" + strconv.Itoa(n)
+			e.label += " #" + strconv.Itoa(n)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func keyed(stmt *dslast.Statement) entry {
+	n := len(stmt.Args)
+	if !stmt.IsBlock() {
+		n = workspace.DirectiveKeyArgs(stmt.Name)
+		if n < 0 || n > len(stmt.Args) {
+			n = len(stmt.Args)
+		}
+	}
+	keyArgs, valueArgs := stmt.Args[:n], stmt.Args[n:]
+	e := entry{key: stmt.Name, label: stmt.Name, stmt: stmt}
+	if stmt.IsBlock() {
+		e.key = "{" + e.key
+	}
+	for _, a := range keyArgs {
+		value := a.Value()
+		if n == 1 && !stmt.IsBlock() {
+			value = workspace.DirectiveKey(stmt.Name, value)
+		}
+		e.key += " " + value
+		e.label += " " + a.Raw
+	}
+	e.args = valueArgs
+	e.value = argsText(valueArgs)
+	return e
+}
+
+func argsText(args []*dslast.Arg) string {
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		parts = append(parts, a.Raw)
+	}
+	return strings.Join(parts, " ")
+}
+
+// sameArgs compares argument lists ignoring quoting style.
+func sameArgs(a, b []*dslast.Arg) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Value() != b[i].Value() {
+			return false
+		}
+	}
+	return true
+}
+
+// compare reports removed statements in old order, then changed and added
+// statements in new order, recursing into blocks present on both sides.
+func compare(path []string, olds, news []*dslast.Statement, out *[]Change) {
+	oldEntries, newEntries := entries(olds), entries(news)
+	byKey := make(map[string]entry, len(newEntries))
+	for _, e := range newEntries {
+		byKey[e.key] = e
+	}
+	oldByKey := make(map[string]entry, len(oldEntries))
+	for _, e := range oldEntries {
+		oldByKey[e.key] = e
+		if _, ok := byKey[e.key]; !ok {
+			*out = append(*out, Change{Kind: Removed, Path: join(path, e.label), Old: e.value})
+		}
+	}
+	for _, e := range newEntries {
+		old, ok := oldByKey[e.key]
+		if !ok {
+			*out = append(*out, Change{Kind: Added, Path: join(path, e.label), New: e.value})
+			continue
+		}
+		if e.stmt.IsBlock() {
+			compare(join(path, e.label), old.stmt.Children(), e.stmt.Children(), out)
+			continue
+		}
+		if !sameArgs(old.args, e.args) {
+			*out = append(*out, Change{Kind: Changed, Path: join(path, e.label), Old: old.value, New: e.value})
+		}
+	}
+}
+
+func join(path []string, label string) []string {
+	out := make([]string, len(path)+1)
+	copy(out, path)
+	out[len(path)] = label
+	return out
+}
@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextIgnoresFormattingAndComments(t *testing.T) {
+	t.Parallel()
+
+	oldText := "provider \"openai\" {\n  defaults { request { req_map openai_chat; } }\n}\n"
+	newText := "# reformatted\nprovider openai {\n  defaults {\n    request {\n      req_map \"openai_chat\";\n    }\n  }\n}\n"
+	if changes := Text(oldText, newText); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestTextReportsPathsAndValues(t *testing.T) {
+	t.Parallel()
+
+	oldText := `provider "openai" {
+  defaults {
+    request {
+      req_map openai_chat;
+      set_header Authorization "Bearer a";
+      del_header X-A;
+    }
+    response { resp_passthrough; }
+  }
+}
+`
+	newText := `provider "openai" {
+  defaults {
+    request {
+      del_header X-B;
+      set_header authorization "Bearer b";
+      req_map openai_chat_to_openai_responses;
+    }
+  }
+}
+provider "anthropic" {
+}
+`
+	var got []string
+	for _, c := range Text(oldText, newText) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		`- provider "openai" > defaults > response`,
+		`- provider "openai" > defaults > request > del_header X-A`,
+		`+ provider "openai" > defaults > request > del_header X-B`,
+		`~ provider "openai" > defaults > request > set_header authorization: "Bearer a" → "Bearer b"`,
+		`~ provider "openai" > defaults > request > req_map: openai_chat → openai_chat_to_openai_responses`,
+		`+ provider "anthropic"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected changes:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestTextComparesJSONPathsCaseSensitively(t *testing.T) {
+	t.Parallel()
+
+	oldText := "provider \"p\" {\n  defaults { request { json_set \"$.Model\" \"a\"; } }\n}\n"
+	newText := "provider \"p\" {\n  defaults { request { json_set \"$.model\" \"a\"; } }\n}\n"
+	var got []string
+	for _, c := range Text(oldText, newText) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		`- provider "p" > defaults > request > json_set "$.Model": "a"`,
+		`+ provider "p" > defaults > request > json_set "$.model": "a"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected changes:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestTextNumbersRepeatedBlocks(t *testing.T) {
+	t.Parallel()
+
+	oldText := "provider \"p\" {\n  match api = \"chat\" { upstream { set_path \"/a\"; } }\n  match api = \"chat\" { upstream { set_path \"/b\"; } }\n}\n"
+	newText := "provider \"p\" {\n  match api = \"chat\" { upstream { set_path \"/a\"; } }\n  match api = \"chat\" { upstream { set_path \"/c\"; } }\n}\n"
+	changes := Text(oldText, newText)
+	if len(changes) != 1 || changes[0].String() != `~ provider "p" > match api = "chat" #2 > upstream > set_path: "/b" → "/c"` {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestPathsComparesDirectories(t *testing.T) {
+	t.Parallel()
+
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	write := func(dir, name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write(oldDir, "providers/same.conf", "provider \"same\" {\n}\n")
+	write(newDir, "providers/same.conf", "provider same { }\n")
+	write(oldDir, "providers/gone.conf", "provider \"gone\" {\n}\n")
+	write(newDir, "providers/new.conf", "provider \"new\" {\n}\n")
+
+	files, err := Paths(oldDir, newDir)
+	if err != nil {
+		t.Fatalf("Paths: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected two changed files, got %+v", files)
+	}
+	if files[0].Path != "providers/gone.conf" || files[0].Status != Removed || files[0].Changes[0].String() != `- provider "gone"` {
+		t.Fatalf("unexpected removed file: %+v", files[0])
+	}
+	if files[1].Path != "providers/new.conf" || files[1].Status != Added || files[1].Changes[0].String() != `+ provider "new"` {
+		t.Fatalf("unexpected added file: %+v", files[1])
+	}
+	if _, err := Paths(oldDir, filepath.Join(newDir, "providers", "new.conf")); err == nil {
+		t.Fatalf("expected error comparing a directory with a file")
+	}
+}
+
+func TestExportGitPathRejectsOptionLikeRevisions(t *testing.T) {
+	t.Parallel()
+
+	out := filepath.Join(t.TempDir(), "out")
+	if _, err := ExportGitPath("--output="+out, ".", t.TempDir()); err == nil || !strings.Contains(err.Error(), "invalid revision") {
+		t.Fatalf("expected invalid revision error, got %v", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Fatalf("expected no file written by git, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ExportGitPath writes the config files under path at revision rev of the
+// git repository containing the working directory into dst, and returns the
+// exported file or directory. path is relative to the working directory, as
+// in `git show rev:./path`.
+func ExportGitPath(rev, target, dst string) (string, error) {
+	if strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("invalid revision %q", rev)
+	}
+	prefix, err := git("rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	full := path.Clean(path.Join(strings.TrimSuffix(prefix, "\n"), filepath.ToSlash(target)))
+	if full == "." {
+		full = ""
+	}
+	// -z keeps names with spaces or special characters unquoted, and
+	// --end-of-options keeps rev from being read as an option.
+	listing, err := git("ls-tree", "-r", "-z", "--name-only", "--full-tree", "--end-of-options", rev, "--", full)
+	if err != nil {
+		return "", err
+	}
+	names := strings.Split(strings.TrimSuffix(listing, "\x00"), "\x00")
+	if listing == "" {
+		names = nil
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("path %q does not exist in %s", target, rev)
+	}
+	if len(names) == 1 && names[0] == full {
+		out := filepath.Join(dst, path.Base(full))
+		return out, exportBlob(rev, full, out)
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		if path.Ext(name) != ".conf" {
+			continue
+		}
+		rel := name
+		if full != "" {
+			rel = strings.TrimPrefix(name, full+"/")
+		}
+		if err := exportBlob(rev, name, filepath.Join(dst, filepath.FromSlash(rel))); err != nil {
+			return "", err
+		}
+	}
+	return dst, nil
+}
+
+func exportBlob(rev, name, out string) error {
+	content, err := git("cat-file", "blob", "--end-of-options", rev+":"+name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(out, []byte(content), 0o644)
+}
+
+func git(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
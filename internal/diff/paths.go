@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+)
+
+// FileDiff holds the changes of one config file. Status is Added or Removed
+// when the file exists on one side only, and empty otherwise.
+type FileDiff struct {
+	Path    string   `json:"path,omitempty"`
+	Status  Kind     `json:"status,omitempty"`
+	Changes []Change `json:"changes"`
+}
+
+// Paths compares two config files, or two directories file by file. Files
+// are matched by their path relative to each directory; a file present on
+// one side only is compared against an empty document. Files without
+// structural changes are omitted.
+func Paths(oldPath, newPath string) ([]FileDiff, error) {
+	oldDir, err := isDir(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newDir, err := isDir(newPath)
+	if err != nil {
+		return nil, err
+	}
+	if oldDir != newDir {
+		return nil, fmt.Errorf("cannot compare a file with a directory: %q, %q", oldPath, newPath)
+	}
+	if !oldDir {
+		changes, err := compareFiles(oldPath, newPath)
+		if err != nil || len(changes) == 0 {
+			return nil, err
+		}
+		return []FileDiff{{Changes: changes}}, nil
+	}
+
+	oldFiles, err := relFiles(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := relFiles(newPath)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(oldFiles)+len(newFiles))
+	for name := range oldFiles {
+		names = append(names, name)
+	}
+	for name := range newFiles {
+		if _, ok := oldFiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var out []FileDiff
+	for _, name := range names {
+		fd := FileDiff{Path: name}
+		_, inOld := oldFiles[name]
+		_, inNew := newFiles[name]
+		switch {
+		case !inOld:
+			fd.Status = Added
+		case !inNew:
+			fd.Status = Removed
+		}
+		changes, err := compareFiles(oldFiles[name], newFiles[name])
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) == 0 && fd.Status == "" {
+			continue
+		}
+		fd.Changes = changes
+		out = append(out, fd)
+	}
+	return out, nil
+}
+
+func isDir(path string) (bool, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("stat %q: %w", path, err)
+	}
+	return st.IsDir(), nil
+}
+
+// relFiles maps slash-separated paths relative to dir to config files.
+func relFiles(dir string) (map[string]string, error) {
+	files, err := workspace.CollectFiles([]string{dir})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		out[filepath.ToSlash(rel)] = file
+	}
+	return out, nil
+}
+
+// compareFiles diffs two files; an empty path stands for an empty document.
+func compareFiles(oldPath, newPath string) ([]Change, error) {
+	oldText, err := readOptional(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newText, err := readOptional(newPath)
+	if err != nil {
+		return nil, err
+	}
+	return Text(oldText, newText), nil
+}
+
+func readOptional(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read file %q: %w", path, err)
+	}
+	return string(data), nil
+}
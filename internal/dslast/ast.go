@@ -0,0 +1,135 @@
+// Package dslast parses ONR DSL text into a lossless-enough syntax tree for
+// workspace analysis and structural tooling. Parsing is tolerant: malformed
+// input still yields a best-effort tree, and syntax errors are left to
+// dsllang diagnostics.
+package dslast
+
+import (
+	"strings"
+
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// File is a parsed DSL document.
+type File struct {
+	Statements []*Statement
+	// EndComments are comments after the last statement.
+	EndComments []*Comment
+}
+
+// Statement is one directive: either `name args...;` or `name args... { ... }`.
+type Statement struct {
+	Name      string
+	NameRange dsllang.Range
+	Args      []*Arg
+	// Block is set for block directives such as provider, defaults or request.
+	Block *Block
+
+	// Comments are the comment lines between the previous statement and this one.
+	Comments []*Comment
+	// TrailingComment is a comment on the same line after the terminator, or
+	// after the opening brace for block directives.
+	TrailingComment *Comment
+	// BlankLineBefore reports whether an empty line separates this statement
+	// (or its leading comments) from what precedes it.
+	BlankLineBefore bool
+	// Terminated reports whether the statement ended with ';' or '}'.
+	Terminated bool
+
+	Range dsllang.Range
+	Start int
+	End   int
+}
+
+// Block is the body of a block directive.
+type Block struct {
+	Statements []*Statement
+	// EndComments are comments after the last statement inside the block.
+	EndComments []*Comment
+	Open        dsllang.Range
+	Close       dsllang.Range
+	// Closed is false when the block runs to EOF without '}'.
+	Closed bool
+	// CloseComment is a comment on the same line after '}'.
+	CloseComment *Comment
+}
+
+// Arg is one whitespace-separated argument. Quoted strings and parenthesized
+// expressions stay in one argument.
+type Arg struct {
+	Raw    string
+	Range  dsllang.Range
+	Start  int
+	End    int
+	Quoted bool
+}
+
+// Comment is one `#` or `//` comment, including its marker.
+type Comment struct {
+	Text  string
+	Range dsllang.Range
+	Start int
+	End   int
+}
+
+// Value returns the argument with surrounding quotes removed.
+func (a *Arg) Value() string {
+	if !a.Quoted {
+		return a.Raw
+	}
+	return Unquote(a.Raw)
+}
+
+// Unquote removes matching surrounding quotes and resolves escaped quotes and
+// backslashes.
+func Unquote(raw string) string {
+	if len(raw) < 2 {
+		return raw
+	}
+	q := raw[0]
+	if (q != '"' && q != '\'') || raw[len(raw)-1] != q {
+		return raw
+	}
+	inner := raw[1 : len(raw)-1]
+	inner = strings.ReplaceAll(inner, `\`+string(q), string(q))
+	return strings.ReplaceAll(inner, `\\`, `\`)
+}
+
+// ArgValues returns unquoted argument values.
+func (s *Statement) ArgValues() []string {
+	out := make([]string, 0, len(s.Args))
+	for _, a := range s.Args {
+		out = append(out, a.Value())
+	}
+	return out
+}
+
+// ArgsRaw returns the source text of all arguments joined by single spaces.
+func (s *Statement) ArgsRaw() string {
+	parts := make([]string, 0, len(s.Args))
+	for _, a := range s.Args {
+		parts = append(parts, a.Raw)
+	}
+	return strings.Join(parts, " ")
+}
+
+// FirstArg returns the first argument value, or "" when there is none.
+func (s *Statement) FirstArg() string {
+	if len(s.Args) == 0 {
+		return ""
+	}
+	return s.Args[0].Value()
+}
+
+// IsBlock reports whether the statement has a body.
+func (s *Statement) IsBlock() bool {
+	return s.Block != nil
+}
+
+// Children returns the statements in the body, or nil for plain directives.
+func (s *Statement) Children() []*Statement {
+	if s.Block == nil {
+		return nil
+	}
+	return s.Block.Statements
+}
@@ -0,0 +1,310 @@
+package dslast
+
+import (
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokLBrace
+	tokRBrace
+	tokSemicolon
+	tokComment
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	start int
+	end   int
+	rng   dsllang.Range
+	// newlines counts line breaks between the previous token and this one.
+	newlines int
+}
+
+// Parse parses DSL text into a File.
+func Parse(src string) *File {
+	p := &parser{toks: scan(src)}
+	f := &File{}
+	f.Statements, f.EndComments, _ = p.parseStatements(false)
+	return f
+}
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// parseStatements parses statements until EOF or, inside a block, until the
+// closing brace, which is returned.
+func (p *parser) parseStatements(inBlock bool) ([]*Statement, []*Comment, *token) {
+	var (
+		stmts    []*Statement
+		pending  []*Comment
+		blankGap bool
+		last     *Statement
+	)
+	for {
+		tok := p.peek()
+		if tok.newlines > 1 && len(pending) == 0 {
+			blankGap = true
+		}
+		switch tok.kind {
+		case tokEOF:
+			return stmts, pending, nil
+		case tokRBrace:
+			p.next()
+			if inBlock {
+				return stmts, pending, &tok
+			}
+			// Stray '}' at file level: ignore it.
+			continue
+		case tokSemicolon:
+			// Empty statement, e.g. "};".
+			p.next()
+			continue
+		case tokComment:
+			p.next()
+			c := &Comment{Text: tok.text, Range: tok.rng, Start: tok.start, End: tok.end}
+			if tok.newlines == 0 && last != nil && last.TrailingComment == nil && len(pending) == 0 {
+				last.TrailingComment = c
+				continue
+			}
+			if len(pending) == 0 && tok.newlines > 1 {
+				blankGap = true
+			}
+			pending = append(pending, c)
+			continue
+		}
+		stmt := p.parseStatement()
+		stmt.Comments = pending
+		stmt.BlankLineBefore = blankGap && (len(stmts) > 0 || len(pending) > 0)
+		pending = nil
+		blankGap = false
+		stmts = append(stmts, stmt)
+		last = stmt
+		if stmt.Block != nil {
+			// Comments after '}' are captured by parseStatement.
+			last = nil
+		}
+	}
+}
+
+func (p *parser) parseStatement() *Statement {
+	first := p.next()
+	stmt := &Statement{Start: first.start, End: first.end}
+	stmt.Range = first.rng
+	if first.kind == tokWord {
+		stmt.Name = first.text
+		stmt.NameRange = first.rng
+	}
+	for {
+		tok := p.peek()
+		switch tok.kind {
+		case tokWord:
+			p.next()
+			stmt.Args = append(stmt.Args, &Arg{
+				Raw:    tok.text,
+				Range:  tok.rng,
+				Start:  tok.start,
+				End:    tok.end,
+				Quoted: isQuoted(tok.text),
+			})
+			stmt.extend(tok)
+		case tokComment:
+			// Comments between header words are kept as the trailing comment.
+			p.next()
+			if stmt.TrailingComment == nil {
+				stmt.TrailingComment = &Comment{Text: tok.text, Range: tok.rng, Start: tok.start, End: tok.end}
+			}
+		case tokSemicolon:
+			p.next()
+			stmt.extend(tok)
+			stmt.Terminated = true
+			p.takeTrailingComment(stmt)
+			return stmt
+		case tokLBrace:
+			p.next()
+			stmt.extend(tok)
+			block := &Block{Open: tok.rng}
+			p.takeTrailingComment(stmt)
+			var closeTok *token
+			block.Statements, block.EndComments, closeTok = p.parseStatements(true)
+			if closeTok != nil {
+				block.Close = closeTok.rng
+				block.Closed = true
+				stmt.Terminated = true
+				stmt.extend(*closeTok)
+				if tok := p.peek(); tok.kind == tokComment && tok.newlines == 0 {
+					p.next()
+					block.CloseComment = &Comment{Text: tok.text, Range: tok.rng, Start: tok.start, End: tok.end}
+				}
+			} else if n := len(block.Statements); n > 0 {
+				last := block.Statements[n-1]
+				stmt.End = last.End
+				stmt.Range.End = last.Range.End
+			}
+			stmt.Block = block
+			return stmt
+		default:
+			// '}' or EOF: statement is missing its ';'. Leave '}' for the
+			// enclosing block.
+			return stmt
+		}
+	}
+}
+
+func (p *parser) takeTrailingComment(stmt *Statement) {
+	tok := p.peek()
+	if tok.kind != tokComment || tok.newlines != 0 || stmt.TrailingComment != nil {
+		return
+	}
+	p.next()
+	stmt.TrailingComment = &Comment{Text: tok.text, Range: tok.rng, Start: tok.start, End: tok.end}
+}
+
+func (s *Statement) extend(tok token) {
+	s.End = tok.end
+	s.Range.End = tok.rng.End
+}
+
+func isQuoted(word string) bool {
+	if len(word) < 2 {
+		return false
+	}
+	q := word[0]
+	return (q == '"' || q == '\'') && word[len(word)-1] == q
+}
+
+// scan splits src into words, braces, semicolons and comments. Columns are
+// byte offsets within the line, matching dsllang diagnostics.
+func scan(src string) []token {
+	var out []token
+	line, col, newlines := 0, 0, 0
+	pos := func() dsllang.Position { return dsllang.Position{Line: line, Character: col} }
+	emit := func(kind tokenKind, start, end int, startPos dsllang.Position) {
+		out = append(out, token{
+			kind:     kind,
+			text:     src[start:end],
+			start:    start,
+			end:      end,
+			rng:      dsllang.Range{Start: startPos, End: pos()},
+			newlines: newlines,
+		})
+		newlines = 0
+	}
+
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == '\n':
+			line++
+			col = 0
+			newlines++
+			i++
+			continue
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			col++
+			i++
+			continue
+		}
+
+		start, startPos := i, pos()
+		switch {
+		case ch == '#' || (ch == '/' && i+1 < len(src) && src[i+1] == '/'):
+			for i < len(src) && src[i] != '\n' {
+				i++
+				col++
+			}
+			end := i
+			for end > start && src[end-1] == '\r' {
+				end--
+			}
+			out = append(out, token{
+				kind:     tokComment,
+				text:     src[start:end],
+				start:    start,
+				end:      end,
+				rng:      dsllang.Range{Start: startPos, End: dsllang.Position{Line: line, Character: startPos.Character + end - start}},
+				newlines: newlines,
+			})
+			newlines = 0
+		case ch == '{':
+			i++
+			col++
+			emit(tokLBrace, start, i, startPos)
+		case ch == '}':
+			i++
+			col++
+			emit(tokRBrace, start, i, startPos)
+		case ch == ';':
+			i++
+			col++
+			emit(tokSemicolon, start, i, startPos)
+		default:
+			i = scanWord(src, i)
+			col += i - start
+			emit(tokWord, start, i, startPos)
+		}
+	}
+	out = append(out, token{kind: tokEOF, start: len(src), end: len(src), rng: dsllang.Range{Start: pos(), End: pos()}, newlines: newlines})
+	return out
+}
+
+// scanWord returns the end offset of the word starting at i. Quotes and
+// parentheses group text containing spaces or terminators; a word never spans
+// lines.
+func scanWord(src string, i int) int {
+	var quote byte
+	depth := 0
+	for ; i < len(src); i++ {
+		ch := src[i]
+		if ch == '\n' {
+			return i
+		}
+		if quote != 0 {
+			if ch == '\\' && i+1 < len(src) && src[i+1] != '\n' {
+				i++
+				continue
+			}
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'':
+			quote = ch
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ' ', '\t', '\r', ';', '{', '}', '#':
+			if depth == 0 {
+				return i
+			}
+		case '/':
+			if depth == 0 && i+1 < len(src) && src[i+1] == '/' {
+				return i
+			}
+		}
+	}
+	return i
+}
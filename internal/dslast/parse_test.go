@@ -0,0 +1,123 @@
+package dslast
+
+import (
+	"testing"
+)
+
+func TestParseStatementsAndBlocks(t *testing.T) {
+	t.Parallel()
+
+	src := "syntax \"next-router/0.1\";\n" +
+		"include modes/*.conf;\n" +
+		"\n" +
+		"# OpenAI provider\n" +
+		"provider \"openai\" { # main\n" +
+		"  defaults {\n" +
+		"    upstream_config { base_url = \"https://api.openai.com\"; }\n" +
+		"    auth { auth_bearer; }\n" +
+		"  } # end defaults\n" +
+		"}\n"
+	f := Parse(src)
+	if len(f.Statements) != 3 {
+		t.Fatalf("expected 3 top-level statements, got %d", len(f.Statements))
+	}
+	syntax, include, provider := f.Statements[0], f.Statements[1], f.Statements[2]
+	if syntax.Name != "syntax" || syntax.FirstArg() != "next-router/0.1" || !syntax.Terminated {
+		t.Fatalf("unexpected syntax statement: %+v", syntax)
+	}
+	if include.Args[0].Raw != "modes/*.conf" || include.Args[0].Quoted {
+		t.Fatalf("unexpected include arg: %+v", include.Args[0])
+	}
+	if provider.FirstArg() != "openai" || !provider.IsBlock() || !provider.Block.Closed {
+		t.Fatalf("unexpected provider statement: %+v", provider)
+	}
+	if !provider.BlankLineBefore || len(provider.Comments) != 1 || provider.Comments[0].Text != "# OpenAI provider" {
+		t.Fatalf("expected leading comment and blank line, got %+v", provider)
+	}
+	if provider.TrailingComment == nil || provider.TrailingComment.Text != "# main" {
+		t.Fatalf("expected trailing comment after '{', got %+v", provider.TrailingComment)
+	}
+	defaults := provider.Children()[0]
+	if defaults.Block.CloseComment == nil || defaults.Block.CloseComment.Text != "# end defaults" {
+		t.Fatalf("expected close comment, got %+v", defaults.Block.CloseComment)
+	}
+	upstream := defaults.Children()[0]
+	baseURL := upstream.Children()[0]
+	if baseURL.Name != "base_url" || baseURL.ArgsRaw() != `= "https://api.openai.com"` {
+		t.Fatalf("unexpected base_url statement: %q %q", baseURL.Name, baseURL.ArgsRaw())
+	}
+	if got := baseURL.Args[1].Range.Start; got.Line != 6 || got.Character != 33 {
+		t.Fatalf("unexpected arg position: %+v", got)
+	}
+	if provider.Range.End.Line != 9 || provider.Range.End.Character != 1 {
+		t.Fatalf("unexpected provider range: %+v", provider.Range)
+	}
+}
+
+func TestParseKeepsExpressionsInOneArg(t *testing.T) {
+	t.Parallel()
+
+	f := Parse("json_set $.model concat(\"a b\", ';', $.x);\n")
+	stmt := f.Statements[0]
+	if len(stmt.Args) != 2 {
+		t.Fatalf("expected 2 args, got %q", stmt.ArgValues())
+	}
+	if stmt.Args[1].Raw != `concat("a b", ';', $.x)` {
+		t.Fatalf("unexpected expression arg: %q", stmt.Args[1].Raw)
+	}
+}
+
+func TestParseToleratesMalformedInput(t *testing.T) {
+	t.Parallel()
+
+	f := Parse("provider \"x\" {\n  defaults {\n    auth { auth_bearer }\n")
+	if len(f.Statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(f.Statements))
+	}
+	provider := f.Statements[0]
+	if provider.Block.Closed {
+		t.Fatalf("expected unclosed provider block")
+	}
+	auth := provider.Children()[0].Children()[0]
+	if !auth.Block.Closed || auth.Children()[0].Terminated {
+		t.Fatalf("expected closed auth block with unterminated directive: %+v", auth)
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		`"a"`:       "a",
+		`'b'`:       "b",
+		`"a\"b"`:    `a"b`,
+		`"c:\\dir"`: `c:\dir`,
+		`plain`:     "plain",
+		`"open`:     `"open`,
+	}
+	for in, want := range cases {
+		if got := Unquote(in); got != want {
+			t.Fatalf("Unquote(%s) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWalkReportsBlockName(t *testing.T) {
+	t.Parallel()
+
+	f := Parse("provider \"p\" { defaults { metrics { usage_extract x; } } }\n")
+	var got []string
+	Walk(f, func(stmt *Statement, parents []*Statement) bool {
+		got = append(got, BlockName(parents)+">"+stmt.Name)
+		return true
+	})
+	want := []string{"top>provider", "provider>defaults", "defaults>metrics", "metrics>usage_extract"}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected walk: %q", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected walk: %q", got)
+		}
+	}
+}
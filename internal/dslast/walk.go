@@ -0,0 +1,37 @@
+package dslast
+
+// Visitor is called for each statement with its enclosing block statements,
+// outermost first. Returning false skips the statement's children.
+type Visitor func(stmt *Statement, parents []*Statement) bool
+
+// Walk visits every statement in f in source order.
+func Walk(f *File, visit Visitor) {
+	if f == nil {
+		return
+	}
+	walkStatements(f.Statements, nil, visit)
+}
+
+func walkStatements(stmts []*Statement, parents []*Statement, visit Visitor) {
+	for _, stmt := range stmts {
+		if !visit(stmt, parents) {
+			continue
+		}
+		if stmt.Block == nil {
+			continue
+		}
+		next := make([]*Statement, len(parents)+1)
+		copy(next, parents)
+		next[len(parents)] = stmt
+		walkStatements(stmt.Block.Statements, next, visit)
+	}
+}
+
+// BlockName returns the DSL block name that contains a statement with the
+// given parents: "top" at file level, otherwise the innermost parent name.
+func BlockName(parents []*Statement) string {
+	if len(parents) == 0 {
+		return "top"
+	}
+	return parents[len(parents)-1].Name
+}
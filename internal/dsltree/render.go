@@ -0,0 +1,145 @@
+package dsltree
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// Render turns a tree back into formatted DSL text. Rendering a tree built by
+// Parse yields the same text as formatting the source with dsllang.FormatText.
+func Render(t *Tree, opts dsllang.FormatOptions) (string, error) {
+	if t.Version != 0 && t.Version != Version {
+		return "", fmt.Errorf("unsupported tree version %d (want %d)", t.Version, Version)
+	}
+	var b strings.Builder
+	if err := writeNodes(&b, t.Statements, "statements"); err != nil {
+		return "", err
+	}
+	if err := writeComments(&b, t.EndComments, "endComments"); err != nil {
+		return "", err
+	}
+	return dsllang.FormatText(b.String(), opts), nil
+}
+
+func writeNodes(b *strings.Builder, nodes []*Node, path string) error {
+	for i, n := range nodes {
+		at := fmt.Sprintf("%s[%d]", path, i)
+		if n == nil {
+			return fmt.Errorf("%s: null statement", at)
+		}
+		if n.Name == "" || strings.ContainsAny(n.Name, " \t\r\n;{}#\"'") {
+			return fmt.Errorf("%s: invalid directive name %q", at, n.Name)
+		}
+		if n.BlankLineBefore && b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		if err := writeComments(b, n.Comments, at+".comments"); err != nil {
+			return err
+		}
+		b.WriteString(n.Name)
+		for j, a := range n.Args {
+			if a == nil {
+				return fmt.Errorf("%s.args[%d]: null argument", at, j)
+			}
+			b.WriteByte(' ')
+			b.WriteString(argText(a))
+		}
+		if n.Block == nil {
+			b.WriteByte(';')
+			if err := writeTrailing(b, n.TrailingComment, at+".trailingComment"); err != nil {
+				return err
+			}
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString(" {")
+		if err := writeTrailing(b, n.TrailingComment, at+".trailingComment"); err != nil {
+			return err
+		}
+		b.WriteByte('\n')
+		if err := writeNodes(b, n.Block.Statements, at+".block.statements"); err != nil {
+			return err
+		}
+		if err := writeComments(b, n.Block.EndComments, at+".block.endComments"); err != nil {
+			return err
+		}
+		b.WriteByte('}')
+		if err := writeTrailing(b, n.Block.CloseComment, at+".block.closeComment"); err != nil {
+			return err
+		}
+		b.WriteByte('\n')
+	}
+	return nil
+}
+
+func writeComments(b *strings.Builder, comments []*Comment, path string) error {
+	for i, c := range comments {
+		text, err := commentText(c, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return err
+		}
+		b.WriteString(text)
+		b.WriteByte('\n')
+	}
+	return nil
+}
+
+func writeTrailing(b *strings.Builder, c *Comment, path string) error {
+	if c == nil {
+		return nil
+	}
+	text, err := commentText(c, path)
+	if err != nil {
+		return err
+	}
+	b.WriteByte(' ')
+	b.WriteString(text)
+	return nil
+}
+
+// commentText returns the comment, adding a `# ` marker when it has none.
+func commentText(c *Comment, path string) (string, error) {
+	if c == nil {
+		return "", fmt.Errorf("%s: null comment", path)
+	}
+	if strings.ContainsAny(c.Text, "\r\n") {
+		return "", fmt.Errorf("%s: comment spans several lines", path)
+	}
+	text := strings.TrimSpace(c.Text)
+	if !strings.HasPrefix(text, "#") && !strings.HasPrefix(text, "//") {
+		text = "# " + text
+	}
+	return text, nil
+}
+
+// argText returns the source form of an argument. Raw is kept only while it
+// still reads back as Value with the same quoting, so an edited value or
+// quoted flag is rendered from the value instead.
+func argText(a *Arg) string {
+	if a.Raw != "" {
+		raw := &dslast.Arg{Raw: a.Raw, Quoted: isQuoted(a.Raw)}
+		if raw.Quoted == a.Quoted && raw.Value() == a.Value {
+			return a.Raw
+		}
+	}
+	if !a.Quoted && isBareWord(a.Value) {
+		return a.Value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(a.Value) + `"`
+}
+
+// isQuoted reports whether raw is wrapped in matching quotes.
+func isQuoted(raw string) bool {
+	return len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0]
+}
+
+// isBareWord reports whether v reads back as one unquoted argument.
+func isBareWord(v string) bool {
+	if v == "" || strings.HasPrefix(v, "//") {
+		return false
+	}
+	return !strings.ContainsAny(v, " \t\r\n;{}#\"'()")
+}
@@ -0,0 +1,157 @@
+// Package dsltree converts DSL syntax trees to and from a stable data model
+// meant for JSON and YAML, so configs can be inspected or generated from
+// structured data.
+//
+// The model is versioned. Version 1 is:
+//
+//	Tree    {version, statements: [Node], endComments: [Comment]}
+//	Node    {name, args: [Arg], block: Block, comments: [Comment],
+//	         trailingComment: Comment, blankLineBefore, range}
+//	Block   {statements: [Node], endComments: [Comment], closeComment: Comment}
+//	Arg     {value, quoted, raw, range}
+//	Comment {text, range}
+//
+// Ranges are zero-based LSP ranges and are ignored by Render. A node without
+// a block is a plain directive; an empty block is written as `"block": {}`.
+// Arg.raw is the exact source text; when it is empty Render builds the
+// argument from value, adding double quotes when quoted is set or the value
+// could not be read back as one bare word.
+package dsltree
+
+import (
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// Version is the model version written by FromFile.
+const Version = 1
+
+// Tree is a serialized DSL document.
+type Tree struct {
+	Version     int        `json:"version" yaml:"version"`
+	Statements  []*Node    `json:"statements" yaml:"statements"`
+	EndComments []*Comment `json:"endComments,omitempty" yaml:"endComments,omitempty"`
+}
+
+// Node is one directive or block.
+type Node struct {
+	Name            string         `json:"name" yaml:"name"`
+	Args            []*Arg         `json:"args,omitempty" yaml:"args,omitempty"`
+	Block           *Block         `json:"block,omitempty" yaml:"block,omitempty"`
+	Comments        []*Comment     `json:"comments,omitempty" yaml:"comments,omitempty"`
+	TrailingComment *Comment       `json:"trailingComment,omitempty" yaml:"trailingComment,omitempty"`
+	BlankLineBefore bool           `json:"blankLineBefore,omitempty" yaml:"blankLineBefore,omitempty"`
+	Range           *dsllang.Range `json:"range,omitempty" yaml:"range,omitempty"`
+}
+
+// Block is the body of a block node.
+type Block struct {
+	Statements   []*Node    `json:"statements" yaml:"statements"`
+	EndComments  []*Comment `json:"endComments,omitempty" yaml:"endComments,omitempty"`
+	CloseComment *Comment   `json:"closeComment,omitempty" yaml:"closeComment,omitempty"`
+}
+
+// Arg is one argument.
+type Arg struct {
+	Value  string         `json:"value" yaml:"value"`
+	Quoted bool           `json:"quoted,omitempty" yaml:"quoted,omitempty"`
+	Raw    string         `json:"raw,omitempty" yaml:"raw,omitempty"`
+	Range  *dsllang.Range `json:"range,omitempty" yaml:"range,omitempty"`
+}
+
+// Comment is one `#` or `//` comment including its marker.
+type Comment struct {
+	Text  string         `json:"text" yaml:"text"`
+	Range *dsllang.Range `json:"range,omitempty" yaml:"range,omitempty"`
+}
+
+// Parse parses DSL text into a tree.
+func Parse(src string) *Tree {
+	return FromFile(dslast.Parse(src))
+}
+
+// FromFile converts a parsed document.
+func FromFile(f *dslast.File) *Tree {
+	return &Tree{
+		Version:     Version,
+		Statements:  fromStatements(f.Statements),
+		EndComments: fromComments(f.EndComments),
+	}
+}
+
+func fromStatements(stmts []*dslast.Statement) []*Node {
+	out := make([]*Node, 0, len(stmts))
+	for _, stmt := range stmts {
+		n := &Node{
+			Name:            stmt.Name,
+			Comments:        fromComments(stmt.Comments),
+			TrailingComment: fromComment(stmt.TrailingComment),
+			BlankLineBefore: stmt.BlankLineBefore,
+			Range:           rangeOf(stmt.Range),
+		}
+		for _, a := range stmt.Args {
+			n.Args = append(n.Args, &Arg{Value: a.Value(), Quoted: a.Quoted, Raw: a.Raw, Range: rangeOf(a.Range)})
+		}
+		if stmt.Block != nil {
+			n.Block = &Block{
+				Statements:   fromStatements(stmt.Block.Statements),
+				EndComments:  fromComments(stmt.Block.EndComments),
+				CloseComment: fromComment(stmt.Block.CloseComment),
+			}
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func fromComments(comments []*dslast.Comment) []*Comment {
+	if len(comments) == 0 {
+		return nil
+	}
+	out := make([]*Comment, 0, len(comments))
+	for _, c := range comments {
+		out = append(out, fromComment(c))
+	}
+	return out
+}
+
+func fromComment(c *dslast.Comment) *Comment {
+	if c == nil {
+		return nil
+	}
+	return &Comment{Text: c.Text, Range: rangeOf(c.Range)}
+}
+
+func rangeOf(r dsllang.Range) *dsllang.Range {
+	return &r
+}
+
+// StripRanges removes source ranges, leaving only what Render uses.
+func (t *Tree) StripRanges() {
+	stripComments(t.EndComments)
+	stripNodes(t.Statements)
+}
+
+func stripNodes(nodes []*Node) {
+	for _, n := range nodes {
+		n.Range = nil
+		for _, a := range n.Args {
+			a.Range = nil
+		}
+		stripComments(n.Comments)
+		stripComments([]*Comment{n.TrailingComment})
+		if n.Block != nil {
+			stripNodes(n.Block.Statements)
+			stripComments(n.Block.EndComments)
+			stripComments([]*Comment{n.Block.CloseComment})
+		}
+	}
+}
+
+func stripComments(comments []*Comment) {
+	for _, c := range comments {
+		if c != nil {
+			c.Range = nil
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package dsltree
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+var formatOpts = dsllang.FormatOptions{TabSize: 2, InsertSpaces: true}
+
+var codecs = []struct {
+	name  string
+	write func(io.Writer, *Tree) error
+	read  func(io.Reader) (*Tree, error)
+}{
+	{"json", WriteJSON, ReadJSON},
+	{"yaml", WriteYAML, ReadYAML},
+}
+
+func TestRenderRoundTripsCorpus(t *testing.T) {
+	t.Parallel()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "*.conf"))
+	nested, _ := filepath.Glob(filepath.Join("testdata", "*", "*.conf"))
+	files = append(files, nested...)
+	if err != nil || len(files) == 0 {
+		t.Fatalf("glob corpus: %v (%d files)", err, len(files))
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		// A minified copy must render to the same formatted text.
+		for _, text := range []string{string(src), squash(string(src))} {
+			for _, c := range codecs {
+				var buf bytes.Buffer
+				if err := c.write(&buf, Parse(text)); err != nil {
+					t.Fatalf("%s: write %s: %v", file, c.name, err)
+				}
+				tree, err := c.read(&buf)
+				if err != nil {
+					t.Fatalf("%s: read %s: %v", file, c.name, err)
+				}
+				if want := Parse(text); !reflect.DeepEqual(tree, want) {
+					t.Fatalf("%s: %s tree differs after decoding", file, c.name)
+				}
+				got, err := Render(tree, formatOpts)
+				if err != nil {
+					t.Fatalf("%s: Render: %v", file, err)
+				}
+				if want := dsllang.FormatText(text, formatOpts); got != want {
+					t.Fatalf("%s: render differs from format:\n--- got\n%s\n--- want\n%s", file, got, want)
+				}
+
+				again := Parse(got)
+				tree.StripRanges()
+				again.StripRanges()
+				if !reflect.DeepEqual(tree, again) {
+					t.Fatalf("%s: tree changed after render and parse", file)
+				}
+			}
+		}
+	}
+}
+
+// squash joins lines without comments, leaving formatting to the renderer.
+func squash(src string) string {
+	var parts []string
+	for _, line := range strings.Split(src, "\n") {
+		if strings.Contains(line, "#") || strings.Contains(line, "//") {
+			parts = append(parts, "\n"+line+"\n")
+			continue
+		}
+		parts = append(parts, strings.TrimSpace(line))
+	}
+	return strings.TrimSpace(strings.Join(parts, " ")) + "\n"
+}
+
+func TestRenderGeneratedTree(t *testing.T) {
+	t.Parallel()
+
+	tree, err := ReadJSON(strings.NewReader(`{
+  "version": 1,
+  "statements": [{
+    "name": "provider",
+    "args": [{"value": "catalog", "quoted": true}],
+    "comments": [{"text": "generated from the service catalog"}],
+    "block": {"statements": [
+      {"name": "defaults", "block": {"statements": [
+        {"name": "request", "block": {"statements": [
+          {"name": "set_header", "args": [{"value": "X-Team"}, {"value": "platform team"}]},
+          {"name": "req_map", "args": [{"value": "openai_chat_to_openai_responses"}]}
+        ]}},
+        {"name": "response", "block": {}}
+      ]}}
+    ]}
+  }]
+}`))
+	if err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	got, err := Render(tree, formatOpts)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	want := `# generated from the service catalog
+provider "catalog" {
+  defaults {
+    request {
+      set_header X-Team "platform team";
+      req_map openai_chat_to_openai_responses;
+    }
+    response {
+    }
+  }
+}
+`
+	if got != want {
+		t.Fatalf("unexpected render:\n%s", got)
+	}
+}
+
+func TestRenderEditedValues(t *testing.T) {
+	t.Parallel()
+
+	tree := Parse("provider \"p\" {\n  defaults {\n    request {\n      set_header X-Mode \"on\";\n      set_header X-Team 'core';\n      req_map openai_chat;\n    }\n  }\n}\n")
+	request := tree.Statements[0].Block.Statements[0].Block.Statements[0].Block.Statements
+	request[0].Args[1].Value = "off"
+	request[1].Args[1].Quoted = false
+	request[2].Args[0].Value = "openai_chat_to_openai_responses"
+	got, err := Render(tree, formatOpts)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	want := `provider "p" {
+  defaults {
+    request {
+      set_header X-Mode "off";
+      set_header X-Team core;
+      req_map openai_chat_to_openai_responses;
+    }
+  }
+}
+`
+	if got != want {
+		t.Fatalf("unexpected render:\n%s", got)
+	}
+}
+
+func TestRenderRejectsInvalidTrees(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		json string
+		want string
+	}{
+		{`{"version": 2, "statements": []}`, "unsupported tree version 2"},
+		{`{"version": 1, "statements": [{"name": "a b"}]}`, `statements[0]: invalid directive name "a b"`},
+		{`{"version": 1, "statements": [{"name": "p", "block": {"statements": [{"name": ""}]}}]}`, "statements[0].block.statements[0]: invalid directive name"},
+	} {
+		tree, err := ReadJSON(strings.NewReader(tc.json))
+		if err != nil {
+			t.Fatalf("ReadJSON %s: %v", tc.json, err)
+		}
+		if _, err := Render(tree, formatOpts); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("expected %q, got %v", tc.want, err)
+		}
+	}
+	if _, err := ReadJSON(strings.NewReader(`{"version": 1, "statement": []}`)); err == nil {
+		t.Fatalf("expected unknown field error")
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	t.Parallel()
+
+	tree := Parse("# note\nprovider \"a\" { auth { auth_bearer; } }\n")
+	tree.StripRanges()
+	var out bytes.Buffer
+	if err := WriteYAML(&out, tree); err != nil {
+		t.Fatalf("WriteYAML: %v", err)
+	}
+	want := `version: 1
+statements:
+  - name: provider
+    args:
+      - value: a
+        quoted: true
+        raw: '"a"'
+    block:
+      statements:
+        - name: auth
+          block:
+            statements:
+              - name: auth_bearer
+    comments:
+      - text: '# note'
+`
+	if out.String() != want {
+		t.Fatalf("unexpected yaml:\n%s", out.String())
+	}
+}
+
+func TestReadYAML(t *testing.T) {
+	t.Parallel()
+
+	tree, err := ReadYAML(strings.NewReader(`version: 1
+statements:
+  - name: provider
+    args:
+      - value: catalog
+        quoted: true
+    block:
+      statements:
+        - name: upstream_config
+          block:
+            statements:
+              - name: base_url
+                args:
+                  - value: =
+                  - value: https://example.com
+                    quoted: true
+        - name: request
+          block:
+            statements:
+              - name: set_header
+                args: [{value: X-Port}, {value: 8080}]
+`))
+	if err != nil {
+		t.Fatalf("ReadYAML: %v", err)
+	}
+	got, err := Render(tree, formatOpts)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	want := `provider "catalog" {
+  upstream_config {
+    base_url = "https://example.com";
+  }
+  request {
+    set_header X-Port 8080;
+  }
+}
+`
+	if got != want {
+		t.Fatalf("unexpected render:\n%s", got)
+	}
+	if _, err := ReadYAML(strings.NewReader("version: 1\nstatement: []\n")); err == nil {
+		t.Fatalf("expected unknown field error")
+	}
+}
@@ -0,0 +1,51 @@
+package dsltree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteJSON writes the tree as indented JSON.
+func WriteJSON(w io.Writer, t *Tree) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// ReadJSON decodes a tree, rejecting unknown fields so typos in generated
+// input are reported instead of silently dropped.
+func ReadJSON(r io.Reader) (*Tree, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var t Tree
+	if err := dec.Decode(&t); err != nil {
+		return nil, fmt.Errorf("decode tree: %w", err)
+	}
+	return &t, nil
+}
+
+// ReadYAML decodes a tree written by WriteYAML or by hand. Like ReadJSON it
+// rejects unknown fields.
+func ReadYAML(r io.Reader) (*Tree, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var t Tree
+	if err := dec.Decode(&t); err != nil {
+		return nil, fmt.Errorf("decode tree: %w", err)
+	}
+	return &t, nil
+}
+
+// WriteYAML writes the tree as block-style YAML with the same fields and
+// field order as the JSON form.
+func WriteYAML(w io.Writer, t *Tree) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(t); err != nil {
+		return err
+	}
+	return enc.Close()
+}
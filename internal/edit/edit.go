@@ -0,0 +1,316 @@
+// Package edit changes single directives in DSL text by structural path while
+// leaving the rest of the file, including comments and formatting, as is.
+package edit
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/query"
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dslspec"
+)
+
+// textEdit replaces src[start:end] with text.
+type textEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// Set points the directive addressed by path at values. path is a query
+// selector whose last step names the directive, e.g.
+// `provider[openai].defaults.auth.oauth_content_type`. Every block the
+// selector matches gets the directive; when no block matches, the missing
+// blocks are created under the deepest block that does. Keyed directives
+// such as set_header are matched by their first value, list directives such
+// as del_header are only added when not already present.
+func Set(src, path string, values []string) (string, error) {
+	sel, err := query.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	n := sel.Len()
+	name := sel.Name(n - 1)
+	if name == "" || sel.HasFilters(n-1) {
+		return "", fmt.Errorf("last step of %q must be a plain directive name", path)
+	}
+	if len(values) == 0 {
+		return "", fmt.Errorf("set %s needs at least one value", name)
+	}
+	f := dslast.Parse(src)
+
+	// Find the deepest existing blocks on the path.
+	k := n - 1
+	var parents []query.Match
+	for ; k > 0; k-- {
+		if parents = sel.Prefix(k).Select(f); len(parents) > 0 {
+			break
+		}
+	}
+	var headers []string
+	for i := k; i < n-1; i++ {
+		header, err := sel.Header(i)
+		if err != nil {
+			return "", fmt.Errorf("%s does not exist and %w", sel.Prefix(i+1), err)
+		}
+		headers = append(headers, header)
+	}
+	args := make([]string, 0, len(values))
+	for _, v := range values {
+		args = append(args, formatValue(v))
+	}
+	directive := name + " " + strings.Join(args, " ") + ";"
+
+	unit := indentUnit(src)
+	var edits []textEdit
+	if k == 0 {
+		if err := validate("top", headers, name, values); err != nil {
+			return "", err
+		}
+		if existing := findDirective(f.Statements, name, values); existing != nil && len(headers) == 0 {
+			edits = append(edits, textEdit{start: existing.Start, end: existing.End, text: directive})
+		} else {
+			edits = append(edits, appendTopLevel(src, nest(headers, directive, unit)))
+		}
+	}
+	for _, p := range parents {
+		if !p.Stmt.IsBlock() {
+			return "", fmt.Errorf("%s at line %d is not a block", query.Label(p.Stmt), p.Stmt.Range.Start.Line+1)
+		}
+		if err := validate(p.Stmt.Name, headers, name, values); err != nil {
+			return "", err
+		}
+		if len(headers) == 0 {
+			if existing := findDirective(p.Stmt.Children(), name, values); existing != nil {
+				if slices.Equal(existing.ArgValues(), unquoteAll(args)) && existing.Terminated {
+					continue
+				}
+				edits = append(edits, textEdit{start: existing.Start, end: existing.End, text: directive})
+				continue
+			}
+		}
+		e, err := insertInBlock(src, p.Stmt, nest(headers, directive, unit), unit)
+		if err != nil {
+			return "", err
+		}
+		edits = append(edits, e)
+	}
+	return apply(src, edits), nil
+}
+
+// Unset removes every statement path matches. Comments above a removed
+// statement are kept; a comment on the same line goes with it.
+func Unset(src, path string) (string, error) {
+	sel, err := query.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	matches := sel.Select(dslast.Parse(src))
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no statement matches %q", path)
+	}
+	var edits []textEdit
+	lastEnd := -1
+	for _, m := range matches {
+		if m.Stmt.Start < lastEnd {
+			// Already removed with an enclosing match.
+			continue
+		}
+		e := removal(src, m.Stmt)
+		edits = append(edits, e)
+		lastEnd = m.Stmt.End
+	}
+	return apply(src, edits), nil
+}
+
+// validate checks created blocks and the directive against dslspec.
+func validate(block string, headers []string, name string, values []string) error {
+	for _, h := range headers {
+		blockName, _, _ := strings.Cut(h, " ")
+		if !slices.Contains(dslspec.DirectivesByBlock(block), blockName) || !dslspec.DirectiveIsBlockInBlock(blockName, block) {
+			return fmt.Errorf("%s is not a block allowed in %s", blockName, block)
+		}
+		block = blockName
+	}
+	if !slices.Contains(dslspec.DirectivesByBlock(block), name) {
+		return fmt.Errorf("%s is not allowed in %s (allowed in: %s)", name, block, strings.Join(dslspec.DirectiveAllowedBlocks(name), ", "))
+	}
+	if dslspec.DirectiveIsBlockInBlock(name, block) {
+		return fmt.Errorf("%s is a block; set only writes plain directives", name)
+	}
+	for i, v := range values {
+		enum := dslspec.DirectiveArgEnumValuesInBlock(name, block, i)
+		if len(enum) > 0 && !slices.Contains(enum, dslast.Unquote(v)) {
+			return fmt.Errorf("invalid %s value %q (want %s)", name, v, strings.Join(enum, ", "))
+		}
+	}
+	return nil
+}
+
+// findDirective returns the statement a new `name values...` would replace:
+// the effective (last) one for single-valued directives, the one with the same
+// key for keyed directives, or an identical one for list directives.
+func findDirective(stmts []*dslast.Statement, name string, values []string) *dslast.Statement {
+	var found *dslast.Statement
+	for _, stmt := range stmts {
+		if stmt.Name != name || stmt.IsBlock() {
+			continue
+		}
+		switch workspace.DirectiveKeyArgs(name) {
+		case -1:
+			if slices.Equal(stmt.ArgValues(), unquoteAll(values)) {
+				found = stmt
+			}
+		case 1:
+			if workspace.DirectiveKey(name, stmt.FirstArg()) == workspace.DirectiveKey(name, dslast.Unquote(values[0])) {
+				found = stmt
+			}
+		default:
+			found = stmt
+		}
+	}
+	return found
+}
+
+func unquoteAll(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, dslast.Unquote(v))
+	}
+	return out
+}
+
+var bareValue = regexp.MustCompile(`^([A-Za-z0-9_.+-]+|=|\(.*\))$`)
+
+// formatValue quotes a command-line value unless it is a bare word, an `=`
+// or already quoted.
+func formatValue(v string) string {
+	if bareValue.MatchString(v) || dslast.Unquote(v) != v || v == `""` {
+		return v
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+// nest wraps directive in the given block headers, one level per header.
+func nest(headers []string, directive, unit string) []string {
+	lines := make([]string, 0, 2*len(headers)+1)
+	for i, h := range headers {
+		lines = append(lines, strings.Repeat(unit, i)+h+" {")
+	}
+	lines = append(lines, strings.Repeat(unit, len(headers))+directive)
+	for i := len(headers) - 1; i >= 0; i-- {
+		lines = append(lines, strings.Repeat(unit, i)+"}")
+	}
+	return lines
+}
+
+// insertInBlock adds lines as the last statements of a block.
+func insertInBlock(src string, blk *dslast.Statement, lines []string, unit string) (textEdit, error) {
+	closeAt := blk.End - 1
+	if !blk.Block.Closed || closeAt < 0 || closeAt >= len(src) || src[closeAt] != '}' {
+		return textEdit{}, fmt.Errorf("%s at line %d is not closed", query.Label(blk), blk.Range.Start.Line+1)
+	}
+	lineStart := strings.LastIndexByte(src[:closeAt], '\n') + 1
+	if indent := src[lineStart:closeAt]; strings.TrimSpace(indent) == "" {
+		var b strings.Builder
+		for _, line := range lines {
+			b.WriteString(indent + unit + line + "\n")
+		}
+		return textEdit{start: lineStart, end: lineStart, text: b.String()}, nil
+	}
+	// One-line block such as `auth { auth_bearer; }`.
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		parts = append(parts, strings.TrimSpace(line))
+	}
+	text := strings.Join(parts, " ") + " "
+	if c := src[closeAt-1]; c != ' ' && c != '\t' {
+		text = " " + text
+	}
+	return textEdit{start: closeAt, end: closeAt, text: text}, nil
+}
+
+// appendTopLevel adds lines at the end of the file after a blank line.
+func appendTopLevel(src string, lines []string) textEdit {
+	var b strings.Builder
+	if src != "" {
+		if !strings.HasSuffix(src, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+	return textEdit{start: len(src), end: len(src), text: b.String()}
+}
+
+// removal deletes a statement and its same-line comment, taking the whole
+// line when nothing else is on it.
+func removal(src string, stmt *dslast.Statement) textEdit {
+	start, end := stmt.Start, stmt.End
+	if c := stmt.TrailingComment; c != nil && !stmt.IsBlock() && c.End > end {
+		end = c.End
+	}
+	if stmt.IsBlock() && stmt.Block.CloseComment != nil {
+		end = stmt.Block.CloseComment.End
+	}
+	lineStart := strings.LastIndexByte(src[:start], '\n') + 1
+	lineEnd := len(src)
+	if i := strings.IndexByte(src[end:], '\n'); i >= 0 {
+		lineEnd = end + i
+	}
+	if strings.TrimSpace(src[lineStart:start]) == "" && strings.TrimSpace(src[end:lineEnd]) == "" {
+		if lineEnd < len(src) {
+			lineEnd++
+		}
+		// Drop the blank line that separated the statement from what came
+		// before when nothing but a closing brace or another blank line follows.
+		rest := strings.TrimLeft(src[lineEnd:], " \t")
+		if prev := strings.TrimRight(src[:lineStart], " \t"); strings.HasSuffix(prev, "\n\n") &&
+			(rest == "" || rest[0] == '}' || rest[0] == '\n') {
+			lineStart = len(prev) - 1
+		}
+		return textEdit{start: lineStart, end: lineEnd}
+	}
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return textEdit{start: start, end: end}
+}
+
+// indentUnit guesses the file's indentation step: a tab when lines are
+// tab-indented, otherwise the smallest space indent, defaulting to two.
+func indentUnit(src string) string {
+	smallest := 0
+	for _, line := range strings.Split(src, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			return "\t"
+		}
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if n > 0 && (smallest == 0 || n < smallest) {
+			smallest = n
+		}
+	}
+	if smallest == 0 {
+		smallest = 2
+	}
+	return strings.Repeat(" ", smallest)
+}
+
+// apply applies non-overlapping edits.
+func apply(src string, edits []textEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		src = src[:e.start] + e.text + src[e.end:]
+	}
+	return src
+}
@@ -0,0 +1,154 @@
+package edit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+const sample = `# provider under test
+provider "openai" {
+    defaults {
+        auth {
+            oauth_content_type json; # legacy
+        }
+        request { set_header X-Trace "on"; }
+    }
+}
+`
+
+func TestSetReplacesValueKeepingComments(t *testing.T) {
+	t.Parallel()
+
+	got, err := Set(sample, "provider[openai].defaults.auth.oauth_content_type", []string{"form"})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	want := strings.Replace(sample, "oauth_content_type json;", "oauth_content_type form;", 1)
+	if got != want {
+		t.Fatalf("unexpected result:\n%s", got)
+	}
+}
+
+func TestSetCreatesMissingBlocks(t *testing.T) {
+	t.Parallel()
+
+	got, err := Set(sample, `provider[openai].match[api="chat.completions"].upstream.set_path`, []string{"/v1/chat/completions"})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	want := strings.Replace(sample, "    }\n}\n", `    }
+    match api = "chat.completions" {
+        upstream {
+            set_path "/v1/chat/completions";
+        }
+    }
+}
+`, 1)
+	if got != want {
+		t.Fatalf("unexpected result:\n%s", got)
+	}
+
+	got, err = Set("", "provider[p].defaults.metrics.usage_extract", []string{"openai"})
+	if err != nil {
+		t.Fatalf("Set on empty file: %v", err)
+	}
+	if want := "provider \"p\" {\n  defaults {\n    metrics {\n      usage_extract openai;\n    }\n  }\n}\n"; got != want {
+		t.Fatalf("unexpected result:\n%s", got)
+	}
+}
+
+func TestSetKeyedAndListDirectives(t *testing.T) {
+	t.Parallel()
+
+	got, err := Set(sample, "provider.defaults.request.set_header", []string{"x-trace", `"off"`})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !strings.Contains(got, `request { set_header x-trace "off"; }`) {
+		t.Fatalf("expected keyed directive to be replaced:\n%s", got)
+	}
+
+	got, err = Set(sample, "provider.defaults.request.json_set", []string{"$.Model", `"a"`})
+	if err == nil {
+		got, err = Set(got, "provider.defaults.request.json_set", []string{"$.model", `"b"`})
+	}
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !strings.Contains(got, `json_set "$.Model" "a"; json_set "$.model" "b"; }`) {
+		t.Fatalf("expected JSON paths differing in case to be separate keys:\n%s", got)
+	}
+
+	got, err = Set(sample, "provider.defaults.request.del_header", []string{"X-Internal"})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !strings.Contains(got, `request { set_header X-Trace "on"; del_header X-Internal; }`) {
+		t.Fatalf("expected list directive to be added:\n%s", got)
+	}
+	again, err := Set(got, "provider.defaults.request.del_header", []string{"X-Internal"})
+	if err != nil || again != got {
+		t.Fatalf("expected repeated list directive to be a no-op: %v\n%s", err, again)
+	}
+}
+
+func TestSetValidatesAgainstSpec(t *testing.T) {
+	t.Parallel()
+
+	for path, want := range map[string]string{
+		"provider.defaults.auth.req_map":                  "req_map is not allowed in auth",
+		"provider.defaults.balance.balance_unit":          `invalid balance_unit value "EUR"`,
+		"provider.defaults.nope.balance_unit":             "nope is not a block allowed in defaults",
+		"provider.match[api=\"chat*\"].upstream.set_path": "does not pin one value",
+		"provider.defaults.auth":                          "auth is a block",
+	} {
+		if _, err := Set(sample, path, []string{"EUR"}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected %q, got %v", path, want, err)
+		}
+	}
+}
+
+func TestUnsetRemovesStatements(t *testing.T) {
+	t.Parallel()
+
+	src := "provider \"p\" {\n  defaults {\n    request {\n      req_map openai_chat; # old\n      del_header X-A;\n    }\n  }\n\n  match api = \"x\" {\n  }\n}\n"
+	got, err := Unset(src, "provider.defaults.request.req_map")
+	if err != nil {
+		t.Fatalf("Unset: %v", err)
+	}
+	if strings.Contains(got, "req_map") || strings.Contains(got, "# old") || !strings.Contains(got, "      del_header X-A;\n") {
+		t.Fatalf("unexpected result:\n%s", got)
+	}
+
+	got, err = Unset(src, "provider.match")
+	if err != nil {
+		t.Fatalf("Unset: %v", err)
+	}
+	if want := "provider \"p\" {\n  defaults {\n    request {\n      req_map openai_chat; # old\n      del_header X-A;\n    }\n  }\n}\n"; got != want {
+		t.Fatalf("unexpected result:\n%q", got)
+	}
+
+	if _, err := Unset(src, "provider.models"); err == nil {
+		t.Fatalf("expected error when nothing matches")
+	}
+}
+
+func TestNewDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	before := "provider \"p\" { defaults { request { req_map openai_chat; } } }\n"
+	after := "provider \"p\" { defaults { request { req_map bogus; } } }\n"
+	if diags := NewDiagnostics("untitled:p.conf", before, before, lint.Config{}); len(diags) != 0 {
+		t.Fatalf("expected no new diagnostics, got %+v", diags)
+	}
+	diags := NewDiagnostics("untitled:p.conf", before, after, lint.Config{})
+	found := false
+	for _, d := range diags {
+		found = found || d.Code == "unsupported-mode"
+	}
+	if !found {
+		t.Fatalf("expected unsupported-mode, got %+v", diags)
+	}
+}
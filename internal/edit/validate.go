@@ -0,0 +1,36 @@
+package edit
+
+import (
+	"regexp"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// NewDiagnostics returns the diagnostics of after that before does not have.
+// Diagnostics are compared by rule and message so that unrelated problems
+// moving to other lines are not reported.
+func NewDiagnostics(uri, before, after string, cfg lint.Config) []lint.Diagnostic {
+	seen := map[string]int{}
+	for _, d := range lint.Apply(before, dsllang.CollectDiagnostics(uri, before), cfg) {
+		seen[diagnosticKey(d)]++
+	}
+	var out []lint.Diagnostic
+	for _, d := range lint.Apply(after, dsllang.CollectDiagnostics(uri, after), cfg) {
+		key := diagnosticKey(d)
+		if seen[key] > 0 {
+			seen[key]--
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// semanticFile matches the file part of semantic errors, which names a
+// temporary copy that differs between runs.
+var semanticFile = regexp.MustCompile(` in "[^"]*":`)
+
+func diagnosticKey(d lint.Diagnostic) string {
+	return d.Code + "\x00" + semanticFile.ReplaceAllString(d.Message, ":")
+}
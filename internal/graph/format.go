@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var formats = []string{"dot", "mermaid", "json"}
+
+// Formats returns the supported output formats.
+func Formats() []string {
+	return append([]string(nil), formats...)
+}
+
+// Supported reports whether format is a known output format.
+func Supported(format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Write encodes g in format.
+func Write(w io.Writer, format string, g Graph) error {
+	switch format {
+	case "dot":
+		return writeDOT(w, g)
+	case "mermaid":
+		return writeMermaid(w, g)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(g)
+	default:
+		return fmt.Errorf("unsupported graph format %q (want %s)", format, strings.Join(formats, ", "))
+	}
+}
+
+// nodeLabel adds the usage count to presets so over-shared ones stand out.
+func nodeLabel(n Node) string {
+	switch {
+	case n.Undefined:
+		return n.Label + " (undefined)"
+	case n.Kind != KindFile && n.Kind != KindProvider:
+		return fmt.Sprintf("%s (used by %d)", n.Label, n.UsedBy)
+	default:
+		return n.Label
+	}
+}
+
+func writeDOT(w io.Writer, g Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph onr {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		attrs := []string{"label=" + dotQuote(nodeLabel(n))}
+		switch n.Kind {
+		case KindFile:
+			attrs = append(attrs, "shape=note")
+		case KindProvider:
+			attrs = append(attrs, "shape=box")
+		default:
+			attrs = append(attrs, "shape=ellipse")
+		}
+		if n.Undefined {
+			attrs = append(attrs, "style=dashed", "color=red")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.ID), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		attr := ""
+		switch e.Kind {
+		case EdgeDeclares:
+			attr = " [style=dotted, arrowhead=none]"
+		case EdgeUses:
+			attr = " [color=blue]"
+		}
+		fmt.Fprintf(&b, "  %s -> %s%s;\n", dotQuote(e.From), dotQuote(e.To), attr)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func writeMermaid(w io.Writer, g Graph) error {
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.ID] = id
+		label := mermaidEscape(nodeLabel(n))
+		switch n.Kind {
+		case KindFile:
+			fmt.Fprintf(&b, "  %s[/\"%s\"/]\n", id, label)
+		case KindProvider:
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", id, label)
+		default:
+			fmt.Fprintf(&b, "  %s([\"%s\"])\n", id, label)
+		}
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		switch e.Kind {
+		case EdgeDeclares:
+			arrow = "-.-"
+		case EdgeUses:
+			arrow = "==>"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+	}
+	for _, n := range g.Nodes {
+		if n.Undefined {
+			fmt.Fprintf(&b, "  style %s stroke:#d00,stroke-dasharray:4\n", ids[n.ID])
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
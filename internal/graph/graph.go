@@ -0,0 +1,176 @@
+// Package graph builds the include graph and the provider → preset usage
+// graph of a workspace.
+package graph
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+)
+
+// Node kinds besides preset registry kinds such as "usage_mode".
+const (
+	KindFile     = "file"
+	KindProvider = "provider"
+)
+
+// Edge kinds.
+const (
+	EdgeInclude  = "include"
+	EdgeDeclares = "declares"
+	EdgeUses     = "uses"
+)
+
+// Node is a file, provider or preset.
+type Node struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	Path  string `json:"path,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	// UsedBy counts the providers and presets that use a preset.
+	UsedBy int `json:"usedBy,omitempty"`
+	// Undefined marks presets that are referenced but never declared.
+	Undefined bool `json:"undefined,omitempty"`
+}
+
+// Edge connects two node IDs.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph is a set of nodes and edges, both in a stable order.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Build returns the graph of docs. Paths are shown relative to base.
+func Build(ws *workspace.Workspace, docs []*workspace.Document, base string) Graph {
+	b := &builder{nodes: map[string]*Node{}, edges: map[Edge]bool{}, base: base}
+	inScope := map[string]bool{}
+	for _, doc := range docs {
+		inScope[doc.Path] = true
+	}
+	for _, doc := range docs {
+		file := b.file(doc.Path)
+		for _, inc := range ws.Includes(doc) {
+			for _, target := range inc.Targets {
+				if inScope[target] {
+					b.edge(file, b.file(target), EdgeInclude)
+				}
+			}
+		}
+		for _, decl := range workspace.Declarations(doc) {
+			id := b.symbol(decl.Kind, decl.Name)
+			n := b.nodes[id]
+			n.Path, n.Line, n.Undefined = b.rel(doc.Path), decl.Arg.Range.Start.Line+1, false
+			b.edge(file, id, EdgeDeclares)
+		}
+	}
+	for _, doc := range docs {
+		for _, ref := range workspace.PresetReferences(doc) {
+			if ref.Owner == nil || workspace.IsBuiltinMode(ref) {
+				continue
+			}
+			from := b.symbol(ref.Owner.Name, ref.Owner.FirstArg())
+			to := b.symbol(ref.Kind, ref.Name)
+			if !b.edges[Edge{From: from, To: to, Kind: EdgeUses}] {
+				b.nodes[to].UsedBy++
+			}
+			b.edge(from, to, EdgeUses)
+		}
+	}
+	return b.graph()
+}
+
+type builder struct {
+	nodes map[string]*Node
+	edges map[Edge]bool
+	base  string
+}
+
+func (b *builder) rel(path string) string {
+	rel, err := filepath.Rel(b.base, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (b *builder) file(path string) string {
+	rel := b.rel(path)
+	id := KindFile + ":" + rel
+	if _, ok := b.nodes[id]; !ok {
+		b.nodes[id] = &Node{ID: id, Kind: KindFile, Label: rel, Path: rel}
+	}
+	return id
+}
+
+// symbol returns the node for a provider or preset, creating an undefined
+// placeholder until its declaration is seen.
+func (b *builder) symbol(kind, name string) string {
+	id := kind + ":" + name
+	if _, ok := b.nodes[id]; !ok {
+		b.nodes[id] = &Node{ID: id, Kind: kind, Label: fmt.Sprintf("%s %q", kind, name), Undefined: true}
+	}
+	return id
+}
+
+func (b *builder) edge(from, to, kind string) {
+	b.edges[Edge{From: from, To: to, Kind: kind}] = true
+}
+
+func (b *builder) graph() Graph {
+	g := Graph{Nodes: make([]Node, 0, len(b.nodes)), Edges: make([]Edge, 0, len(b.edges))}
+	for _, n := range b.nodes {
+		g.Nodes = append(g.Nodes, *n)
+	}
+	for e := range b.edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		if kindOrder(g.Nodes[i].Kind) != kindOrder(g.Nodes[j].Kind) {
+			return kindOrder(g.Nodes[i].Kind) < kindOrder(g.Nodes[j].Kind)
+		}
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, c := g.Edges[i], g.Edges[j]
+		if edgeOrder(a.Kind) != edgeOrder(c.Kind) {
+			return edgeOrder(a.Kind) < edgeOrder(c.Kind)
+		}
+		if a.From != c.From {
+			return a.From < c.From
+		}
+		return a.To < c.To
+	})
+	return g
+}
+
+// kindOrder lists files first, then providers, then presets.
+func kindOrder(kind string) int {
+	switch kind {
+	case KindFile:
+		return 0
+	case KindProvider:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func edgeOrder(kind string) int {
+	switch kind {
+	case EdgeInclude:
+		return 0
+	case EdgeDeclares:
+		return 1
+	default:
+		return 2
+	}
+}
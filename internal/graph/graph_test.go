@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+)
+
+func buildTestGraph(t *testing.T) Graph {
+	t.Helper()
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":         "include modes/*.conf;\ninclude providers;\n",
+		"modes/usage.conf": "usage_mode \"base\" {\n  usage_extract custom;\n}\nusage_mode \"child\" {\n  usage_extract base;\n}\n",
+		"providers/a.conf": "provider \"a\" {\n  defaults {\n    metrics { usage_extract child; finish_reason_extract gone; }\n  }\n}\n",
+		"providers/b.conf": "provider \"b\" {\n  defaults {\n    metrics { usage_extract child; }\n    request { req_map openai_chat_to_openai_responses; }\n  }\n}\n",
+	})
+	ws := workspace.New(nil)
+	rootPath := filepath.Join(root, "onr.conf")
+	ws.Add(rootPath)
+	doc, _ := ws.Document(rootPath)
+	return Build(ws, ws.Reachable(doc), root)
+}
+
+func TestBuildIncludeAndUsageEdges(t *testing.T) {
+	t.Parallel()
+
+	g := buildTestGraph(t)
+	nodes := map[string]Node{}
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	if n := nodes["usage_mode:child"]; n.UsedBy != 2 || n.Path != "modes/usage.conf" || n.Line != 4 {
+		t.Fatalf("unexpected child preset node: %+v", n)
+	}
+	if n := nodes["usage_mode:base"]; n.UsedBy != 1 {
+		t.Fatalf("expected base preset used by child preset: %+v", n)
+	}
+	if n := nodes["finish_reason_mode:gone"]; !n.Undefined {
+		t.Fatalf("expected undefined preset node: %+v", n)
+	}
+	if _, ok := nodes["req_map:openai_chat_to_openai_responses"]; ok {
+		t.Fatalf("built-in modes must not become nodes")
+	}
+	edges := map[Edge]bool{}
+	for _, e := range g.Edges {
+		edges[e] = true
+	}
+	for _, want := range []Edge{
+		{From: "file:onr.conf", To: "file:providers/a.conf", Kind: EdgeInclude},
+		{From: "file:providers/a.conf", To: "provider:a", Kind: EdgeDeclares},
+		{From: "provider:b", To: "usage_mode:child", Kind: EdgeUses},
+		{From: "usage_mode:child", To: "usage_mode:base", Kind: EdgeUses},
+	} {
+		if !edges[want] {
+			t.Fatalf("missing edge %+v in %+v", want, g.Edges)
+		}
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	t.Parallel()
+
+	g := Graph{
+		Nodes: []Node{
+			{ID: "file:onr.conf", Kind: KindFile, Label: "onr.conf"},
+			{ID: "provider:a", Kind: KindProvider, Label: `provider "a"`},
+			{ID: "usage_mode:u", Kind: "usage_mode", Label: `usage_mode "u"`, Undefined: true},
+		},
+		Edges: []Edge{
+			{From: "file:onr.conf", To: "provider:a", Kind: EdgeDeclares},
+			{From: "provider:a", To: "usage_mode:u", Kind: EdgeUses},
+		},
+	}
+	cases := map[string]string{
+		"dot": `digraph onr {
+  rankdir=LR;
+  node [fontname="Helvetica"];
+  "file:onr.conf" [label="onr.conf", shape=note];
+  "provider:a" [label="provider \"a\"", shape=box];
+  "usage_mode:u" [label="usage_mode \"u\" (undefined)", shape=ellipse, style=dashed, color=red];
+  "file:onr.conf" -> "provider:a" [style=dotted, arrowhead=none];
+  "provider:a" -> "usage_mode:u" [color=blue];
+}
+`,
+		"mermaid": `flowchart LR
+  n0[/"onr.conf"/]
+  n1["provider #quot;a#quot;"]
+  n2(["usage_mode #quot;u#quot; (undefined)"])
+  n0 -.- n1
+  n1 ==> n2
+  style n2 stroke:#d00,stroke-dasharray:4
+`,
+	}
+	for format, want := range cases {
+		var out bytes.Buffer
+		if err := Write(&out, format, g); err != nil {
+			t.Fatalf("Write %s: %v", format, err)
+		}
+		if out.String() != want {
+			t.Fatalf("unexpected %s output:\n%s", format, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, "json", g); err != nil {
+		t.Fatalf("Write json: %v", err)
+	}
+	var decoded Graph
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded.Nodes) != 3 || len(decoded.Edges) != 2 {
+		t.Fatalf("unexpected json output: %v\n%s", err, out.String())
+	}
+	if err := Write(&out, "svg", g); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
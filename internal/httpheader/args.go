@@ -0,0 +1,89 @@
+package httpheader
+
+import (
+	"errors"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// directives maps directives that take a header name to its argument index.
+var directives = map[string]int{
+	"set_header":             0,
+	"del_header":             0,
+	"pass_header":            0,
+	"filter_header_values":   0,
+	"auth_header_key":        0,
+	"json_set_header_values": 1,
+}
+
+// ArgIndex returns the index of the header-name argument of directive.
+func ArgIndex(directive string) (int, bool) {
+	i, ok := directives[directive]
+	return i, ok
+}
+
+// Arg is one header-name directive argument.
+type Arg struct {
+	Stmt *dslast.Statement
+	Arg  *dslast.Arg
+	// Name is the header name with quotes removed.
+	Name string
+}
+
+// Range returns the source range of Name[start:end].
+func (a *Arg) Range(start, end int) dsllang.Range {
+	col := a.Arg.Range.Start.Character
+	if a.Arg.Quoted {
+		col++
+	}
+	line := a.Arg.Range.Start.Line
+	return dsllang.Range{
+		Start: dsllang.Position{Line: line, Character: col + start},
+		End:   dsllang.Position{Line: line, Character: col + end},
+	}
+}
+
+// Args returns the header-name arguments of f in source order.
+func Args(f *dslast.File) []*Arg {
+	var out []*Arg
+	dslast.Walk(f, func(stmt *dslast.Statement, _ []*dslast.Statement) bool {
+		i, ok := directives[stmt.Name]
+		if !ok || stmt.IsBlock() || i >= len(stmt.Args) {
+			return true
+		}
+		arg := stmt.Args[i]
+		if !arg.Quoted && len(arg.Raw) > 0 && (arg.Raw[0] == '"' || arg.Raw[0] == '\'') {
+			// Unterminated quotes are syntax errors of their own.
+			return true
+		}
+		out = append(out, &Arg{Stmt: stmt, Arg: arg, Name: arg.Value()})
+		return true
+	})
+	return out
+}
+
+// Diagnostics reports header-name arguments of text that are not valid HTTP
+// field names, at the offending character. Severities are left to
+// lint.Filter.
+func Diagnostics(text string) []lint.Diagnostic {
+	var out []lint.Diagnostic
+	for _, a := range Args(dslast.Parse(text)) {
+		var herr *Error
+		if !errors.As(Check(a.Name), &herr) {
+			continue
+		}
+		r := a.Range(herr.Offset, herr.Offset+1)
+		if a.Name == "" {
+			r = a.Arg.Range
+		}
+		out = append(out, lint.Diagnostic{
+			Range:   r,
+			Code:    lint.RuleInvalidHeaderName,
+			Source:  "onr-lsp",
+			Message: "invalid header name " + a.Arg.Raw + ": " + herr.Message,
+		})
+	}
+	return out
+}
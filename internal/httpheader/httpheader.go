@@ -0,0 +1,117 @@
+// Package httpheader checks header-name directive arguments and documents
+// the standard and LLM-vendor request headers providers commonly set.
+package httpheader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Header is a well-known request header.
+type Header struct {
+	// Name is the canonical spelling used in vendor documentation.
+	Name string
+	// Vendor is the API vendor that defines the header, or "" for standard
+	// HTTP headers.
+	Vendor string
+	Doc    string
+}
+
+var known = []Header{
+	{Name: "Accept", Doc: "Media types the client accepts, such as `application/json` or `text/event-stream` for streaming."},
+	{Name: "Accept-Encoding", Doc: "Content encodings the client accepts, such as `gzip`."},
+	{Name: "Accept-Language", Doc: "Preferred natural languages of the response."},
+	{Name: "Authorization", Doc: "Credentials for the upstream, usually `Bearer <key>`. `auth_bearer` sets it from the channel key."},
+	{Name: "Cache-Control", Doc: "Caching directives for the request."},
+	{Name: "Connection", Doc: "Connection options. Hop-by-hop; proxies do not forward it."},
+	{Name: "Content-Encoding", Doc: "Encoding applied to the request body."},
+	{Name: "Content-Length", Doc: "Size of the request body in bytes. Set by the HTTP client; rewriting it breaks the request."},
+	{Name: "Content-Type", Doc: "Media type of the request body, usually `application/json`."},
+	{Name: "Cookie", Doc: "Cookies sent by the client."},
+	{Name: "Host", Doc: "Upstream host. Derived from `base_url`."},
+	{Name: "Idempotency-Key", Doc: "Client key that makes retried requests safe to replay."},
+	{Name: "Origin", Doc: "Origin of a browser request."},
+	{Name: "Referer", Doc: "Page that issued a browser request."},
+	{Name: "User-Agent", Doc: "Client software identification."},
+	{Name: "X-Forwarded-For", Doc: "Client address chain added by proxies."},
+	{Name: "X-Forwarded-Host", Doc: "Original host requested by the client."},
+	{Name: "X-Forwarded-Proto", Doc: "Original protocol used by the client."},
+	{Name: "X-Request-Id", Doc: "Request id for correlating logs across services."},
+
+	{Name: "OpenAI-Beta", Vendor: "OpenAI", Doc: "Opts in to beta APIs, such as `assistants=v2`."},
+	{Name: "OpenAI-Organization", Vendor: "OpenAI", Doc: "Organization id the request is billed to."},
+	{Name: "OpenAI-Project", Vendor: "OpenAI", Doc: "Project id the request is billed to."},
+	{Name: "api-key", Vendor: "Azure OpenAI", Doc: "Azure OpenAI resource key, used instead of `Authorization`."},
+
+	{Name: "x-api-key", Vendor: "Anthropic", Doc: "Anthropic API key. Set it with `auth_header_key x-api-key;`."},
+	{Name: "anthropic-version", Vendor: "Anthropic", Doc: "Required Messages API version, such as `2023-06-01`."},
+	{Name: "anthropic-beta", Vendor: "Anthropic", Doc: "Comma-separated beta features, such as `prompt-caching-2024-07-31`."},
+	{Name: "anthropic-dangerous-direct-browser-access", Vendor: "Anthropic", Doc: "Allows CORS requests from browsers when set to `true`."},
+
+	{Name: "x-goog-api-key", Vendor: "Google", Doc: "Gemini API key, used instead of the `key` query parameter."},
+	{Name: "x-goog-user-project", Vendor: "Google", Doc: "Google Cloud project billed for the request."},
+	{Name: "x-goog-api-client", Vendor: "Google", Doc: "Client library identification."},
+
+	{Name: "HTTP-Referer", Vendor: "OpenRouter", Doc: "Site URL used for OpenRouter app attribution."},
+	{Name: "X-Title", Vendor: "OpenRouter", Doc: "Site name used for OpenRouter app attribution."},
+}
+
+// Known returns the well-known headers sorted by name, case-insensitively.
+func Known() []Header {
+	out := append([]Header(nil), known...)
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name) })
+	return out
+}
+
+// Lookup returns the well-known header named name, ignoring case as HTTP
+// does.
+func Lookup(name string) (Header, bool) {
+	for _, h := range known {
+		if strings.EqualFold(h.Name, name) {
+			return h, true
+		}
+	}
+	return Header{}, false
+}
+
+// Markdown renders hover documentation for h.
+func (h Header) Markdown() string {
+	title := "`" + h.Name + "` header"
+	if h.Vendor != "" {
+		title += " (" + h.Vendor + ")"
+	}
+	return title + "\n\n" + h.Doc
+}
+
+// Error is an invalid header name, with the byte offset of the problem.
+type Error struct {
+	Offset  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Message)
+}
+
+// Check reports whether name is an HTTP field name: one or more token
+// characters as defined by RFC 9110.
+func Check(name string) error {
+	if name == "" {
+		return &Error{Message: "empty header name"}
+	}
+	for i := 0; i < len(name); i++ {
+		if !isTokenChar(name[i]) {
+			return &Error{Offset: i, Message: fmt.Sprintf("header names cannot contain %q", name[i])}
+		}
+	}
+	return nil
+}
+
+func isTokenChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", b) >= 0
+}
@@ -0,0 +1,69 @@
+package httpheader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"Authorization", "anthropic-version", "x-goog-api-key", "X_Custom.1", "!#$%&'*+-.^_`|~"} {
+		if err := Check(name); err != nil {
+			t.Errorf("%q: unexpected error: %v", name, err)
+		}
+	}
+	for name, offset := range map[string]int{"": 0, "X A": 1, "X-A:": 3, "Bad@": 3, "é": 0} {
+		err, ok := Check(name).(*Error)
+		if !ok || err.Offset != offset {
+			t.Errorf("%q: got %v, want error at %d", name, err, offset)
+		}
+	}
+}
+
+func TestLookupIgnoresCase(t *testing.T) {
+	t.Parallel()
+
+	h, ok := Lookup("OPENAI-BETA")
+	if !ok || h.Name != "OpenAI-Beta" || h.Vendor != "OpenAI" {
+		t.Fatalf("Lookup = %+v, %v", h, ok)
+	}
+	if _, ok := Lookup("X-Unknown"); ok {
+		t.Fatalf("unexpected well-known header")
+	}
+	seen := map[string]bool{}
+	for _, h := range Known() {
+		key := strings.ToLower(h.Name)
+		if seen[key] || Check(h.Name) != nil || h.Doc == "" {
+			t.Fatalf("bad known header %+v", h)
+		}
+		seen[key] = true
+	}
+}
+
+func TestDiagnosticsPointAtTheOffendingCharacter(t *testing.T) {
+	t.Parallel()
+
+	text := "provider \"x\" {\n  defaults {\n    request {\n" +
+		"      set_header \"X A\" \"1\";\n" +
+		"      del_header X-Ok;\n" +
+		"      json_set_header_values \"$.a\" \"X:Y\";\n" +
+		"      pass_header \"\";\n" +
+		"    }\n  }\n}\n"
+	diags := Diagnostics(text)
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %+v", diags)
+	}
+	for i, want := range []struct{ line, start, end int }{
+		{3, len(`      set_header "X`), len(`      set_header "X `)},
+		{5, len(`      json_set_header_values "$.a" "X`), len(`      json_set_header_values "$.a" "X:`)},
+		{6, len(`      pass_header `), len(`      pass_header ""`)},
+	} {
+		d := diags[i]
+		if d.Code != lint.RuleInvalidHeaderName || d.Range.Start.Line != want.line || d.Range.Start.Character != want.start || d.Range.End.Character != want.end {
+			t.Errorf("diagnostic %d = %+v, want %+v", i, d, want)
+		}
+	}
+}
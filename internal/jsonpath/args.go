@@ -0,0 +1,292 @@
+package jsonpath
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// responseDirectives take one JSONPath into the upstream response.
+var responseDirectives = map[string]bool{
+	"input_tokens_path":       true,
+	"output_tokens_path":      true,
+	"cache_read_tokens_path":  true,
+	"cache_write_tokens_path": true,
+	"finish_reason_path":      true,
+}
+
+// endpointDirectives take one JSONPath into the response of a models,
+// balance or OAuth endpoint.
+var endpointDirectives = map[string]bool{
+	"id_path":               true,
+	"balance_path":          true,
+	"used_path":             true,
+	"oauth_token_path":      true,
+	"oauth_expires_in_path": true,
+	"oauth_token_type_path": true,
+}
+
+// objectDirectives take object paths as their leading arguments, by count.
+var objectDirectives = map[string]int{
+	"json_set":            1,
+	"json_set_if_absent":  1,
+	"json_replace":        1,
+	"json_del":            1,
+	"json_rename":         2,
+	"json_del_if_missing": 2,
+}
+
+// keyDirectives take JSONPaths as key=value arguments.
+var keyDirectives = map[string][]string{
+	"usage_root": {"path"},
+	"usage_fact": {"path", "count_path", "sum_path"},
+}
+
+// Arg is one path-typed directive argument.
+type Arg struct {
+	Stmt *dslast.Statement
+	// Parents are the enclosing block statements, outermost first.
+	Parents []*dslast.Statement
+	// Value is the path with quotes and escapes removed.
+	Value string
+	// Object reports whether the path is checked as ParseObject does.
+	Object bool
+	// Response reports whether the path reads the upstream API response,
+	// so the built-in shapes apply.
+	Response bool
+
+	line int
+	// cols holds the column of each byte of Value, plus the column after it.
+	cols []int
+}
+
+// Check parses the argument as its directive expects.
+func (a *Arg) Check() error {
+	if a.Object {
+		return ParseObject(a.Value)
+	}
+	return Parse(a.Value)
+}
+
+// Range returns the source range of Value[start:end].
+func (a *Arg) Range(start, end int) dsllang.Range {
+	start = min(max(start, 0), len(a.Value))
+	end = min(max(end, start), len(a.Value))
+	return dsllang.Range{
+		Start: dsllang.Position{Line: a.line, Character: a.cols[start]},
+		End:   dsllang.Position{Line: a.line, Character: a.cols[end]},
+	}
+}
+
+// IsPathDirective reports whether name takes JSONPath arguments.
+func IsPathDirective(name string) bool {
+	return responseDirectives[name] || endpointDirectives[name] || objectDirectives[name] > 0 || keyDirectives[name] != nil
+}
+
+// IsResponseDirective reports whether name reads paths from the upstream
+// API response.
+func IsResponseDirective(name string) bool {
+	return responseDirectives[name] || keyDirectives[name] != nil
+}
+
+// PathKeys returns the keys of the key=value arguments of name that take
+// JSONPaths, or nil when its paths are positional.
+func PathKeys(name string) []string {
+	return keyDirectives[name]
+}
+
+// Args returns the path-typed arguments of f in source order.
+func Args(f *dslast.File) []*Arg {
+	var out []*Arg
+	dslast.Walk(f, func(stmt *dslast.Statement, parents []*dslast.Statement) bool {
+		if stmt.IsBlock() {
+			return true
+		}
+		add := func(raw *dslast.Arg, skip int, object bool) {
+			if a, ok := newArg(raw, skip); ok {
+				a.Stmt, a.Parents, a.Object = stmt, parents, object
+				a.Response = IsResponseDirective(stmt.Name)
+				out = append(out, a)
+			}
+		}
+		switch {
+		case responseDirectives[stmt.Name] || endpointDirectives[stmt.Name]:
+			if len(stmt.Args) > 0 {
+				add(stmt.Args[0], 0, false)
+			}
+		case objectDirectives[stmt.Name] > 0:
+			for _, raw := range stmt.Args[:min(len(stmt.Args), objectDirectives[stmt.Name])] {
+				add(raw, 0, true)
+			}
+		case keyDirectives[stmt.Name] != nil:
+			args := stmt.Args
+			for i, raw := range args {
+				for _, key := range keyDirectives[stmt.Name] {
+					switch {
+					case strings.HasPrefix(raw.Raw, key+"=") && len(raw.Raw) > len(key)+1:
+						add(raw, len(key)+1, false)
+					case raw.Raw == key+"=" && i+1 < len(args):
+						add(args[i+1], 0, false)
+					case raw.Raw == key && i+2 < len(args) && args[i+1].Raw == "=":
+						add(args[i+2], 0, false)
+					}
+				}
+			}
+		}
+		return true
+	})
+	return out
+}
+
+// newArg reads the path in raw.Raw[skip:]. ok is false for unterminated
+// quotes, which are syntax errors of their own.
+func newArg(raw *dslast.Arg, skip int) (*Arg, bool) {
+	src := raw.Raw[skip:]
+	col := raw.Range.Start.Character + skip
+	a := &Arg{line: raw.Range.Start.Line}
+	if src == "" || (src[0] != '"' && src[0] != '\'') {
+		for i := 0; i <= len(src); i++ {
+			a.cols = append(a.cols, col+i)
+		}
+		a.Value = src
+		return a, true
+	}
+	q := src[0]
+	if len(src) < 2 || src[len(src)-1] != q {
+		return nil, false
+	}
+	var b strings.Builder
+	for i := 1; i < len(src)-1; i++ {
+		if src[i] == '\\' && i+1 < len(src)-1 && (src[i+1] == q || src[i+1] == '\\') {
+			i++
+		}
+		b.WriteByte(src[i])
+		a.cols = append(a.cols, col+i)
+	}
+	a.cols = append(a.cols, col+len(src)-1)
+	a.Value = b.String()
+	return a, true
+}
+
+// Diagnostics reports path-typed arguments of text that onr-core cannot
+// parse, at the offending character. Severities are left to lint.Filter.
+func Diagnostics(text string) []lint.Diagnostic {
+	var out []lint.Diagnostic
+	for _, a := range Args(dslast.Parse(text)) {
+		var perr *Error
+		if !errors.As(a.Check(), &perr) {
+			continue
+		}
+		out = append(out, lint.Diagnostic{
+			Range:   a.Range(perr.Offset, perr.Offset+1),
+			Code:    lint.RuleInvalidJSONPath,
+			Source:  "onr-lsp",
+			Message: "invalid JSONPath " + quote(a.Value) + ": " + perr.Message,
+		})
+	}
+	return out
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// Families returns the API families whose responses a path in a statement
+// with the given parents reads: those named by resp_map and sse_parse modes
+// of the enclosing match, or of every match when the statement is in the
+// provider defaults, falling back to the match api when the response is
+// not mapped. It is empty when the families are unknown, such as in
+// usage_mode presets.
+func Families(parents []*dslast.Statement) []string {
+	var provider, match *dslast.Statement
+	for _, p := range parents {
+		switch p.Name {
+		case "provider":
+			provider = p
+		case "match":
+			match = p
+		}
+	}
+	if provider == nil {
+		return nil
+	}
+	var defaults *dslast.Statement
+	var matches []*dslast.Statement
+	for _, child := range provider.Children() {
+		switch {
+		case child.Name == "defaults" && child.IsBlock():
+			defaults = child
+		case child.Name == "match" && child.IsBlock():
+			matches = append(matches, child)
+		}
+	}
+	if match != nil {
+		matches = []*dslast.Statement{match}
+	}
+	var out []string
+	addAll := func(families []string) {
+		for _, f := range families {
+			if !slices.Contains(out, f) {
+				out = append(out, f)
+			}
+		}
+	}
+	fallback := responseFamilies(defaults)
+	if len(matches) == 0 {
+		addAll(fallback)
+	}
+	for _, m := range matches {
+		families := responseFamilies(m)
+		if len(families) == 0 {
+			families = fallback
+		}
+		if len(families) == 0 {
+			if f, ok := FamilyOfAPI(matchAPI(m)); ok {
+				families = []string{f}
+			}
+		}
+		addAll(families)
+	}
+	return out
+}
+
+// responseFamilies returns the families of the resp_map and sse_parse modes
+// in the response block of scope.
+func responseFamilies(scope *dslast.Statement) []string {
+	if scope == nil {
+		return nil
+	}
+	var out []string
+	for _, child := range scope.Children() {
+		if child.Name != "response" {
+			continue
+		}
+		for _, stmt := range child.Children() {
+			if stmt.Name != "resp_map" && stmt.Name != "sse_parse" {
+				continue
+			}
+			if f, ok := FamilyOfMode(stmt.FirstArg()); ok && !slices.Contains(out, f) {
+				out = append(out, f)
+			}
+		}
+	}
+	return out
+}
+
+// matchAPI returns the api condition of a match statement.
+func matchAPI(match *dslast.Statement) string {
+	args := match.Args
+	for i, a := range args {
+		switch {
+		case strings.HasPrefix(a.Raw, "api="):
+			return dslast.Unquote(a.Raw[len("api="):])
+		case a.Raw == "api" && i+2 < len(args) && args[i+1].Raw == "=":
+			return args[i+2].Value()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,190 @@
+// Package jsonpath checks the JSONPath subset onr-core evaluates in
+// path-typed directive arguments and completes paths from the response
+// shapes of well-known API families.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Error is a syntax error at a byte offset of the path.
+type Error struct {
+	Offset  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Message)
+}
+
+func errorAt(offset int, format string, args ...any) *Error {
+	return &Error{Offset: offset, Message: fmt.Sprintf(format, args...)}
+}
+
+// Parse checks a path as onr-core extracts values with it: `$.` followed by
+// dot-separated names, each optionally indexed by an integer, `*` or a
+// `?(@.field=="value")` filter.
+func Parse(path string) error {
+	start, end := trimmed(path)
+	if err := checkRoot(path, start, end); err != nil {
+		return err
+	}
+	partStart, depth, quote, quoteAt, openAt := start+2, 0, byte(0), 0, 0
+	for i := start + 2; i < end; i++ {
+		ch := path[i]
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'':
+			quote, quoteAt = ch, i
+		case '[':
+			if depth == 0 {
+				openAt = i
+			}
+			depth++
+		case ']':
+			depth--
+			if depth < 0 {
+				return errorAt(i, `unmatched "]"`)
+			}
+		case '.':
+			if depth == 0 {
+				if err := checkPart(path, partStart, i); err != nil {
+					return err
+				}
+				partStart = i + 1
+			}
+		}
+	}
+	if quote != 0 {
+		return errorAt(quoteAt, "unterminated quote")
+	}
+	if depth != 0 {
+		return errorAt(openAt, `unclosed "["`)
+	}
+	return checkPart(path, partStart, end)
+}
+
+// ParseObject checks a path as json_* operations address request and
+// response bodies: `$.` followed by dot-separated object keys only.
+func ParseObject(path string) error {
+	start, end := trimmed(path)
+	if err := checkRoot(path, start, end); err != nil {
+		return err
+	}
+	if i := strings.IndexAny(path[start:end], "[]"); i >= 0 {
+		return errorAt(start+i, "array indexes are not supported by json operations")
+	}
+	partStart := start + 2
+	for i := partStart; i <= end; i++ {
+		if i < end && path[i] != '.' {
+			continue
+		}
+		if strings.TrimSpace(path[partStart:i]) == "" {
+			return errorAt(partStart, "empty path segment")
+		}
+		partStart = i + 1
+	}
+	return nil
+}
+
+func trimmed(path string) (int, int) {
+	start, end := 0, len(path)
+	for start < end && isSpace(path[start]) {
+		start++
+	}
+	for end > start && isSpace(path[end-1]) {
+		end--
+	}
+	return start, end
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func checkRoot(path string, start, end int) error {
+	if start == end {
+		return errorAt(start, "empty JSONPath")
+	}
+	if !strings.HasPrefix(path[start:end], "$.") {
+		if path[start] == '$' {
+			return errorAt(start+1, `JSONPath must start with "$."`)
+		}
+		return errorAt(start, `JSONPath must start with "$."`)
+	}
+	return nil
+}
+
+// checkPart checks the segment path[start:end], a name with an optional
+// trailing index.
+func checkPart(path string, start, end int) error {
+	for start < end && isSpace(path[start]) {
+		start++
+	}
+	for end > start && isSpace(path[end-1]) {
+		end--
+	}
+	if start == end {
+		return errorAt(start, "empty path segment")
+	}
+	part := path[start:end]
+	open := strings.IndexByte(part, '[')
+	if open < 0 {
+		return nil
+	}
+	closing := strings.LastIndexByte(part, ']')
+	if closing != len(part)-1 {
+		return errorAt(start+closing+1, `unexpected %q after "]"`, part[closing+1:])
+	}
+	inner := part[open+1 : closing]
+	at := start + open + 1
+	trimmedInner := strings.TrimSpace(inner)
+	switch {
+	case trimmedInner == "*":
+		return nil
+	case strings.HasPrefix(trimmedInner, "?"):
+		return checkFilter(path, at+strings.Index(inner, "?"), trimmedInner)
+	}
+	if _, err := strconv.Atoi(trimmedInner); err != nil {
+		return errorAt(at, `invalid index %q: want an integer, "*" or a filter ?(@.field=="value")`, inner)
+	}
+	return nil
+}
+
+// checkFilter checks a `?(@.field=="value")` filter starting at path[at].
+func checkFilter(path string, at int, filter string) error {
+	if !strings.HasPrefix(filter, "?(") {
+		return errorAt(at+1, `filter must be written ?(@.field=="value")`)
+	}
+	if !strings.HasSuffix(filter, ")") {
+		return errorAt(at+len(filter), `filter is missing ")"`)
+	}
+	body := filter[2 : len(filter)-1]
+	bodyAt := at + 2
+	lead := len(body) - len(strings.TrimLeft(body, " \t"))
+	body, bodyAt = strings.TrimSpace(body), bodyAt+lead
+	if !strings.HasPrefix(body, "@.") {
+		return errorAt(bodyAt, `filter must test a field of the current element, like @.type`)
+	}
+	eq := strings.Index(body, "==")
+	if eq < 0 {
+		return errorAt(bodyAt, `filter must compare with "=="`)
+	}
+	if strings.TrimSpace(body[2:eq]) == "" {
+		return errorAt(bodyAt+2, "filter field is empty")
+	}
+	raw := body[eq+2:]
+	rawAt := bodyAt + eq + 2 + len(raw) - len(strings.TrimLeft(raw, " \t"))
+	raw = strings.TrimSpace(raw)
+	if len(raw) < 2 || (raw[0] != '"' && raw[0] != '\'') || raw[len(raw)-1] != raw[0] {
+		return errorAt(rawAt, "filter value must be a quoted string")
+	}
+	return nil
+}
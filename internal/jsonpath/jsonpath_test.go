@@ -0,0 +1,180 @@
+package jsonpath
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		path   string
+		object bool
+		offset int
+		msg    string
+	}{
+		{path: "$.usage.prompt_tokens", offset: -1},
+		{path: " $.choices[0].message.content ", offset: -1},
+		{path: "$.output[*].content[*].text", offset: -1},
+		{path: `$.usageMetadata.promptTokensDetails[?(@.modality=="AUDIO")].tokenCount`, offset: -1},
+		{path: `$.items[?(@.meta.kind=='a.b')].n`, offset: -1},
+		{path: "", offset: 0, msg: "empty JSONPath"},
+		{path: "usage.x", offset: 0, msg: `must start with "$."`},
+		{path: "$usage", offset: 1, msg: `must start with "$."`},
+		{path: "$.usage..x", offset: 8, msg: "empty path segment"},
+		{path: "$.usage.", offset: 8, msg: "empty path segment"},
+		{path: "$.a]", offset: 3, msg: `unmatched "]"`},
+		{path: "$.a[0", offset: 3, msg: `unclosed "["`},
+		{path: "$.a[0]b", offset: 6, msg: `unexpected "b"`},
+		{path: "$.a[x].b", offset: 4, msg: `invalid index "x"`},
+		{path: `$.a[?(@.m="x")]`, offset: 6, msg: `compare with "=="`},
+		{path: `$.a[?(m=="x")]`, offset: 6, msg: "current element"},
+		{path: `$.a[?(@.m==x)]`, offset: 11, msg: "quoted string"},
+		{path: `$.a[?(@.m=="x)]`, offset: 11, msg: "unterminated quote"},
+		{path: `$.a[?(@.m=="x"`, offset: 3, msg: `unclosed "["`},
+		{path: "$.messages.0.content", object: true, offset: -1},
+		{path: "$.messages[0]", object: true, offset: 10, msg: "array indexes"},
+		{path: "$.a..b", object: true, offset: 4, msg: "empty path segment"},
+	} {
+		parse := Parse
+		if tc.object {
+			parse = ParseObject
+		}
+		err := parse(tc.path)
+		if tc.offset < 0 {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tc.path, err)
+			}
+			continue
+		}
+		var perr *Error
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: want error at %d, got %v", tc.path, tc.offset, err)
+			continue
+		}
+		if perr.Offset != tc.offset || !strings.Contains(perr.Message, tc.msg) {
+			t.Errorf("%q: got %d %q, want %d containing %q", tc.path, perr.Offset, perr.Message, tc.offset, tc.msg)
+		}
+	}
+}
+
+func TestDiagnosticsPointAtTheOffendingCharacter(t *testing.T) {
+	t.Parallel()
+
+	text := "usage_mode \"m\" {\n" +
+		"  usage_fact input token path=\"$.usage..x\";\n" +
+		"  usage_fact output token count_path = \"$.a[?(@.m==\\\"A\\\")]b\";\n" +
+		"  input_tokens_path \"$.usage.prompt_tokens\";\n" +
+		"  output_tokens_path \"$.usage.x;\n" +
+		"}\n" +
+		"provider \"p\" {\n  defaults {\n    request {\n      json_rename \"$.a\" \"$.b[0]\";\n    }\n  }\n}\n"
+	diags := Diagnostics(text)
+	type want struct{ line, start, end int }
+	var got []want
+	for _, d := range diags {
+		if d.Code != lint.RuleInvalidJSONPath {
+			t.Fatalf("unexpected code %q", d.Code)
+		}
+		got = append(got, want{d.Range.Start.Line, d.Range.Start.Character, d.Range.End.Character})
+	}
+	expected := []want{
+		{1, len(`  usage_fact input token path="$.usage.`), len(`  usage_fact input token path="$.usage..`)},
+		{2, len(`  usage_fact output token count_path = "$.a[?(@.m==\"A\")]`), len(`  usage_fact output token count_path = "$.a[?(@.m==\"A\")]b`)},
+		{9, len(`      json_rename "$.a" "$.b`), len(`      json_rename "$.a" "$.b[`)},
+	}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("ranges = %v, want %v\n%v", got, expected, diags)
+	}
+}
+
+func TestFamilies(t *testing.T) {
+	t.Parallel()
+
+	text := `provider "p" {
+  defaults {
+    metrics {
+      usage_extract custom;
+    }
+  }
+  match api = "chat.completions" {
+    response {
+      resp_map anthropic_to_openai_chat;
+      sse_parse anthropic_to_openai_chunks;
+    }
+    metrics {
+      usage_extract custom;
+    }
+  }
+  match api="gemini.generateContent" {
+    response {
+      resp_passthrough;
+    }
+  }
+}
+usage_mode "m" {
+  usage_extract custom;
+}
+`
+	byBlock := map[string][]string{}
+	dslast.Walk(dslast.Parse(text), func(stmt *dslast.Statement, parents []*dslast.Statement) bool {
+		if stmt.Name == "usage_extract" {
+			key := parents[0].Name
+			if len(parents) > 1 {
+				key += "/" + parents[1].Name
+			}
+			byBlock[key] = Families(parents)
+		}
+		return true
+	})
+	for key, want := range map[string][]string{
+		"provider/defaults": {FamilyAnthropicMessages, FamilyGemini},
+		"provider/match":    {FamilyAnthropicMessages},
+		"usage_mode":        nil,
+	} {
+		if !slices.Equal(byBlock[key], want) {
+			t.Errorf("%s: families = %v, want %v", key, byBlock[key], want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	t.Parallel()
+
+	names := func(cs []Candidate) string {
+		out := make([]string, 0, len(cs))
+		for _, c := range cs {
+			out = append(out, c.Name)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := names(Complete("$.usage.pro", []string{FamilyOpenAIChat})); got != "completion_tokens,completion_tokens_details,prompt_tokens,prompt_tokens_details,total_tokens" {
+		t.Fatalf("openai usage fields = %s", got)
+	}
+	if got := names(Complete("$.candidates[0].", []string{FamilyGemini})); got != "content,finishReason,index,safetyRatings" {
+		t.Fatalf("gemini candidate fields = %s", got)
+	}
+	all := Complete("$.usage.", nil)
+	i := slices.IndexFunc(all, func(c Candidate) bool { return c.Name == "input_tokens" })
+	if i < 0 || !slices.Equal(all[i].Families, []string{FamilyOpenAIResponses, FamilyAnthropicMessages}) {
+		t.Fatalf("input_tokens candidate = %+v", all)
+	}
+	if got := Complete("$.choices[0", nil); got != nil {
+		t.Fatalf("completion inside an index = %v", got)
+	}
+	for mode, want := range map[string]string{
+		"anthropic_to_openai_chat":               FamilyAnthropicMessages,
+		"openai_responses_to_openai_chat_chunks": FamilyOpenAIResponses,
+		"openai_to_gemini_chat":                  FamilyOpenAIChat,
+		"gemini_to_openai_chat":                  FamilyGemini,
+	} {
+		if got, ok := FamilyOfMode(mode); !ok || got != want {
+			t.Errorf("FamilyOfMode(%q) = %q, want %q", mode, got, want)
+		}
+	}
+}
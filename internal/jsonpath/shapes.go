@@ -0,0 +1,360 @@
+package jsonpath
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Field is one key of a response shape.
+type Field struct {
+	Name   string
+	Doc    string
+	Array  bool
+	Fields []Field
+}
+
+// Shape is the response body of an API family, including the fields of its
+// streaming events.
+type Shape struct {
+	Family string
+	Title  string
+	Fields []Field
+}
+
+// Built-in families. resp_map and sse_parse modes name the upstream family
+// before "_to_"; "openai" there means Chat Completions.
+const (
+	FamilyOpenAIChat        = "openai_chat"
+	FamilyOpenAIResponses   = "openai_responses"
+	FamilyAnthropicMessages = "anthropic_messages"
+	FamilyGemini            = "gemini"
+)
+
+func leaf(name, doc string) Field { return Field{Name: name, Doc: doc} }
+
+func object(name, doc string, fields ...Field) Field {
+	return Field{Name: name, Doc: doc, Fields: fields}
+}
+
+func array(name, doc string, fields ...Field) Field {
+	return Field{Name: name, Doc: doc, Array: true, Fields: fields}
+}
+
+var toolCalls = array("tool_calls", "Tool calls requested by the model.",
+	leaf("index", "Position of the tool call in a stream."),
+	leaf("id", "Tool call id."),
+	leaf("type", `Always "function".`),
+	object("function", "Called function.", leaf("name", "Function name."), leaf("arguments", "JSON-encoded arguments.")),
+)
+
+var openAIChatUsage = object("usage", "Token usage of the request.",
+	leaf("prompt_tokens", "Input tokens."),
+	leaf("completion_tokens", "Output tokens."),
+	leaf("total_tokens", "Input plus output tokens."),
+	object("prompt_tokens_details", "Input token breakdown.",
+		leaf("cached_tokens", "Input tokens served from the prompt cache."),
+		leaf("audio_tokens", "Audio input tokens."),
+	),
+	object("completion_tokens_details", "Output token breakdown.",
+		leaf("reasoning_tokens", "Reasoning tokens."),
+		leaf("audio_tokens", "Audio output tokens."),
+		leaf("accepted_prediction_tokens", "Predicted output tokens that appeared in the completion."),
+		leaf("rejected_prediction_tokens", "Predicted output tokens that did not appear in the completion."),
+	),
+)
+
+var openAIResponsesUsage = object("usage", "Token usage of the response.",
+	leaf("input_tokens", "Input tokens."),
+	leaf("output_tokens", "Output tokens."),
+	leaf("total_tokens", "Input plus output tokens."),
+	object("input_tokens_details", "Input token breakdown.", leaf("cached_tokens", "Input tokens served from the prompt cache.")),
+	object("output_tokens_details", "Output token breakdown.", leaf("reasoning_tokens", "Reasoning tokens.")),
+)
+
+var openAIResponse = []Field{
+	leaf("id", "Response id."),
+	leaf("object", `Always "response".`),
+	leaf("created_at", "Unix creation time."),
+	leaf("model", "Model that produced the response."),
+	leaf("status", "completed, incomplete, failed or in_progress."),
+	object("incomplete_details", "Why the response is incomplete.", leaf("reason", "max_output_tokens or content_filter.")),
+	array("output", "Output items.",
+		leaf("type", "message, reasoning, function_call, ..."),
+		leaf("id", "Item id."),
+		leaf("role", "Message role."),
+		leaf("status", "Item status."),
+		array("content", "Message content parts.", leaf("type", "output_text or refusal."), leaf("text", "Generated text.")),
+		leaf("name", "Called function name."),
+		leaf("arguments", "JSON-encoded function arguments."),
+		leaf("call_id", "Function call id."),
+	),
+	openAIResponsesUsage,
+}
+
+var anthropicUsage = object("usage", "Token usage of the message.",
+	leaf("input_tokens", "Input tokens after the last cache breakpoint."),
+	leaf("output_tokens", "Output tokens."),
+	leaf("cache_creation_input_tokens", "Input tokens written to the prompt cache."),
+	leaf("cache_read_input_tokens", "Input tokens read from the prompt cache."),
+)
+
+var anthropicMessage = []Field{
+	leaf("id", "Message id."),
+	leaf("type", `"message", or the stream event type.`),
+	leaf("role", `Always "assistant".`),
+	leaf("model", "Model that produced the message."),
+	array("content", "Content blocks.",
+		leaf("type", "text, thinking or tool_use."),
+		leaf("text", "Generated text."),
+		leaf("thinking", "Extended thinking text."),
+		leaf("id", "Tool use id."),
+		leaf("name", "Tool name."),
+		object("input", "Tool input."),
+	),
+	leaf("stop_reason", "end_turn, max_tokens, stop_sequence, tool_use, ..."),
+	leaf("stop_sequence", "Stop sequence that ended the message."),
+	anthropicUsage,
+}
+
+var geminiTokenDetails = []Field{
+	leaf("modality", "TEXT, IMAGE, AUDIO or VIDEO."),
+	leaf("tokenCount", "Tokens of this modality."),
+}
+
+var shapes = []Shape{
+	{
+		Family: FamilyOpenAIChat,
+		Title:  "OpenAI Chat Completions",
+		Fields: []Field{
+			leaf("id", "Completion id."),
+			leaf("object", `"chat.completion" or "chat.completion.chunk".`),
+			leaf("created", "Unix creation time."),
+			leaf("model", "Model that produced the completion."),
+			leaf("system_fingerprint", "Backend configuration fingerprint."),
+			array("choices", "Completion choices.",
+				leaf("index", "Choice index."),
+				object("message", "Generated message.",
+					leaf("role", `Always "assistant".`),
+					leaf("content", "Generated text."),
+					leaf("refusal", "Refusal text."),
+					toolCalls,
+				),
+				object("delta", "Streamed message delta.",
+					leaf("role", "Role, on the first chunk."),
+					leaf("content", "Text delta."),
+					toolCalls,
+				),
+				leaf("finish_reason", "stop, length, tool_calls, content_filter, ..."),
+				object("logprobs", "Token log probabilities."),
+			),
+			openAIChatUsage,
+		},
+	},
+	{
+		Family: FamilyOpenAIResponses,
+		Title:  "OpenAI Responses",
+		Fields: append(append([]Field{
+			leaf("type", "Stream event type, such as response.completed."),
+			object("response", "Response object of response.* stream events.", openAIResponse...),
+		}, openAIResponse...), leaf("output_text", "Concatenated output text.")),
+	},
+	{
+		Family: FamilyAnthropicMessages,
+		Title:  "Anthropic Messages",
+		Fields: append(append([]Field{}, anthropicMessage...),
+			leaf("index", "Content block index of a stream event."),
+			object("message", "Message of the message_start event.", anthropicMessage...),
+			object("delta", "Delta of message_delta and content_block_delta events.",
+				leaf("type", "text_delta, input_json_delta or thinking_delta."),
+				leaf("text", "Text delta."),
+				leaf("stop_reason", "Stop reason, on message_delta."),
+				leaf("stop_sequence", "Stop sequence, on message_delta."),
+			),
+		),
+	},
+	{
+		Family: FamilyGemini,
+		Title:  "Gemini generateContent",
+		Fields: []Field{
+			array("candidates", "Response candidates.",
+				object("content", "Generated content.",
+					leaf("role", `Always "model".`),
+					array("parts", "Content parts.",
+						leaf("text", "Generated text."),
+						leaf("thought", "Whether the part is a thought summary."),
+						object("functionCall", "Requested function call.", leaf("name", "Function name."), object("args", "Function arguments.")),
+					),
+				),
+				leaf("finishReason", "STOP, MAX_TOKENS, SAFETY, ..."),
+				leaf("index", "Candidate index."),
+				array("safetyRatings", "Safety ratings.", leaf("category", "Harm category."), leaf("probability", "Harm probability.")),
+			),
+			object("usageMetadata", "Token usage of the request.",
+				leaf("promptTokenCount", "Input tokens."),
+				leaf("candidatesTokenCount", "Output tokens."),
+				leaf("totalTokenCount", "Input plus output tokens."),
+				leaf("cachedContentTokenCount", "Input tokens served from cached content."),
+				leaf("thoughtsTokenCount", "Thinking tokens."),
+				leaf("toolUsePromptTokenCount", "Tokens of tool-use prompts."),
+				array("promptTokensDetails", "Input tokens per modality.", geminiTokenDetails...),
+				array("candidatesTokensDetails", "Output tokens per modality.", geminiTokenDetails...),
+				array("cacheTokensDetails", "Cached tokens per modality.", geminiTokenDetails...),
+			),
+			leaf("modelVersion", "Model that produced the response."),
+			leaf("responseId", "Response id."),
+		},
+	},
+}
+
+// Shapes returns the built-in response shapes.
+func Shapes() []Shape {
+	return append([]Shape(nil), shapes...)
+}
+
+// LookupShape returns the shape of family.
+func LookupShape(family string) (Shape, bool) {
+	for _, s := range shapes {
+		if s.Family == family {
+			return s, true
+		}
+	}
+	return Shape{}, false
+}
+
+// FamilyOfMode returns the upstream family a resp_map or sse_parse mode
+// reads, such as anthropic_messages for anthropic_to_openai_chat.
+func FamilyOfMode(mode string) (string, bool) {
+	src, _, ok := strings.Cut(mode, "_to_")
+	if !ok {
+		return "", false
+	}
+	switch src {
+	case "openai", "openai_chat":
+		return FamilyOpenAIChat, true
+	case "openai_responses":
+		return FamilyOpenAIResponses, true
+	case "anthropic", "claude":
+		return FamilyAnthropicMessages, true
+	case "gemini":
+		return FamilyGemini, true
+	}
+	return "", false
+}
+
+// FamilyOfAPI returns the family a match api answers in when the upstream
+// response is passed through unmapped.
+func FamilyOfAPI(api string) (string, bool) {
+	switch {
+	case api == "chat.completions":
+		return FamilyOpenAIChat, true
+	case api == "responses":
+		return FamilyOpenAIResponses, true
+	case api == "claude.messages":
+		return FamilyAnthropicMessages, true
+	case strings.HasPrefix(api, "gemini."):
+		return FamilyGemini, true
+	}
+	return "", false
+}
+
+// Candidate is a completion for the next path segment.
+type Candidate struct {
+	Name  string
+	Doc   string
+	Array bool
+	// Families lists the shapes that have the field.
+	Families []string
+}
+
+// Complete returns the fields that may follow the complete segments of typed
+// in the shapes of families, or in every shape when families is empty.
+// typed is the path up to the cursor; its last segment is the partial name
+// and is not used for filtering.
+func Complete(typed string, families []string) []Candidate {
+	if !strings.HasPrefix(typed, "$.") {
+		return nil
+	}
+	parents, ok := splitSegments(typed[2:])
+	if !ok {
+		return nil
+	}
+	parents = parents[:len(parents)-1]
+	var selected []Shape
+	for _, s := range shapes {
+		if len(families) == 0 || slices.Contains(families, s.Family) {
+			selected = append(selected, s)
+		}
+	}
+	byName := map[string]*Candidate{}
+	for _, s := range selected {
+		fields := s.Fields
+		for _, name := range parents {
+			fields = childFields(fields, name)
+		}
+		for _, f := range fields {
+			c, ok := byName[f.Name]
+			if !ok {
+				c = &Candidate{Name: f.Name, Doc: f.Doc, Array: f.Array}
+				byName[f.Name] = c
+			}
+			c.Families = append(c.Families, s.Family)
+		}
+	}
+	out := make([]Candidate, 0, len(byName))
+	for _, c := range byName {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// splitSegments returns the segment names of a path without "$.", with
+// indexes dropped. ok is false inside an unclosed index or quote.
+func splitSegments(rest string) ([]string, bool) {
+	var names []string
+	start, depth := 0, 0
+	var quote byte
+	for i := 0; i < len(rest); i++ {
+		ch := rest[i]
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'':
+			quote = ch
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				names = append(names, segmentName(rest[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if quote != 0 || depth != 0 {
+		return nil, false
+	}
+	return append(names, segmentName(rest[start:])), true
+}
+
+func segmentName(part string) string {
+	if i := strings.IndexByte(part, '['); i >= 0 {
+		part = part[:i]
+	}
+	return strings.TrimSpace(part)
+}
+
+func childFields(fields []Field, name string) []Field {
+	for _, f := range fields {
+		if f.Name == name {
+			return f.Fields
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigFileName is the project config file looked up from a document's
+// directory towards the filesystem root.
+const ConfigFileName = ".onr-lsp.json"
+
+// Config holds per-rule severity overrides. Unlisted rules use their default
+// severity.
+type Config struct {
+	Rules map[string]int
+	// TargetCore is the onr-core release configs must stay compatible with,
+	// e.g. "v1.14.x"; empty derives it from the file's syntax directive.
+	TargetCore string
+}
+
+// configFile is the on-disk and editor-settings representation of Config.
+type configFile struct {
+	Rules      map[string]string `json:"rules"`
+	TargetCore string            `json:"targetCore,omitempty"`
+}
+
+// Severity returns the configured severity for rule id.
+func (c Config) Severity(id string) int {
+	if sev, ok := c.Rules[id]; ok {
+		return sev
+	}
+	if r, ok := LookupRule(id); ok {
+		return r.DefaultSeverity
+	}
+	return SeverityError
+}
+
+// Merge returns c overlaid with the rules set in other.
+func (c Config) Merge(other Config) Config {
+	out := Config{Rules: map[string]int{}, TargetCore: c.TargetCore}
+	if other.TargetCore != "" {
+		out.TargetCore = other.TargetCore
+	}
+	for id, sev := range c.Rules {
+		out.Rules[id] = sev
+	}
+	for id, sev := range other.Rules {
+		out.Rules[id] = sev
+	}
+	return out
+}
+
+// ParseConfig parses a JSON config such as
+// {"rules": {"unknown-directive": "warning"}, "targetCore": "v1.14.x"}.
+func ParseConfig(data []byte) (Config, error) {
+	var raw configFile
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Config{}, fmt.Errorf("parse lint config: %w", err)
+	}
+	return raw.config()
+}
+
+func (f configFile) config() (Config, error) {
+	cfg, err := configFromRules(f.Rules)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.TargetCore = strings.TrimSpace(f.TargetCore)
+	return cfg, nil
+}
+
+func configFromRules(in map[string]string) (Config, error) {
+	cfg := Config{Rules: map[string]int{}}
+	for id, value := range in {
+		id = strings.TrimSpace(id)
+		if _, ok := LookupRule(id); !ok {
+			return Config{}, fmt.Errorf("unknown lint rule %q", id)
+		}
+		sev, ok := ParseSeverity(value)
+		if !ok {
+			return Config{}, fmt.Errorf("invalid severity %q for rule %q", value, id)
+		}
+		cfg.Rules[id] = sev
+	}
+	return cfg, nil
+}
+
+// ConfigFromSettings builds a Config from the editor settings object sent in
+// workspace/didChangeConfiguration, i.e.
+// {"onrLsp": {"lint": {"rules": {...}}, "targetCore": "v1.14.x"}}.
+func ConfigFromSettings(settings json.RawMessage) (Config, error) {
+	var raw struct {
+		OnrLsp struct {
+			Lint       configFile `json:"lint"`
+			TargetCore string     `json:"targetCore"`
+		} `json:"onrLsp"`
+	}
+	if len(settings) == 0 || string(settings) == "null" {
+		return Config{}, nil
+	}
+	if err := json.Unmarshal(settings, &raw); err != nil {
+		return Config{}, fmt.Errorf("parse lint settings: %w", err)
+	}
+	cfg, err := configFromRules(raw.OnrLsp.Lint.Rules)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.TargetCore = strings.TrimSpace(raw.OnrLsp.TargetCore)
+	return cfg, nil
+}
+
+// LoadConfig reads the nearest ConfigFileName at or above dir. It returns an
+// empty Config when no file exists.
+func LoadConfig(dir string) (Config, string, error) {
+	path, ok := FindConfigFile(dir)
+	if !ok {
+		return Config{}, "", nil
+	}
+	cfg, err := LoadConfigFile(path)
+	return cfg, path, err
+}
+
+// LoadConfigFile reads one config file.
+func LoadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read lint config %q: %w", path, err)
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// FindConfigFile returns the nearest ConfigFileName at or above dir.
+func FindConfigFile(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		p := filepath.Join(abs, ConfigFileName)
+		if st, err := os.Stat(p); err == nil && !st.IsDir() {
+			return p, true
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", false
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", false
+		}
+		abs = parent
+	}
+}
+
+// SeverityName returns the lower-case name of a severity.
+func SeverityName(severity int) string {
+	switch severity {
+	case SeverityOff:
+		return "off"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInformation:
+		return "info"
+	case SeverityHint:
+		return "hint"
+	default:
+		return "error"
+	}
+}
+
+// ParseSeverity parses a severity name, including "off".
+func ParseSeverity(name string) (int, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "off", "none":
+		return SeverityOff, true
+	case "error":
+		return SeverityError, true
+	case "warning", "warn":
+		return SeverityWarning, true
+	case "info", "information":
+		return SeverityInformation, true
+	case "hint":
+		return SeverityHint, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,102 @@
+package lint
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`{"rules": {"unknown-directive": "warning", "semantic-error": "off"}}`))
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	if got := cfg.Severity(RuleUnknownDirective); got != SeverityWarning {
+		t.Fatalf("unexpected unknown-directive severity: %d", got)
+	}
+	if got := cfg.Severity(RuleSemanticError); got != SeverityOff {
+		t.Fatalf("unexpected semantic-error severity: %d", got)
+	}
+	if got := cfg.Severity(RuleSyntaxError); got != SeverityError {
+		t.Fatalf("expected default severity, got %d", got)
+	}
+}
+
+func TestParseConfigRejectsUnknownRuleAndSeverity(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ParseConfig([]byte(`{"rules": {"nope": "error"}}`)); err == nil || !strings.Contains(err.Error(), "unknown lint rule") {
+		t.Fatalf("expected unknown rule error, got: %v", err)
+	}
+	if _, err := ParseConfig([]byte(`{"rules": {"syntax-error": "loud"}}`)); err == nil || !strings.Contains(err.Error(), "invalid severity") {
+		t.Fatalf("expected invalid severity error, got: %v", err)
+	}
+}
+
+func TestConfigFromSettings(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ConfigFromSettings(json.RawMessage(`{"onrLsp": {"lint": {"rules": {"unsupported-mode": "hint"}}}}`))
+	if err != nil {
+		t.Fatalf("config from settings: %v", err)
+	}
+	if got := cfg.Severity(RuleUnsupportedMode); got != SeverityHint {
+		t.Fatalf("unexpected severity: %d", got)
+	}
+	if cfg, err := ConfigFromSettings(nil); err != nil || len(cfg.Rules) != 0 {
+		t.Fatalf("expected empty config for nil settings, got %+v err=%v", cfg, err)
+	}
+}
+
+func TestLoadConfigSearchesParents(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	sub := filepath.Join(root, "providers")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte(`{"rules": {"syntax-error": "warning"}}`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, path, err := LoadConfig(sub)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if path != filepath.Join(root, ConfigFileName) || cfg.Severity(RuleSyntaxError) != SeverityWarning {
+		t.Fatalf("unexpected config %+v from %q", cfg, path)
+	}
+}
+
+func TestMergeOverridesRules(t *testing.T) {
+	t.Parallel()
+
+	base := Config{Rules: map[string]int{RuleSyntaxError: SeverityWarning, RuleSemanticError: SeverityOff}}
+	merged := base.Merge(Config{Rules: map[string]int{RuleSyntaxError: SeverityHint}})
+	if merged.Severity(RuleSyntaxError) != SeverityHint || merged.Severity(RuleSemanticError) != SeverityOff {
+		t.Fatalf("unexpected merged config: %+v", merged)
+	}
+}
+
+func TestTargetCoreFromConfigAndSettings(t *testing.T) {
+	t.Parallel()
+
+	project, err := ParseConfig([]byte(`{"targetCore": " v1.14.x "}`))
+	if err != nil || project.TargetCore != "v1.14.x" {
+		t.Fatalf("unexpected project config %+v err=%v", project, err)
+	}
+	settings, err := ConfigFromSettings(json.RawMessage(`{"onrLsp": {"targetCore": "v1.15.x"}}`))
+	if err != nil || settings.TargetCore != "v1.15.x" {
+		t.Fatalf("unexpected settings config %+v err=%v", settings, err)
+	}
+	if got := settings.Merge(project).TargetCore; got != "v1.14.x" {
+		t.Fatalf("project targetCore must win, got %q", got)
+	}
+	if got := settings.Merge(Config{}).TargetCore; got != "v1.15.x" {
+		t.Fatalf("empty targetCore must not override, got %q", got)
+	}
+}
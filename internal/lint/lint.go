@@ -0,0 +1,79 @@
+package lint
+
+import (
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// Severity values follow LSP DiagnosticSeverity numbering. SeverityOff
+// disables a rule.
+const (
+	SeverityOff         = 0
+	SeverityError       = 1
+	SeverityWarning     = 2
+	SeverityInformation = 3
+	SeverityHint        = 4
+)
+
+// Diagnostic tag values follow LSP DiagnosticTag numbering.
+const (
+	TagUnnecessary = 1
+	TagDeprecated  = 2
+)
+
+// Diagnostic is a dsllang diagnostic tagged with its rule ID.
+type Diagnostic struct {
+	Range              dsllang.Range        `json:"range"`
+	Severity           int                  `json:"severity,omitempty"`
+	Code               string               `json:"code,omitempty"`
+	Source             string               `json:"source,omitempty"`
+	Message            string               `json:"message"`
+	Tags               []int                `json:"tags,omitempty"`
+	RelatedInformation []RelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+// Location is a range in another document.
+type Location struct {
+	URI   string        `json:"uri"`
+	Range dsllang.Range `json:"range"`
+}
+
+// RelatedInformation points at a location related to a diagnostic, such as
+// the other declaration of a duplicate name.
+type RelatedInformation struct {
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
+}
+
+// Apply classifies diags, applies per-rule severities from cfg and drops
+// diagnostics disabled by cfg or suppressed by inline comments in text.
+func Apply(text string, diags []dsllang.Diagnostic, cfg Config) []Diagnostic {
+	coded := make([]Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		coded = append(coded, Diagnostic{
+			Range:   d.Range,
+			Code:    Classify(d.Message),
+			Source:  d.Source,
+			Message: d.Message,
+		})
+	}
+	return Filter(text, coded, cfg)
+}
+
+// Filter applies per-rule severities from cfg to diagnostics that already
+// carry a rule ID and drops those disabled by cfg or suppressed in text.
+func Filter(text string, diags []Diagnostic, cfg Config) []Diagnostic {
+	sup := ParseSuppressions(text)
+	out := make([]Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		sev := cfg.Severity(d.Code)
+		if sev == SeverityOff {
+			continue
+		}
+		if sup.Suppressed(d.Code, d.Range.Start.Line) {
+			continue
+		}
+		d.Severity = sev
+		out = append(out, d)
+	}
+	return out
+}
@@ -0,0 +1,156 @@
+package lint
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"unknown top-level directive: foo":                               RuleUnknownDirective,
+		"unknown directive in request block: bogus":                      RuleUnknownDirective,
+		"directive req_map is not allowed in response block; allowed in": RuleMisplacedDirective,
+		`unsupported req_map mode "nope"`:                                RuleUnsupportedMode,
+		"expected ';' after req_map":                                     RuleSyntaxError,
+		"missing closing '}' for provider block":                         RuleSyntaxError,
+		"include does not use '{ ... }'; expected ';'":                   RuleSyntaxError,
+		`provider "x": upstream_config.base_url is required`:             RuleSemanticError,
+	}
+	for msg, want := range cases {
+		if got := Classify(msg); got != want {
+			t.Fatalf("Classify(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
+
+// TestClassifyCoreDiagnostics pins Classify to the messages the onr-core
+// release in go.mod actually reports. dsllang.Diagnostic has no code, so a
+// reworded message in a core bump must fail here rather than silently fall
+// back to semantic-error.
+func TestClassifyCoreDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	request := func(body string) string {
+		return "provider \"p\" {\n  defaults {\n    upstream_config { base_url = \"https://example.com\"; }\n    " + body + "\n  }\n}\n"
+	}
+	cases := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"unknown top-level", "unknown_top foo;\n", []string{RuleUnknownDirective}},
+		{"unknown in block", request("request { bogus on; }"), []string{RuleUnknownDirective}},
+		{"misplaced", request("response { req_map openai_chat; }"), []string{RuleMisplacedDirective}},
+		{"unsupported mode", request("request { req_map nope; }"), []string{RuleUnsupportedMode, RuleSemanticError}},
+		{"missing semicolon", request("request { req_map openai_chat }"), []string{RuleSyntaxError, RuleUnsupportedMode}},
+		{"missing brace", "provider \"p\" {\n", []string{RuleSyntaxError, RuleSemanticError}},
+		{"include without path", "include;\n", []string{RuleSyntaxError, RuleSemanticError}},
+		{"include with block", "include { }\n", []string{RuleSyntaxError, RuleSyntaxError}},
+		{"semantic", "provider \"p\" {\n}\n", []string{RuleSemanticError}},
+	}
+	for _, tc := range cases {
+		var got []string
+		var messages []string
+		for _, d := range dsllang.CollectDiagnostics("", tc.src) {
+			got = append(got, Classify(d.Message))
+			messages = append(messages, d.Message)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Fatalf("%s: rules %q, want %q (messages %q)", tc.name, got, tc.want, messages)
+		}
+	}
+}
+
+func TestApplySeverityOverridesAndOff(t *testing.T) {
+	t.Parallel()
+
+	diags := []dsllang.Diagnostic{
+		{Severity: 1, Message: "unknown directive in request block: bogus"},
+		{Severity: 1, Message: `unsupported req_map mode "nope"`},
+	}
+	cfg := Config{Rules: map[string]int{
+		RuleUnknownDirective: SeverityWarning,
+		RuleUnsupportedMode:  SeverityOff,
+	}}
+	got := Apply("", diags, cfg)
+	if len(got) != 1 {
+		t.Fatalf("expected one diagnostic, got %+v", got)
+	}
+	if got[0].Code != RuleUnknownDirective || got[0].Severity != SeverityWarning {
+		t.Fatalf("unexpected diagnostic: %+v", got[0])
+	}
+}
+
+func TestApplyInlineSuppressions(t *testing.T) {
+	t.Parallel()
+
+	text := "provider \"x\" {\n" +
+		"  # onr-lsp:ignore unknown-directive\n" +
+		"  request {\n" +
+		"    bogus;\n" +
+		"  }\n" +
+		"  # onr-lsp:ignore unsupported-mode\n" +
+		"  other;\n" +
+		"  third;\n" +
+		"}\n"
+	diags := []dsllang.Diagnostic{
+		{Range: rangeAt(3, 4), Message: "unknown directive in request block: bogus"},
+		{Range: rangeAt(6, 2), Message: "unknown directive in provider block: other"},
+		{Range: rangeAt(7, 2), Message: "unknown directive in provider block: third"},
+	}
+	got := Apply(text, diags, Config{})
+	if len(got) != 2 || got[0].Range.Start.Line != 6 || got[1].Range.Start.Line != 7 {
+		t.Fatalf("expected block suppression only for unknown-directive inside request, got %+v", got)
+	}
+}
+
+func TestSuppressionWithoutRulesSuppressesAll(t *testing.T) {
+	t.Parallel()
+
+	text := "// onr-lsp:ignore\n\nbad line;\nnext;\n"
+	sup := ParseSuppressions(text)
+	if !sup.Suppressed(RuleSemanticError, 2) {
+		t.Fatalf("expected all rules suppressed on target line")
+	}
+	if sup.Suppressed(RuleSemanticError, 3) {
+		t.Fatalf("expected suppression to stop after target line")
+	}
+}
+
+func TestSuppressionIgnoresBracesInStrings(t *testing.T) {
+	t.Parallel()
+
+	text := "# onr-lsp:ignore\nset_header X \"{\";\nnext;\n"
+	if ParseSuppressions(text).Suppressed(RuleSemanticError, 2) {
+		t.Fatalf("brace inside string must not extend suppression")
+	}
+}
+
+func TestFilterKeepsCodeAndRelatedInformation(t *testing.T) {
+	t.Parallel()
+
+	related := []RelatedInformation{{Location: Location{URI: "file:///b.conf"}, Message: "also declared here"}}
+	diags := []Diagnostic{
+		{Range: rangeAt(0, 0), Code: RuleDuplicateProvider, Message: "duplicate", RelatedInformation: related},
+		{Range: rangeAt(2, 0), Code: RuleUndefinedPreset, Message: "undefined"},
+	}
+	text := "provider \"a\" {\n# onr-lsp:ignore undefined-preset\nusage_extract x;\n"
+	got := Filter(text, diags, Config{Rules: map[string]int{RuleDuplicateProvider: SeverityWarning}})
+	if len(got) != 1 {
+		t.Fatalf("expected suppressed diagnostic to be dropped, got %+v", got)
+	}
+	if got[0].Severity != SeverityWarning || got[0].Code != RuleDuplicateProvider || len(got[0].RelatedInformation) != 1 {
+		t.Fatalf("unexpected diagnostic: %+v", got[0])
+	}
+}
+
+func rangeAt(line, col int) dsllang.Range {
+	return dsllang.Range{
+		Start: dsllang.Position{Line: line, Character: col},
+		End:   dsllang.Position{Line: line, Character: col + 1},
+	}
+}
@@ -0,0 +1,98 @@
+package lint
+
+import (
+	"sort"
+	"strings"
+)
+
+// Stable rule IDs. They appear as diagnostic codes, in config files and in
+// inline suppressions, so existing IDs must not be renamed.
+const (
+	RuleSyntaxError        = "syntax-error"
+	RuleUnknownDirective   = "unknown-directive"
+	RuleMisplacedDirective = "misplaced-directive"
+	RuleUnsupportedMode    = "unsupported-mode"
+	RuleSemanticError      = "semantic-error"
+
+	RuleMissingInclude    = "missing-include"
+	RuleIncludeCycle      = "include-cycle"
+	RuleDuplicateProvider = "duplicate-provider"
+	RuleDuplicatePreset   = "duplicate-preset"
+	RuleUndefinedPreset   = "undefined-preset"
+
+	RuleUnusedPreset        = "unused-preset"
+	RuleOverriddenDirective = "overridden-directive"
+	RuleUnincludedFile      = "unincluded-file"
+
+	RuleDeprecatedDirective = "deprecated-directive"
+	RuleUnavailableInTarget = "unavailable-in-target"
+
+	RuleInvalidJSONPath   = "invalid-jsonpath"
+	RuleInvalidHeaderName = "invalid-header-name"
+)
+
+// Rule describes one diagnostic rule.
+type Rule struct {
+	ID              string
+	Description     string
+	DefaultSeverity int
+}
+
+var rules = []Rule{
+	{ID: RuleSyntaxError, Description: "Malformed statements, missing braces or semicolons.", DefaultSeverity: SeverityError},
+	{ID: RuleUnknownDirective, Description: "Directive name is not known in any block.", DefaultSeverity: SeverityError},
+	{ID: RuleMisplacedDirective, Description: "Directive is known but not allowed in the current block.", DefaultSeverity: SeverityError},
+	{ID: RuleUnsupportedMode, Description: "Mode value is not a built-in mode for the directive.", DefaultSeverity: SeverityError},
+	{ID: RuleSemanticError, Description: "Provider config rejected by onr-core semantic validation.", DefaultSeverity: SeverityError},
+	{ID: RuleMissingInclude, Description: "Include target does not exist or a glob matches no files.", DefaultSeverity: SeverityError},
+	{ID: RuleIncludeCycle, Description: "Include chain leads back to a file that is already being included.", DefaultSeverity: SeverityError},
+	{ID: RuleDuplicateProvider, Description: "Provider name is declared more than once in the workspace.", DefaultSeverity: SeverityError},
+	{ID: RuleDuplicatePreset, Description: "Preset name is declared more than once for the same preset kind.", DefaultSeverity: SeverityError},
+	{ID: RuleUndefinedPreset, Description: "Mode value is neither built in nor a preset declared in the workspace.", DefaultSeverity: SeverityError},
+	{ID: RuleUnusedPreset, Description: "Preset block is never referenced by any mode directive.", DefaultSeverity: SeverityHint},
+	{ID: RuleOverriddenDirective, Description: "Directive is overridden or repeated by a later directive in the same block.", DefaultSeverity: SeverityWarning},
+	{ID: RuleUnincludedFile, Description: "Provider file is never included by the root config.", DefaultSeverity: SeverityWarning},
+	{ID: RuleDeprecatedDirective, Description: "Directive was removed in a newer DSL syntax version; `onr-lsp migrate` can rewrite it.", DefaultSeverity: SeverityError},
+	{ID: RuleUnavailableInTarget, Description: "Directive, mode or enum value is not available in the targeted onr-core release.", DefaultSeverity: SeverityError},
+	{ID: RuleInvalidJSONPath, Description: "Path-typed argument is not a JSONPath onr-core can evaluate.", DefaultSeverity: SeverityError},
+	{ID: RuleInvalidHeaderName, Description: "Header-name argument is not a valid HTTP field name.", DefaultSeverity: SeverityError},
+}
+
+// Rules returns all known rules sorted by ID.
+func Rules() []Rule {
+	out := append([]Rule(nil), rules...)
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// LookupRule returns the rule with id.
+func LookupRule(id string) (Rule, bool) {
+	for _, r := range rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Classify returns the rule ID for a dsllang diagnostic message. dsllang
+// diagnostics carry no code, so the rule is read from the message wording.
+func Classify(message string) string {
+	msg := strings.TrimSpace(message)
+	switch {
+	case strings.HasPrefix(msg, "unknown top-level directive"),
+		strings.HasPrefix(msg, "unknown directive in "):
+		return RuleUnknownDirective
+	case strings.HasPrefix(msg, "directive ") && strings.Contains(msg, " is not allowed in "):
+		return RuleMisplacedDirective
+	case strings.HasPrefix(msg, "unsupported ") && strings.Contains(msg, " mode "):
+		return RuleUnsupportedMode
+	case strings.HasPrefix(msg, "expected "),
+		strings.HasPrefix(msg, "missing closing "),
+		strings.HasPrefix(msg, "include expects "),
+		strings.Contains(msg, "does not use '{ ... }'"):
+		return RuleSyntaxError
+	default:
+		return RuleSemanticError
+	}
+}
@@ -0,0 +1,144 @@
+package lint
+
+import (
+	"strings"
+)
+
+// suppressMarker starts an inline suppression comment:
+//
+//	# onr-lsp:ignore unknown-directive, unsupported-mode
+//
+// The comment applies to the next statement line. When that line opens a
+// block, it applies to the whole block. Without rule IDs every rule is
+// suppressed.
+const suppressMarker = "onr-lsp:ignore"
+
+// Suppressions holds inline suppressions parsed from one document.
+type Suppressions struct {
+	spans []suppressSpan
+}
+
+type suppressSpan struct {
+	startLine int
+	endLine   int
+	rules     map[string]struct{}
+}
+
+// Suppressed reports whether rule id is suppressed on line.
+func (s Suppressions) Suppressed(id string, line int) bool {
+	for _, span := range s.spans {
+		if line < span.startLine || line > span.endLine {
+			continue
+		}
+		if len(span.rules) == 0 {
+			return true
+		}
+		if _, ok := span.rules[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSuppressions scans text for inline suppression comments.
+func ParseSuppressions(text string) Suppressions {
+	lines := strings.Split(text, "\n")
+	var out Suppressions
+	for i, line := range lines {
+		rules, ok := parseSuppressComment(line)
+		if !ok {
+			continue
+		}
+		target := nextStatementLine(lines, i+1)
+		if target < 0 {
+			continue
+		}
+		out.spans = append(out.spans, suppressSpan{
+			startLine: target,
+			endLine:   blockEndLine(lines, target),
+			rules:     rules,
+		})
+	}
+	return out
+}
+
+func parseSuppressComment(line string) (map[string]struct{}, bool) {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(trimmed, "#"):
+		trimmed = strings.TrimSpace(trimmed[1:])
+	case strings.HasPrefix(trimmed, "//"):
+		trimmed = strings.TrimSpace(trimmed[2:])
+	default:
+		return nil, false
+	}
+	if !strings.HasPrefix(trimmed, suppressMarker) {
+		return nil, false
+	}
+	rest := trimmed[len(suppressMarker):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return nil, false
+	}
+	rules := map[string]struct{}{}
+	for _, f := range strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		rules[f] = struct{}{}
+	}
+	return rules, true
+}
+
+func nextStatementLine(lines []string, from int) int {
+	for i := from; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// blockEndLine returns the line closing the block opened on start, or start
+// itself when the line does not leave a block open.
+func blockEndLine(lines []string, start int) int {
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		depth += braceDelta(lines[i])
+		if depth <= 0 {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+func braceDelta(line string) int {
+	delta := 0
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+				continue
+			}
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'':
+			quote = ch
+		case '#':
+			return delta
+		case '/':
+			if i+1 < len(line) && line[i+1] == '/' {
+				return delta
+			}
+		case '{':
+			delta++
+		case '}':
+			delta--
+		}
+	}
+	return delta
+}
@@ -0,0 +1,81 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/onr-lsp/internal/migrate"
+)
+
+const codeActionKindQuickFix = "quickfix"
+
+type codeActionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Context      struct {
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	} `json:"context"`
+}
+
+type codeActionOptions struct {
+	CodeActionKinds []string `json:"codeActionKinds"`
+}
+
+type workspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+// CodeAction is a quick fix offered for a diagnostic.
+type CodeAction struct {
+	Title       string        `json:"title"`
+	Kind        string        `json:"kind"`
+	Diagnostics []Diagnostic  `json:"diagnostics,omitempty"`
+	IsPreferred bool          `json:"isPreferred,omitempty"`
+	Edit        workspaceEdit `json:"edit"`
+}
+
+// handleCodeAction offers to rename deprecated directives in the requested
+// range to their replacements.
+func (s *Server) handleCodeAction(id *json.RawMessage, params json.RawMessage) error {
+	var p codeActionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return s.replyError(id, -32602, "invalid params for code action")
+	}
+	actions := []CodeAction{}
+	text, ok := s.documentText(p.TextDocument.URI)
+	if !ok {
+		return s.reply(id, actions)
+	}
+	findings, _ := migrate.Find(text, "")
+	for _, f := range findings {
+		rng, newText := f.Edit()
+		if !f.Fixable() || !rangesOverlap(rng, p.Range) {
+			continue
+		}
+		action := CodeAction{
+			Title:       "Replace " + f.Entry.Name + " with " + newText,
+			Kind:        codeActionKindQuickFix,
+			IsPreferred: true,
+			Edit: workspaceEdit{Changes: map[string][]TextEdit{
+				p.TextDocument.URI: {{Range: rng, NewText: newText}},
+			}},
+		}
+		for _, d := range p.Context.Diagnostics {
+			if d.Code == lint.RuleDeprecatedDirective && d.Range == rng {
+				action.Diagnostics = append(action.Diagnostics, d)
+			}
+		}
+		actions = append(actions, action)
+	}
+	return s.reply(id, actions)
+}
+
+// rangesOverlap reports whether a and b share a position; touching ranges
+// count, so a cursor at either end of a name still gets its fix.
+func rangesOverlap(a, b Range) bool {
+	return !positionBefore(a.End, b.Start) && !positionBefore(b.End, a.Start)
+}
+
+func positionBefore(a, b Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
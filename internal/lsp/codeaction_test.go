@@ -0,0 +1,65 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHandle_DeprecatedDirectiveDiagnosticAndQuickFix(t *testing.T) {
+	s, out := newPullServer(t, "")
+	text := "provider \"p\" {\n  defaults {\n    request {\n      header_set \"X-A\" \"1\";\n    }\n  }\n}\n"
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: "untitled:a.conf", Text: text}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+
+	rawID := json.RawMessage("2")
+	req := json.RawMessage(`{"textDocument":{"uri":"untitled:a.conf"}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/diagnostic", Params: req}); err != nil {
+		t.Fatalf("handle document diagnostic: %v", err)
+	}
+	report := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+	items := report["items"].([]any)
+	if len(items) != 1 {
+		t.Fatalf("expected one diagnostic for the removed directive, got %+v", items)
+	}
+	deprecated := items[0].(map[string]any)
+	if deprecated["code"] != "deprecated-directive" || deprecated["severity"] != float64(1) {
+		t.Fatalf("expected deprecated-directive error, got %+v", deprecated)
+	}
+	if tags := deprecated["tags"].([]any); len(tags) != 1 || tags[0] != float64(2) {
+		t.Fatalf("expected Deprecated tag, got %+v", deprecated["tags"])
+	}
+
+	out.Reset()
+	diag, _ := json.Marshal(deprecated)
+	req = json.RawMessage(`{"textDocument":{"uri":"untitled:a.conf"},` +
+		`"range":{"start":{"line":3,"character":8},"end":{"line":3,"character":8}},` +
+		`"context":{"diagnostics":[` + string(diag) + `]}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/codeAction", Params: req}); err != nil {
+		t.Fatalf("handle codeAction: %v", err)
+	}
+	actions := readAllLSPMessages(t, out.Bytes())[0]["result"].([]any)
+	if len(actions) != 1 {
+		t.Fatalf("expected one quick fix, got %+v", actions)
+	}
+	action := actions[0].(map[string]any)
+	if action["title"] != "Replace header_set with set_header" || action["kind"] != "quickfix" || len(action["diagnostics"].([]any)) != 1 {
+		t.Fatalf("unexpected action: %+v", action)
+	}
+	edits := action["edit"].(map[string]any)["changes"].(map[string]any)["untitled:a.conf"].([]any)
+	edit := edits[0].(map[string]any)
+	start := edit["range"].(map[string]any)["start"].(map[string]any)
+	if edit["newText"] != "set_header" || start["line"] != float64(3) || start["character"] != float64(6) {
+		t.Fatalf("unexpected edit: %+v", edit)
+	}
+
+	out.Reset()
+	req = json.RawMessage(`{"textDocument":{"uri":"untitled:a.conf"},"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":3}},"context":{"diagnostics":[]}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/codeAction", Params: req}); err != nil {
+		t.Fatalf("handle codeAction: %v", err)
+	}
+	if actions := readAllLSPMessages(t, out.Bytes())[0]["result"].([]any); len(actions) != 0 {
+		t.Fatalf("expected no actions outside the directive, got %+v", actions)
+	}
+}
@@ -0,0 +1,368 @@
+package lsp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/r9s-ai/onr-lsp/internal/corespec"
+	"github.com/r9s-ai/onr-lsp/internal/httpheader"
+	"github.com/r9s-ai/onr-lsp/internal/jsonpath"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/onr-lsp/internal/migrate"
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+type documentDiagnosticParams struct {
+	TextDocument     textDocumentIdentifier `json:"textDocument"`
+	PreviousResultID string                 `json:"previousResultId,omitempty"`
+}
+
+type workspaceDiagnosticParams struct {
+	PreviousResultIDs  []previousResultID `json:"previousResultIds"`
+	PartialResultToken json.RawMessage    `json:"partialResultToken,omitempty"`
+}
+
+type previousResultID struct {
+	URI   string `json:"uri"`
+	Value string `json:"value"`
+}
+
+// documentDiagnosticReport is either a full report (Items set) or an
+// unchanged report (Items omitted) as defined by LSP 3.17.
+type documentDiagnosticReport struct {
+	Kind     string       `json:"kind"`
+	ResultID string       `json:"resultId,omitempty"`
+	Items    []Diagnostic `json:"items,omitempty"`
+}
+
+type workspaceDocumentDiagnosticReport struct {
+	Kind     string       `json:"kind"`
+	URI      string       `json:"uri"`
+	Version  *int         `json:"version"`
+	ResultID string       `json:"resultId,omitempty"`
+	Items    []Diagnostic `json:"items,omitempty"`
+}
+
+type workspaceDiagnosticReport struct {
+	Items []workspaceDocumentDiagnosticReport `json:"items"`
+}
+
+type progressParams struct {
+	Token json.RawMessage `json:"token"`
+	Value any             `json:"value"`
+}
+
+const (
+	reportKindFull      = "full"
+	reportKindUnchanged = "unchanged"
+)
+
+// pushDiagnostics publishes diagnostics for clients without pull support.
+// The changed document goes first; other open documents follow because
+// cross-file diagnostics may have changed with it.
+func (s *Server) pushDiagnostics(uri string) error {
+	if s.pullDiagnostics {
+		return nil
+	}
+	ws := s.loadWorkspace()
+	if err := s.publishDiagnostics(ws, uri); err != nil {
+		return err
+	}
+	for _, other := range s.openURIs() {
+		if other == uri {
+			continue
+		}
+		if err := s.publishDiagnostics(ws, other); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) publishDiagnostics(ws *workspace.Workspace, uri string) error {
+	if _, ok := s.docs[uri]; !ok {
+		return nil
+	}
+	diags, _ := s.documentDiagnostics(ws, uri)
+	params := publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diags,
+	}
+	return s.notify("textDocument/publishDiagnostics", params)
+}
+
+func (s *Server) publishAllDiagnostics() error {
+	ws := s.loadWorkspace()
+	for _, uri := range s.openURIs() {
+		if err := s.publishDiagnostics(ws, uri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshDiagnostics re-reports diagnostics after a configuration change,
+// asking pull clients to re-pull and pushing to the others.
+func (s *Server) refreshDiagnostics() error {
+	if s.pullDiagnostics {
+		if !s.refreshSupport {
+			return nil
+		}
+		return s.request("workspace/diagnostic/refresh", nil)
+	}
+	return s.publishAllDiagnostics()
+}
+
+func (s *Server) openURIs() []string {
+	uris := make([]string, 0, len(s.docs))
+	for uri := range s.docs {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// documentText returns the open document text or, for files that are not
+// open, the file content on disk.
+func (s *Server) documentText(uri string) (string, bool) {
+	if text, ok := s.docs[uri]; ok {
+		return text, true
+	}
+	path, ok := pathFromURI(uri)
+	if !ok {
+		return "", false
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// setDocument stores the text of an open document and applies it to the
+// cached workspace.
+func (s *Server) setDocument(uri, text string) {
+	s.docs[uri] = text
+	if path, ok := pathFromURI(uri); ok && s.ws != nil {
+		s.ws.SetOverlay(path, text)
+	}
+}
+
+// loadWorkspace returns the cached workspace, loading the workspace roots
+// with open documents overlaid on their files on disk when there is none.
+func (s *Server) loadWorkspace() *workspace.Workspace {
+	if s.ws != nil {
+		return s.ws
+	}
+	overlay := map[string]string{}
+	for uri, text := range s.docs {
+		if path, ok := pathFromURI(uri); ok {
+			overlay[path] = text
+		}
+	}
+	ws := workspace.New(overlay)
+	var files []string
+	if len(s.roots) > 0 {
+		var err error
+		files, err = workspace.CollectFiles(s.roots)
+		if err != nil {
+			s.logger.Printf("collect workspace files: %v", err)
+		}
+		for _, path := range files {
+			ws.Add(path)
+		}
+	}
+	for path := range overlay {
+		ws.Add(path)
+	}
+	for path, root := range s.activeRoots {
+		ws.SetActiveRoot(path, root)
+	}
+	s.ws, s.wsFiles = ws, files
+	return ws
+}
+
+// documentDiagnostics returns single-file diagnostics for uri plus the
+// cross-file diagnostics ws reports for it.
+func (s *Server) documentDiagnostics(ws *workspace.Workspace, uri string) ([]Diagnostic, bool) {
+	text, ok := s.documentText(uri)
+	if !ok {
+		return []Diagnostic{}, false
+	}
+	cfg := s.lintConfig(uri)
+	diags := lint.Apply(text, dsllang.CollectDiagnostics(uri, text), cfg)
+	diags = migrate.Supersede(diags, lint.Filter(text, migrate.Diagnostics(text), cfg))
+	diags = append(diags, lint.Filter(text, jsonpath.Diagnostics(text), cfg)...)
+	diags = append(diags, lint.Filter(text, httpheader.Diagnostics(text), cfg)...)
+	diags = append(diags, lint.Filter(text, corespec.Diagnostics(text, cfg.TargetCore), cfg)...)
+	if path, ok := pathFromURI(uri); ok {
+		ws.Add(path)
+		diags = append(diags, lint.Filter(text, ws.Diagnostics()[path], cfg)...)
+	}
+	return diags, true
+}
+
+func (s *Server) handleDocumentDiagnostic(id *json.RawMessage, params json.RawMessage) error {
+	var p documentDiagnosticParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return s.replyError(id, -32602, "invalid params for document diagnostic")
+	}
+	diags, _ := s.documentDiagnostics(s.loadWorkspace(), p.TextDocument.URI)
+	resultID := diagnosticsResultID(diags)
+	if p.PreviousResultID != "" && p.PreviousResultID == resultID {
+		return s.reply(id, documentDiagnosticReport{Kind: reportKindUnchanged, ResultID: resultID})
+	}
+	return s.reply(id, documentDiagnosticReport{Kind: reportKindFull, ResultID: resultID, Items: diags})
+}
+
+// handleWorkspaceDiagnostic reports every .conf file under the workspace
+// roots plus any open document outside them. With a partialResultToken each
+// report is streamed as a $/progress notification and the final response is
+// empty.
+func (s *Server) handleWorkspaceDiagnostic(id *json.RawMessage, params json.RawMessage) error {
+	var p workspaceDiagnosticParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return s.replyError(id, -32602, "invalid params for workspace diagnostic")
+	}
+	previous := make(map[string]string, len(p.PreviousResultIDs))
+	for _, prev := range p.PreviousResultIDs {
+		previous[prev.URI] = prev.Value
+	}
+	stream := len(p.PartialResultToken) > 0 && string(p.PartialResultToken) != "null"
+
+	out := workspaceDiagnosticReport{Items: []workspaceDocumentDiagnosticReport{}}
+	ws := s.loadWorkspace()
+	for _, uri := range s.workspaceURIs() {
+		diags, ok := s.documentDiagnostics(ws, uri)
+		if !ok {
+			continue
+		}
+		item := workspaceDocumentDiagnosticReport{
+			Kind:     reportKindFull,
+			URI:      uri,
+			ResultID: diagnosticsResultID(diags),
+			Items:    diags,
+		}
+		if prev, ok := previous[uri]; ok && prev == item.ResultID {
+			item.Kind = reportKindUnchanged
+			item.Items = nil
+		}
+		if !stream {
+			out.Items = append(out.Items, item)
+			continue
+		}
+		err := s.notify("$/progress", progressParams{
+			Token: p.PartialResultToken,
+			Value: workspaceDiagnosticReport{Items: []workspaceDocumentDiagnosticReport{item}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.reply(id, out)
+}
+
+func (s *Server) workspaceURIs() []string {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(s.docs))
+	add := func(uri string) {
+		if _, ok := seen[uri]; ok {
+			return
+		}
+		seen[uri] = struct{}{}
+		out = append(out, uri)
+	}
+	s.loadWorkspace()
+	for _, path := range s.wsFiles {
+		add(uriFromPath(path))
+	}
+	for _, uri := range s.openURIs() {
+		add(uri)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// diagnosticsResultID derives the result ID from the reported diagnostics, so
+// a report is "unchanged" exactly when the client already has the same items,
+// including changes caused by other files.
+func diagnosticsResultID(diags []Diagnostic) string {
+	b, err := json.Marshal(diags)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// lintConfig returns editor settings overlaid with the nearest project lint
+// config for uri, so the editor agrees with `onr-lsp check`.
+func (s *Server) lintConfig(uri string) lint.Config {
+	cfg := s.lintSettings
+	path, ok := pathFromURI(uri)
+	if !ok {
+		return cfg
+	}
+	dir := filepath.Dir(path)
+	project, ok := s.lintConfigs[dir]
+	if !ok {
+		project = s.loadProjectConfig(dir)
+		if s.lintConfigs == nil {
+			s.lintConfigs = map[string]lint.Config{}
+		}
+		s.lintConfigs[dir] = project
+	}
+	return cfg.Merge(project)
+}
+
+// loadProjectConfig reads the nearest project lint config above dir. An
+// invalid config is logged and ignored.
+func (s *Server) loadProjectConfig(dir string) lint.Config {
+	project, cfgPath, err := lint.LoadConfig(dir)
+	if err != nil {
+		s.logger.Printf("load lint config %s: %v", cfgPath, err)
+		return lint.Config{}
+	}
+	if project.TargetCore != "" {
+		if _, err := corespec.Resolve(project.TargetCore); err != nil {
+			s.logger.Printf("load lint config %s: targetCore: %v", cfgPath, err)
+			project.TargetCore = ""
+		}
+	}
+	return project
+}
+
+func workspaceRoots(p initializeParams) []string {
+	uris := make([]string, 0, len(p.WorkspaceFolders)+1)
+	for _, f := range p.WorkspaceFolders {
+		uris = append(uris, f.URI)
+	}
+	if len(uris) == 0 && p.RootURI != "" {
+		uris = append(uris, p.RootURI)
+	}
+	out := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		if path, ok := pathFromURI(uri); ok {
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+func pathFromURI(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+func uriFromPath(path string) string {
+	return workspace.FileURI(path)
+}
@@ -0,0 +1,308 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+)
+
+func newPullServer(t *testing.T, rootURI string) (*Server, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	s := NewServer(stringsReader(""), &out, log.New(io.Discard, "", 0))
+	rawID := json.RawMessage("1")
+	params, err := json.Marshal(map[string]any{
+		"rootUri": rootURI,
+		"capabilities": map[string]any{
+			"textDocument": map[string]any{"diagnostic": map[string]any{}},
+			"workspace":    map[string]any{"diagnostics": map[string]any{"refreshSupport": true}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal initialize params: %v", err)
+	}
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "initialize", Params: params}); err != nil {
+		t.Fatalf("handle initialize: %v", err)
+	}
+	msgs := readAllLSPMessages(t, out.Bytes())
+	caps := msgs[0]["result"].(map[string]any)["capabilities"].(map[string]any)
+	provider, ok := caps["diagnosticProvider"].(map[string]any)
+	if !ok || provider["interFileDependencies"] != true || provider["workspaceDiagnostics"] != true {
+		t.Fatalf("unexpected diagnosticProvider: %#v", caps["diagnosticProvider"])
+	}
+	out.Reset()
+	return s, &out
+}
+
+func TestPullDiagnostics_DocumentFullThenUnchanged(t *testing.T) {
+	s, out := newPullServer(t, "")
+
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: "untitled:a.conf", Text: "unknown_top foo;"}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("pull clients must not receive pushed diagnostics, got %d bytes", out.Len())
+	}
+
+	rawID := json.RawMessage("2")
+	req := json.RawMessage(`{"textDocument":{"uri":"untitled:a.conf"}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/diagnostic", Params: req}); err != nil {
+		t.Fatalf("handle document diagnostic: %v", err)
+	}
+	res := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+	if res["kind"] != "full" || len(res["items"].([]any)) != 1 {
+		t.Fatalf("unexpected full report: %+v", res)
+	}
+	resultID, _ := res["resultId"].(string)
+	if resultID == "" {
+		t.Fatalf("expected resultId in full report")
+	}
+
+	out.Reset()
+	req = json.RawMessage(`{"textDocument":{"uri":"untitled:a.conf"},"previousResultId":"` + resultID + `"}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/diagnostic", Params: req}); err != nil {
+		t.Fatalf("handle document diagnostic: %v", err)
+	}
+	res = readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+	if res["kind"] != "unchanged" || res["resultId"] != resultID {
+		t.Fatalf("expected unchanged report, got: %+v", res)
+	}
+}
+
+func TestPullDiagnostics_WorkspaceIncludesClosedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "closed.conf")
+	if err := os.WriteFile(path, []byte("unknown_top foo;\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	s, out := newPullServer(t, uriFromPath(dir))
+
+	rawID := json.RawMessage("3")
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "workspace/diagnostic", Params: json.RawMessage(`{"previousResultIds":[]}`)}); err != nil {
+		t.Fatalf("handle workspace diagnostic: %v", err)
+	}
+	res := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+	items := res["items"].([]any)
+	if len(items) != 1 {
+		t.Fatalf("expected one workspace report, got: %+v", items)
+	}
+	item := items[0].(map[string]any)
+	if item["uri"] != uriFromPath(path) || item["kind"] != "full" || len(item["items"].([]any)) != 1 {
+		t.Fatalf("unexpected workspace report: %+v", item)
+	}
+}
+
+func TestPullDiagnostics_WorkspaceStreamsPartialResults(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.conf", "b.conf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("unknown_top foo;\n"), 0o600); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	s, out := newPullServer(t, uriFromPath(dir))
+
+	rawID := json.RawMessage("4")
+	params := json.RawMessage(`{"previousResultIds":[],"partialResultToken":"tok"}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "workspace/diagnostic", Params: params}); err != nil {
+		t.Fatalf("handle workspace diagnostic: %v", err)
+	}
+	msgs := readAllLSPMessages(t, out.Bytes())
+	if len(msgs) != 3 || msgs[0]["method"] != "$/progress" || msgs[1]["method"] != "$/progress" {
+		t.Fatalf("expected two progress notifications and a response, got: %+v", msgs)
+	}
+	if items := msgs[2]["result"].(map[string]any)["items"].([]any); len(items) != 0 {
+		t.Fatalf("expected empty final response when streaming, got: %+v", items)
+	}
+}
+
+func TestPullDiagnostics_ConfigurationChangeRequestsRefresh(t *testing.T) {
+	s, out := newPullServer(t, "")
+	s.docs["untitled:a.conf"] = "unknown_top foo;"
+
+	params := json.RawMessage(`{"settings":{"onrLsp":{"lint":{"rules":{}}}}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "workspace/didChangeConfiguration", Params: params}); err != nil {
+		t.Fatalf("handle didChangeConfiguration: %v", err)
+	}
+	msgs := readAllLSPMessages(t, out.Bytes())
+	if len(msgs) != 1 || msgs[0]["method"] != "workspace/diagnostic/refresh" || msgs[0]["id"] == nil {
+		t.Fatalf("expected diagnostic refresh request, got: %+v", msgs)
+	}
+}
+
+func TestHandle_ConfigurationSectionsApplyIndependently(t *testing.T) {
+	s, _ := newPullServer(t, "")
+	s.lintSettings = lint.Config{TargetCore: "v1.15.x"}
+
+	params := json.RawMessage(`{"settings":{"onrLsp":{"lint":{"rules":{"no-such-rule":"off"}},"exclude":["vendor"],"scaffold":{"family":"anthropic"}}}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "workspace/didChangeConfiguration", Params: params}); err == nil {
+		t.Fatalf("expected the unknown lint rule to be reported")
+	}
+	if len(s.exclude) != 1 || s.exclude[0] != "vendor" || s.scaffold.Family != "anthropic" {
+		t.Fatalf("invalid lint settings dropped other sections: exclude=%v scaffold=%+v", s.exclude, s.scaffold)
+	}
+	if s.lintSettings.TargetCore != "v1.15.x" {
+		t.Fatalf("invalid lint settings replaced the previous ones: %+v", s.lintSettings)
+	}
+}
+
+func TestPullDiagnostics_CrossFileUsesOpenBuffers(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"providers/a.conf": "provider \"openai\" {\n}\n",
+		"providers/b.conf": "provider \"other\" {\n}\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	s, out := newPullServer(t, uriFromPath(dir))
+	bURI := uriFromPath(filepath.Join(dir, "providers", "b.conf"))
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: bURI, Text: "provider \"openai\" {\n}\n"}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+
+	rawID := json.RawMessage("2")
+	aURI := uriFromPath(filepath.Join(dir, "providers", "a.conf"))
+	req := json.RawMessage(`{"textDocument":{"uri":"` + aURI + `"}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/diagnostic", Params: req}); err != nil {
+		t.Fatalf("handle document diagnostic: %v", err)
+	}
+	res := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+	var found map[string]any
+	for _, item := range res["items"].([]any) {
+		d := item.(map[string]any)
+		if d["code"] == "duplicate-provider" {
+			found = d
+		}
+	}
+	if found == nil {
+		t.Fatalf("expected duplicate-provider from unsaved buffer, got %+v", res["items"])
+	}
+	related := found["relatedInformation"].([]any)
+	loc := related[0].(map[string]any)["location"].(map[string]any)
+	if len(related) != 1 || loc["uri"] != bURI {
+		t.Fatalf("unexpected relatedInformation: %+v", related)
+	}
+}
+
+func TestPullDiagnostics_CachedWorkspaceFollowsEditsAndWatchedFiles(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"providers/a.conf": "provider \"openai\" {\n}\n",
+		"providers/b.conf": "provider \"other\" {\n}\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	aURI := uriFromPath(filepath.Join(dir, "providers", "a.conf"))
+	bURI := uriFromPath(filepath.Join(dir, "providers", "b.conf"))
+	// duplicates returns how many other files declare a.conf's provider.
+	duplicates := func() int {
+		t.Helper()
+		out.Reset()
+		rawID := json.RawMessage("2")
+		req := json.RawMessage(`{"textDocument":{"uri":"` + aURI + `"}}`)
+		if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/diagnostic", Params: req}); err != nil {
+			t.Fatalf("handle document diagnostic: %v", err)
+		}
+		res := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+		items, _ := res["items"].([]any)
+		for _, item := range items {
+			if d := item.(map[string]any); d["code"] == "duplicate-provider" {
+				return len(d["relatedInformation"].([]any))
+			}
+		}
+		return 0
+	}
+
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: bURI, Text: "provider \"other\" {\n}\n"}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+	if n := duplicates(); n != 0 {
+		t.Fatalf("expected no duplicate provider, got %d", n)
+	}
+	ws := s.ws
+
+	change := json.RawMessage(`{"textDocument":{"uri":"` + bURI + `"},"contentChanges":[{"text":"provider \"openai\" {\n}\n"}]}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didChange", Params: change}); err != nil {
+		t.Fatalf("handle didChange: %v", err)
+	}
+	if n := duplicates(); n != 1 || s.ws != ws {
+		t.Fatalf("expected the edit applied to the cached workspace, got %d other files", n)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "providers", "c.conf"), []byte("provider \"openai\" {\n}\n"), 0o600); err != nil {
+		t.Fatalf("write c.conf: %v", err)
+	}
+	if n := duplicates(); n != 1 {
+		t.Fatalf("files on disk must not be re-read before a watched-file event, got %d other files", n)
+	}
+	out.Reset()
+	watched := json.RawMessage(`{"changes":[{"uri":"` + uriFromPath(filepath.Join(dir, "providers", "c.conf")) + `","type":1}]}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "workspace/didChangeWatchedFiles", Params: watched}); err != nil {
+		t.Fatalf("handle didChangeWatchedFiles: %v", err)
+	}
+	if msgs := readAllLSPMessages(t, out.Bytes()); len(msgs) != 1 || msgs[0]["method"] != "workspace/diagnostic/refresh" {
+		t.Fatalf("expected diagnostic refresh request, got: %+v", msgs)
+	}
+	if n := duplicates(); n != 2 {
+		t.Fatalf("expected b.conf and c.conf as other declarations, got %d", n)
+	}
+	if s.ws == ws {
+		t.Fatalf("expected the workspace to be loaded again")
+	}
+}
+
+func TestPullDiagnostics_ProjectLintConfigReloadsOnWatchedChange(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"providers/a.conf": "unknown_top foo;\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	aURI := uriFromPath(filepath.Join(dir, "providers", "a.conf"))
+	// unknown returns how many unknown-directive diagnostics a.conf has.
+	unknown := func() int {
+		t.Helper()
+		out.Reset()
+		rawID := json.RawMessage("2")
+		req := json.RawMessage(`{"textDocument":{"uri":"` + aURI + `"}}`)
+		if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/diagnostic", Params: req}); err != nil {
+			t.Fatalf("handle document diagnostic: %v", err)
+		}
+		n := 0
+		for _, item := range readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)["items"].([]any) {
+			if item.(map[string]any)["code"] == lint.RuleUnknownDirective {
+				n++
+			}
+		}
+		return n
+	}
+
+	if n := unknown(); n != 1 {
+		t.Fatalf("expected one unknown-directive diagnostic, got %d", n)
+	}
+	cfgPath := filepath.Join(dir, lint.ConfigFileName)
+	if err := os.WriteFile(cfgPath, []byte(`{"rules": {"unknown-directive": "off"}}`), 0o600); err != nil {
+		t.Fatalf("write lint config: %v", err)
+	}
+	if n := unknown(); n != 1 {
+		t.Fatalf("lint config must be cached until a watched-file event, got %d", n)
+	}
+	watched := json.RawMessage(`{"changes":[{"uri":"` + uriFromPath(cfgPath) + `","type":1}]}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "workspace/didChangeWatchedFiles", Params: watched}); err != nil {
+		t.Fatalf("handle didChangeWatchedFiles: %v", err)
+	}
+	if n := unknown(); n != 0 {
+		t.Fatalf("expected the new lint config to turn the rule off, got %d", n)
+	}
+}
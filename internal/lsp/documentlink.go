@@ -0,0 +1,88 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// maxLinkTooltipFiles caps the files listed in an include link tooltip.
+const maxLinkTooltipFiles = 10
+
+type documentLinkParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type documentLinkOptions struct {
+	ResolveProvider bool `json:"resolveProvider"`
+}
+
+// DocumentLink makes an include argument open its target.
+type DocumentLink struct {
+	Range   Range  `json:"range"`
+	Target  string `json:"target,omitempty"`
+	Tooltip string `json:"tooltip,omitempty"`
+}
+
+// handleDocumentLink links include arguments to the included file, or to the
+// directory a directory or glob include expands in. Includes that resolve to
+// nothing are left unlinked; workspace diagnostics report them.
+func (s *Server) handleDocumentLink(id *json.RawMessage, params json.RawMessage) error {
+	var p documentLinkParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return s.replyError(id, -32602, "invalid params for document link")
+	}
+	links := []DocumentLink{}
+	path, ok := pathFromURI(p.TextDocument.URI)
+	if !ok {
+		return s.reply(id, links)
+	}
+	ws := s.loadWorkspace()
+	ws.Add(path)
+	doc, ok := ws.Document(path)
+	if !ok {
+		return s.reply(id, links)
+	}
+	dir := filepath.Dir(path)
+	for _, inc := range ws.Includes(doc) {
+		if inc.Err != nil || len(inc.Targets) == 0 {
+			continue
+		}
+		full := inc.Pattern
+		if !filepath.IsAbs(full) {
+			full = filepath.Join(dir, full)
+		}
+		target := full
+		if strings.ContainsAny(full, "*?[") {
+			target = globDir(full)
+		}
+		link := DocumentLink{Range: inc.Range(), Target: uriFromPath(target)}
+		if len(inc.Targets) > 1 || inc.Targets[0] != full {
+			link.Tooltip = includeTooltip(dir, inc.Targets)
+		}
+		links = append(links, link)
+	}
+	return s.reply(id, links)
+}
+
+// globDir returns the directories of a glob before its first wildcard.
+func globDir(pattern string) string {
+	i := strings.IndexAny(pattern, "*?[")
+	return filepath.Dir(pattern[:i+1])
+}
+
+func includeTooltip(dir string, targets []string) string {
+	names := make([]string, 0, min(len(targets), maxLinkTooltipFiles))
+	for _, t := range targets[:min(len(targets), maxLinkTooltipFiles)] {
+		rel, err := filepath.Rel(dir, t)
+		if err != nil {
+			rel = t
+		}
+		names = append(names, filepath.ToSlash(rel))
+	}
+	if extra := len(targets) - len(names); extra > 0 {
+		names = append(names, fmt.Sprintf("and %d more", extra))
+	}
+	return fmt.Sprintf("Includes %d file(s): %s", len(targets), strings.Join(names, ", "))
+}
@@ -0,0 +1,46 @@
+package lsp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+)
+
+func TestHandle_DocumentLinksForIncludes(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"onr.conf":           "include \"providers/a.conf\";\ninclude modes/*.conf;\ninclude providers;\ninclude missing.conf;\n",
+		"providers/a.conf":   "provider \"a\" {\n}\n",
+		"providers/b.conf":   "provider \"b\" {\n}\n",
+		"modes/usage.conf":   "syntax \"next-router/0.1\";\n",
+		"modes/finish.conf":  "syntax \"next-router/0.1\";\n",
+		"modes/README.notes": "not included\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	rawID := json.RawMessage("2")
+	req := json.RawMessage(`{"textDocument":{"uri":"` + uriFromPath(filepath.Join(dir, "onr.conf")) + `"}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/documentLink", Params: req}); err != nil {
+		t.Fatalf("handle documentLink: %v", err)
+	}
+	links := readAllLSPMessages(t, out.Bytes())[0]["result"].([]any)
+	if len(links) != 3 {
+		t.Fatalf("expected links for the three resolved includes, got %+v", links)
+	}
+	for i, want := range []struct {
+		line, start, end float64
+		target, tooltip  string
+	}{
+		{line: 0, start: 8, end: 26, target: uriFromPath(filepath.Join(dir, "providers", "a.conf"))},
+		{line: 1, start: 8, end: 20, target: uriFromPath(filepath.Join(dir, "modes")), tooltip: "Includes 2 file(s): modes/finish.conf, modes/usage.conf"},
+		{line: 2, start: 8, end: 17, target: uriFromPath(filepath.Join(dir, "providers")), tooltip: "Includes 2 file(s): providers/a.conf, providers/b.conf"},
+	} {
+		link := links[i].(map[string]any)
+		rng := link["range"].(map[string]any)
+		start, end := rng["start"].(map[string]any), rng["end"].(map[string]any)
+		tooltip, _ := link["tooltip"].(string)
+		if start["line"] != want.line || start["character"] != want.start || end["character"] != want.end || link["target"] != want.target || tooltip != want.tooltip {
+			t.Fatalf("link %d: got %+v", i, link)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/httpheader"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// headerArgPrefix splits the line before the cursor into the directive name,
+// its complete arguments and the argument being typed.
+var headerArgPrefix = regexp.MustCompile(`^\s*([a-z_]+)((?:\s+[^\s;]+)*?)\s+(["']?)([^"'\s;]*)$`)
+
+// completeHeader completes well-known header names where a directive takes
+// a header. ok is false outside header-name arguments.
+func completeHeader(text string, pos Position) ([]CompletionItem, bool) {
+	line := lineAt(text, pos.Line)
+	if pos.Character < 0 || pos.Character > len(line) {
+		return nil, false
+	}
+	m := headerArgPrefix.FindStringSubmatch(line[:pos.Character])
+	if m == nil {
+		return nil, false
+	}
+	index, ok := httpheader.ArgIndex(m[1])
+	if !ok || len(strings.Fields(m[2])) != index {
+		return nil, false
+	}
+	typed := m[4]
+	edit := Range{
+		Start: Position{Line: pos.Line, Character: pos.Character - len(typed)},
+		End:   pos,
+	}
+	items := []CompletionItem{}
+	for _, h := range httpheader.Known() {
+		if !strings.HasPrefix(strings.ToLower(h.Name), strings.ToLower(typed)) {
+			continue
+		}
+		detail := "HTTP header"
+		if h.Vendor != "" {
+			detail = h.Vendor + " header"
+		}
+		items = append(items, CompletionItem{
+			Label:         h.Name,
+			Kind:          12,
+			Detail:        detail,
+			Documentation: h.Doc,
+			TextEdit:      &TextEdit{Range: edit, NewText: h.Name},
+		})
+	}
+	return items, true
+}
+
+// hoverHeader documents the well-known header under pos.
+func hoverHeader(text string, pos Position) (*dsllang.Hover, bool) {
+	for _, a := range httpheader.Args(dslast.Parse(text)) {
+		r := a.Arg.Range
+		if positionBefore(pos, r.Start) || positionBefore(r.End, pos) {
+			continue
+		}
+		h, ok := httpheader.Lookup(a.Name)
+		if !ok {
+			return nil, false
+		}
+		return &dsllang.Hover{
+			Contents: dsllang.MarkupContent{Kind: "markdown", Value: h.Markdown()},
+			Range:    &r,
+		}, true
+	}
+	return nil, false
+}
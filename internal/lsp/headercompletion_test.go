@@ -0,0 +1,75 @@
+package lsp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCompleteHeaderNames(t *testing.T) {
+	text := "provider \"x\" {\n  defaults {\n    request {\n      set_header \"anth\n      json_set_header_values \"$.a\" Open\n      set_header \"X-A\" \n    }\n    auth {\n      auth_header_key \n    }\n  }\n}\n"
+	lines := strings.Split(text, "\n")
+	at := func(line int) Position { return Position{Line: line, Character: len(lines[line])} }
+
+	items, ok := completeHeader(text, at(3))
+	if !ok {
+		t.Fatalf("expected header completion in set_header")
+	}
+	var labels []string
+	for _, it := range items {
+		labels = append(labels, it.Label)
+	}
+	if got := strings.Join(labels, ","); got != "anthropic-beta,anthropic-dangerous-direct-browser-access,anthropic-version" {
+		t.Fatalf("unexpected set_header completion: %s", got)
+	}
+	if items[2].Detail != "Anthropic header" || items[2].TextEdit.Range.Start.Character != len(`      set_header "`) {
+		t.Fatalf("unexpected item: %+v", items[2])
+	}
+
+	items, ok = completeHeader(text, at(4))
+	if !ok || len(items) != 3 || items[0].Label != "OpenAI-Beta" {
+		t.Fatalf("unexpected json_set_header_values completion: %+v", items)
+	}
+
+	if _, ok := completeHeader(text, at(5)); ok {
+		t.Fatalf("set_header value must not complete header names")
+	}
+
+	items, ok = completeHeader(text, at(8))
+	if !ok || len(items) < 20 {
+		t.Fatalf("expected all known headers for auth_header_key, got %d", len(items))
+	}
+}
+
+func TestHandle_HoverWellKnownHeader(t *testing.T) {
+	s, out := newPullServer(t, "")
+	docURI := "file:///tmp/providers/x.conf"
+	text := "provider \"x\" {\n  defaults {\n    request {\n      set_header \"Anthropic-Version\" \"2023-06-01\";\n    }\n  }\n}\n"
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: docURI, Text: text}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+	hover := func(character int) map[string]any {
+		t.Helper()
+		out.Reset()
+		rawID := json.RawMessage("5")
+		req, _ := json.Marshal(hoverParams{TextDocument: textDocumentIdentifier{URI: docURI}, Position: Position{Line: 3, Character: character}})
+		if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/hover", Params: req}); err != nil {
+			t.Fatalf("handle hover: %v", err)
+		}
+		result, _ := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+		return result
+	}
+
+	result := hover(len(`      set_header "Anth`))
+	value, _ := result["contents"].(map[string]any)["value"].(string)
+	if !strings.HasPrefix(value, "`anthropic-version` header (Anthropic)") || !strings.Contains(value, "2023-06-01") {
+		t.Fatalf("unexpected header hover: %q", value)
+	}
+
+	result = hover(len(`      set_`))
+	value, _ = result["contents"].(map[string]any)["value"].(string)
+	if !strings.Contains(value, "set_header") {
+		t.Fatalf("directive hover must still work: %q", value)
+	}
+}
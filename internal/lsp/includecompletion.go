@@ -0,0 +1,138 @@
+package lsp
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	completionKindFile   = 17
+	completionKindFolder = 19
+)
+
+// includeArgPrefix matches an include argument being typed, quoted or not.
+var includeArgPrefix = regexp.MustCompile(`^\s*include\s+("?)([^";\s]*)$`)
+
+// excludeSettingsFrom returns the glob patterns of onrLsp.exclude and the
+// enabled patterns of files.exclude.
+func excludeSettingsFrom(settings json.RawMessage) []string {
+	var raw struct {
+		OnrLsp struct {
+			Exclude []string `json:"exclude"`
+		} `json:"onrLsp"`
+		Files struct {
+			Exclude map[string]bool `json:"exclude"`
+		} `json:"files"`
+	}
+	if len(settings) > 0 {
+		_ = json.Unmarshal(settings, &raw)
+	}
+	out := append([]string(nil), raw.OnrLsp.Exclude...)
+	for pattern, on := range raw.Files.Exclude {
+		if on {
+			out = append(out, pattern)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// completeInclude lists .conf files and directories for an include argument,
+// relative to the document's directory. ok is false outside include
+// arguments.
+func (s *Server) completeInclude(uri, text string, pos Position) ([]CompletionItem, bool) {
+	line := lineAt(text, pos.Line)
+	if pos.Character < 0 || pos.Character > len(line) {
+		return nil, false
+	}
+	m := includeArgPrefix.FindStringSubmatch(line[:pos.Character])
+	if m == nil {
+		return nil, false
+	}
+	docPath, ok := pathFromURI(uri)
+	if !ok {
+		return nil, false
+	}
+	typed := m[2]
+	dirPart, partial := "", typed
+	if i := strings.LastIndex(typed, "/"); i >= 0 {
+		dirPart, partial = typed[:i+1], typed[i+1:]
+	}
+	dir := filepath.FromSlash(dirPart)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(docPath), dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []CompletionItem{}, true
+	}
+	edit := Range{
+		Start: Position{Line: pos.Line, Character: pos.Character - len(partial)},
+		End:   pos,
+	}
+	items := []CompletionItem{}
+	for _, e := range entries {
+		name := e.Name()
+		full := filepath.Join(dir, name)
+		if strings.HasPrefix(name, ".") || !strings.HasPrefix(name, partial) || full == docPath || s.excluded(full) {
+			continue
+		}
+		item := CompletionItem{Label: name, Kind: completionKindFile, Detail: "include file"}
+		if e.IsDir() {
+			item = CompletionItem{Label: name + "/", Kind: completionKindFolder, Detail: "include directory"}
+		} else if filepath.Ext(name) != ".conf" {
+			continue
+		}
+		item.TextEdit = &TextEdit{Range: edit, NewText: item.Label}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind == completionKindFolder
+		}
+		return items[i].Label < items[j].Label
+	})
+	return items, true
+}
+
+// excluded reports whether path matches an exclude glob, relative to the
+// workspace root that contains it.
+func (s *Server) excluded(p string) bool {
+	if len(s.exclude) == 0 {
+		return false
+	}
+	for _, root := range s.roots {
+		rel, err := filepath.Rel(root, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		for _, pattern := range s.exclude {
+			if matchGlob(pattern, filepath.ToSlash(rel)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated relative path against a glob where
+// "**/" matches any number of leading directories, as in files.exclude.
+func matchGlob(pattern, rel string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if rest, ok := strings.CutPrefix(pattern, "**/"); ok {
+		parts := strings.Split(rel, "/")
+		for i := range parts {
+			if matchGlob(rest, strings.Join(parts[i:], "/")) {
+				return true
+			}
+		}
+		return false
+	}
+	ok, _ := path.Match(pattern, rel)
+	return ok
+}
@@ -0,0 +1,67 @@
+package lsp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+)
+
+func TestHandle_CompletionForIncludePaths(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"onr.conf":              "",
+		"providers/a.conf":      "provider \"a\" {\n}\n",
+		"providers/notes.txt":   "not DSL\n",
+		"modes/usage.conf":      "syntax \"next-router/0.1\";\n",
+		"vendor/x.conf":         "syntax \"next-router/0.1\";\n",
+		".git/config.conf":      "",
+		"presets.conf":          "syntax \"next-router/0.1\";\n",
+		"providers/old/b.conf":  "provider \"b\" {\n}\n",
+		"providers/azure.conf":  "provider \"azure\" {\n}\n",
+		"providers/anthro.conf": "provider \"anthro\" {\n}\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	docURI := uriFromPath(filepath.Join(dir, "onr.conf"))
+	text := "include ;\ninclude \"providers/a\";\n"
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: docURI, Text: text}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+	settings := json.RawMessage(`{"settings":{"onrLsp":{"exclude":["vendor"]},"files":{"exclude":{"**/old":true,"**/modes":false}}}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "workspace/didChangeConfiguration", Params: settings}); err != nil {
+		t.Fatalf("handle didChangeConfiguration: %v", err)
+	}
+
+	complete := func(line, character int) ([]string, []any) {
+		t.Helper()
+		out.Reset()
+		rawID := json.RawMessage("3")
+		req, _ := json.Marshal(completionParams{TextDocument: textDocumentIdentifier{URI: docURI}, Position: Position{Line: line, Character: character}})
+		if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/completion", Params: req}); err != nil {
+			t.Fatalf("handle completion: %v", err)
+		}
+		items := readAllLSPMessages(t, out.Bytes())[0]["result"].([]any)
+		labels := make([]string, 0, len(items))
+		for _, item := range items {
+			labels = append(labels, item.(map[string]any)["label"].(string))
+		}
+		return labels, items
+	}
+
+	labels, _ := complete(0, len("include "))
+	if got := strings.Join(labels, ","); got != "modes/,providers/,presets.conf" {
+		t.Fatalf("unexpected top-level include completion: %s", got)
+	}
+
+	labels, items := complete(1, len(`include "providers/a`))
+	if got := strings.Join(labels, ","); got != "a.conf,anthro.conf,azure.conf" {
+		t.Fatalf("unexpected nested include completion: %s", got)
+	}
+	edit := items[0].(map[string]any)["textEdit"].(map[string]any)
+	start := edit["range"].(map[string]any)["start"].(map[string]any)
+	if edit["newText"] != "a.conf" || start["character"] != float64(len(`include "providers/`)) {
+		t.Fatalf("unexpected text edit: %+v", edit)
+	}
+}
@@ -0,0 +1,113 @@
+package lsp
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/jsonpath"
+)
+
+const completionKindField = 5
+
+var (
+	// pathDirectivePrefix splits the line before the cursor into the
+	// directive name and its arguments.
+	pathDirectivePrefix = regexp.MustCompile(`^\s*([a-z_]+)(\s.*)$`)
+	// positionalPathPrefix matches a positional path argument being typed.
+	positionalPathPrefix = regexp.MustCompile(`^\s+["']?([^"'\s;]*)$`)
+	// keyPathPrefix matches a key=value path argument being typed.
+	keyPathPrefix = regexp.MustCompile(`\s([a-z_]+)\s*=\s*["']?([^"'\s;]*)$`)
+)
+
+// completePath completes the segments of a JSONPath argument that reads the
+// upstream response, from the shapes of the API families the enclosing
+// provider maps from. ok is false outside such arguments.
+func completePath(text string, pos Position) ([]CompletionItem, bool) {
+	line := lineAt(text, pos.Line)
+	if pos.Character < 0 || pos.Character > len(line) {
+		return nil, false
+	}
+	typed, ok := pathArgTyped(line[:pos.Character])
+	if !ok {
+		return nil, false
+	}
+	items := []CompletionItem{}
+	root, partial := "", typed
+	switch {
+	case strings.HasPrefix(typed, "$."):
+		partial = typed[strings.LastIndex(typed, ".")+1:]
+	case strings.HasPrefix("$.", typed):
+		root = "$."
+	default:
+		return items, true
+	}
+	edit := Range{
+		Start: Position{Line: pos.Line, Character: pos.Character - len(partial)},
+		End:   pos,
+	}
+	query := typed
+	if root != "" {
+		query = root
+	}
+	for _, c := range jsonpath.Complete(query, families(text, pos)) {
+		if root == "" && !strings.HasPrefix(c.Name, partial) {
+			continue
+		}
+		titles := make([]string, 0, len(c.Families))
+		for _, f := range c.Families {
+			shape, _ := jsonpath.LookupShape(f)
+			titles = append(titles, shape.Title)
+		}
+		detail := strings.Join(titles, ", ")
+		if c.Array {
+			detail = "array · " + detail
+		}
+		items = append(items, CompletionItem{
+			Label:         c.Name,
+			Kind:          completionKindField,
+			Detail:        detail,
+			Documentation: c.Doc,
+			TextEdit:      &TextEdit{Range: edit, NewText: root + c.Name},
+		})
+	}
+	return items, true
+}
+
+// families returns the API families for a path at pos, from the blocks
+// that enclose it.
+func families(text string, pos Position) []string {
+	var parents []*dslast.Statement
+	dslast.Walk(dslast.Parse(text), func(stmt *dslast.Statement, _ []*dslast.Statement) bool {
+		if !stmt.IsBlock() || positionBefore(pos, stmt.Block.Open.End) {
+			return false
+		}
+		if stmt.Block.Closed && positionBefore(stmt.Block.Close.Start, pos) {
+			return false
+		}
+		parents = append(parents, stmt)
+		return true
+	})
+	return jsonpath.Families(parents)
+}
+
+// pathArgTyped returns the path typed so far when linePrefix ends inside a
+// response path argument.
+func pathArgTyped(linePrefix string) (string, bool) {
+	m := pathDirectivePrefix.FindStringSubmatch(linePrefix)
+	if m == nil || !jsonpath.IsResponseDirective(m[1]) {
+		return "", false
+	}
+	keys := jsonpath.PathKeys(m[1])
+	if keys == nil {
+		if p := positionalPathPrefix.FindStringSubmatch(m[2]); p != nil {
+			return p[1], true
+		}
+		return "", false
+	}
+	if p := keyPathPrefix.FindStringSubmatch(m[2]); p != nil && slices.Contains(keys, p[1]) {
+		return p[2], true
+	}
+	return "", false
+}
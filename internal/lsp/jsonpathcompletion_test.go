@@ -0,0 +1,80 @@
+package lsp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHandle_CompletionForJSONPathArguments(t *testing.T) {
+	s, out := newPullServer(t, "")
+	docURI := "file:///tmp/providers/acme.conf"
+	text := `provider "acme" {
+  defaults {
+    metrics {
+      usage_extract custom;
+      input_tokens_path "$.usage.
+      usage_fact output token path="$.
+    }
+  }
+  match api = "chat.completions" {
+    response {
+      resp_map anthropic_to_openai_chat;
+    }
+  }
+}
+usage_mode "m" {
+  finish_reason_path "$.candidates[0].fin
+  id_path "$.
+}
+`
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: docURI, Text: text}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+	lines := strings.Split(text, "\n")
+	complete := func(line int) ([]string, []any) {
+		t.Helper()
+		out.Reset()
+		rawID := json.RawMessage("4")
+		req, _ := json.Marshal(completionParams{TextDocument: textDocumentIdentifier{URI: docURI}, Position: Position{Line: line, Character: len(lines[line])}})
+		if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/completion", Params: req}); err != nil {
+			t.Fatalf("handle completion: %v", err)
+		}
+		items, _ := readAllLSPMessages(t, out.Bytes())[0]["result"].([]any)
+		labels := make([]string, 0, len(items))
+		for _, item := range items {
+			labels = append(labels, item.(map[string]any)["label"].(string))
+		}
+		return labels, items
+	}
+
+	labels, items := complete(4)
+	if got := strings.Join(labels, ","); got != "cache_creation_input_tokens,cache_read_input_tokens,input_tokens,output_tokens" {
+		t.Fatalf("unexpected anthropic usage completion: %s", got)
+	}
+	item := items[2].(map[string]any)
+	if item["detail"] != "Anthropic Messages" {
+		t.Fatalf("unexpected detail: %v", item["detail"])
+	}
+	edit := item["textEdit"].(map[string]any)
+	start := edit["range"].(map[string]any)["start"].(map[string]any)
+	if edit["newText"] != "input_tokens" || int(start["character"].(float64)) != len(lines[4]) {
+		t.Fatalf("unexpected edit: %v", edit)
+	}
+
+	labels, _ = complete(5)
+	if !strings.Contains(strings.Join(labels, ","), "stop_reason") || strings.Contains(strings.Join(labels, ","), "choices") {
+		t.Fatalf("unexpected usage_fact root completion: %v", labels)
+	}
+
+	labels, _ = complete(15)
+	if got := strings.Join(labels, ","); got != "finishReason" {
+		t.Fatalf("unexpected preset completion: %s", got)
+	}
+
+	labels, _ = complete(16)
+	if strings.Contains(strings.Join(labels, ","), "usage") {
+		t.Fatalf("endpoint paths must not complete response fields: %v", labels)
+	}
+}
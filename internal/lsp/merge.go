@@ -0,0 +1,50 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/r9s-ai/onr-lsp/internal/merge"
+)
+
+// errRequestFailed is the LSP 3.17 RequestFailed error code.
+const errRequestFailed = -32803
+
+type mergedDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+// mergedDocumentResult answers onr/mergedDocument. URI is the root config
+// that was merged, which differs from the request for included fragments.
+type mergedDocumentResult struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+// handleMergedDocument flattens the document's active root config, or the
+// document itself when no root includes it, using open buffers.
+func (s *Server) handleMergedDocument(id *json.RawMessage, params json.RawMessage) error {
+	var p mergedDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return s.replyError(id, -32602, "invalid params for merged document")
+	}
+	path, ok := pathFromURI(p.TextDocument.URI)
+	if !ok {
+		return s.replyError(id, -32602, "merged document requires a file URI")
+	}
+	ws := s.loadWorkspace()
+	ws.Add(path)
+	if root, ok := ws.ActiveRoot(path); ok {
+		path = root.Path
+	}
+	overlay := map[string]string{}
+	for uri, text := range s.docs {
+		if p, ok := pathFromURI(uri); ok {
+			overlay[p] = text
+		}
+	}
+	text, err := merge.Merge(path, merge.Options{Overlay: overlay})
+	if err != nil {
+		return s.replyError(id, errRequestFailed, err.Error())
+	}
+	return s.reply(id, mergedDocumentResult{URI: uriFromPath(path), Text: text})
+}
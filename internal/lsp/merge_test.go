@@ -0,0 +1,54 @@
+package lsp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+)
+
+func TestHandle_MergedDocumentUsesActiveRootAndBuffers(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"onr.conf":         "include providers;\n",
+		"providers/p.conf": "provider \"disk\" {\n}\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	docURI := uriFromPath(filepath.Join(dir, "providers", "p.conf"))
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: docURI, Text: "provider \"buffer\" {\n}\n"}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+
+	rawID := json.RawMessage("2")
+	req := json.RawMessage(`{"textDocument":{"uri":"` + docURI + `"}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "onr/mergedDocument", Params: req}); err != nil {
+		t.Fatalf("handle mergedDocument: %v", err)
+	}
+	res := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+	if res["uri"] != uriFromPath(filepath.Join(dir, "onr.conf")) {
+		t.Fatalf("expected root config to be merged, got %v", res["uri"])
+	}
+	if text := res["text"].(string); !strings.Contains(text, "# from: providers/p.conf\nprovider \"buffer\"") {
+		t.Fatalf("unexpected merged text:\n%s", text)
+	}
+}
+
+func TestHandle_MergedDocumentReportsCycle(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"onr.conf": "include a.conf;\n",
+		"a.conf":   "include onr.conf;\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	rawID := json.RawMessage("2")
+	req := json.RawMessage(`{"textDocument":{"uri":"` + uriFromPath(filepath.Join(dir, "onr.conf")) + `"}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "onr/mergedDocument", Params: req}); err != nil {
+		t.Fatalf("handle mergedDocument: %v", err)
+	}
+	msg := readAllLSPMessages(t, out.Bytes())[0]
+	e, ok := msg["error"].(map[string]any)
+	if !ok || !strings.Contains(e["message"].(string), "include cycle") {
+		t.Fatalf("expected include cycle error, got %+v", msg)
+	}
+}
@@ -0,0 +1,106 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+)
+
+// renameFilters select DSL files and any folder, since moving a folder moves
+// the files includes point at.
+var renameFilters = []fileOperationFilter{
+	{Scheme: "file", Pattern: fileOperationPattern{Glob: "**/*.conf", Matches: "file"}},
+	{Scheme: "file", Pattern: fileOperationPattern{Glob: "**", Matches: "folder"}},
+}
+
+const messageTypeWarning = 2
+
+type fileRename struct {
+	OldURI string `json:"oldUri"`
+	NewURI string `json:"newUri"`
+}
+
+type renameFilesParams struct {
+	Files []fileRename `json:"files"`
+}
+
+type showMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
+
+func renamesFrom(p renameFilesParams) []workspace.Rename {
+	var out []workspace.Rename
+	for _, f := range p.Files {
+		oldPath, ok1 := pathFromURI(f.OldURI)
+		newPath, ok2 := pathFromURI(f.NewURI)
+		if ok1 && ok2 {
+			out = append(out, workspace.Rename{Old: oldPath, New: newPath})
+		}
+	}
+	return out
+}
+
+// handleWillRenameFiles rewrites include arguments so they keep pointing at
+// renamed files, and warns about includes that will lose files.
+func (s *Server) handleWillRenameFiles(id *json.RawMessage, params json.RawMessage) error {
+	var p renameFilesParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return s.replyError(id, -32602, "invalid params for willRenameFiles")
+	}
+	renames := renamesFrom(p)
+	if len(renames) == 0 {
+		return s.reply(id, nil)
+	}
+	edits, dangling := s.loadWorkspace().RenameIncludes(renames)
+	if len(dangling) > 0 {
+		lines := make([]string, 0, len(dangling))
+		for _, d := range dangling {
+			pos := d.Include.Stmt.Args[0].Range.Start
+			lines = append(lines, fmt.Sprintf("%s:%d: include %q will no longer include %s", filepath.Base(d.Path), pos.Line+1, d.Include.Pattern, filepath.Base(d.Target)))
+		}
+		if err := s.notify("window/showMessage", showMessageParams{
+			Type:    messageTypeWarning,
+			Message: "Some includes cannot be updated for this rename: " + strings.Join(lines, "; "),
+		}); err != nil {
+			return err
+		}
+	}
+	if len(edits) == 0 {
+		return s.reply(id, nil)
+	}
+	changes := map[string][]TextEdit{}
+	for _, e := range edits {
+		uri := uriFromPath(e.Path)
+		changes[uri] = append(changes[uri], TextEdit{Range: e.Range, NewText: e.NewText})
+	}
+	return s.reply(id, workspaceEdit{Changes: changes})
+}
+
+// handleDidRenameFiles moves per-document state to the new paths and
+// re-runs diagnostics, which report includes the rename left dangling.
+func (s *Server) handleDidRenameFiles(params json.RawMessage) error {
+	var p renameFilesParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+	renames := renamesFrom(p)
+	docs := make(map[string]string, len(s.docs))
+	for uri, text := range s.docs {
+		if path, ok := pathFromURI(uri); ok {
+			uri = uriFromPath(workspace.RenamedPath(path, renames))
+		}
+		docs[uri] = text
+	}
+	s.docs = docs
+	active := map[string]string{}
+	for doc, root := range s.activeRoots {
+		active[workspace.RenamedPath(doc, renames)] = workspace.RenamedPath(root, renames)
+	}
+	s.activeRoots = active
+	s.ws = nil
+	return s.refreshDiagnostics()
+}
@@ -0,0 +1,72 @@
+package lsp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+)
+
+func TestHandle_WillRenameFilesRewritesIncludes(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"onr.conf":             "include providers/azure.conf;\ninclude shared;\n",
+		"providers/azure.conf": "provider \"azure\" {\n}\n",
+		"shared/a.conf":        "syntax \"next-router/0.1\";\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	rootURI := uriFromPath(filepath.Join(dir, "onr.conf"))
+	oldURI := uriFromPath(filepath.Join(dir, "providers", "azure.conf"))
+	newURI := uriFromPath(filepath.Join(dir, "vendors", "azure.conf"))
+	sharedURI := uriFromPath(filepath.Join(dir, "shared", "a.conf"))
+	movedURI := uriFromPath(filepath.Join(dir, "a.conf"))
+
+	rawID := json.RawMessage("2")
+	req := json.RawMessage(`{"files":[{"oldUri":"` + oldURI + `","newUri":"` + newURI + `"},{"oldUri":"` + sharedURI + `","newUri":"` + movedURI + `"}]}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "workspace/willRenameFiles", Params: req}); err != nil {
+		t.Fatalf("handle willRenameFiles: %v", err)
+	}
+	msgs := readAllLSPMessages(t, out.Bytes())
+	if len(msgs) != 2 || msgs[0]["method"] != "window/showMessage" {
+		t.Fatalf("expected warning and reply, got %+v", msgs)
+	}
+	if msg := msgs[0]["params"].(map[string]any)["message"].(string); !strings.Contains(msg, `onr.conf:2: include "shared" will no longer include a.conf`) {
+		t.Fatalf("unexpected warning: %s", msg)
+	}
+	edits := msgs[1]["result"].(map[string]any)["changes"].(map[string]any)[rootURI].([]any)
+	if len(edits) != 1 || edits[0].(map[string]any)["newText"] != "vendors/azure.conf" {
+		t.Fatalf("unexpected include edits: %+v", edits)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "vendors"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "providers", "azure.conf"), filepath.Join(dir, "vendors", "azure.conf")); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "workspace/didRenameFiles", Params: req}); err != nil {
+		t.Fatalf("handle didRenameFiles: %v", err)
+	}
+	if msgs := readAllLSPMessages(t, out.Bytes()); len(msgs) != 1 || msgs[0]["method"] != "workspace/diagnostic/refresh" {
+		t.Fatalf("expected diagnostic refresh, got %+v", msgs)
+	}
+
+	out.Reset()
+	diagReq := json.RawMessage(`{"textDocument":{"uri":"` + rootURI + `"}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/diagnostic", Params: diagReq}); err != nil {
+		t.Fatalf("handle document diagnostic: %v", err)
+	}
+	items, _ := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)["items"].([]any)
+	var missing int
+	for _, item := range items {
+		if item.(map[string]any)["code"] == "missing-include" {
+			missing++
+		}
+	}
+	if missing != 1 {
+		t.Fatalf("expected the unedited include to dangle, got %+v", items)
+	}
+}
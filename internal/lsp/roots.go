@@ -0,0 +1,103 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+)
+
+// commandSelectRoot sets the root config a fragment is analysed under.
+// Arguments: document URI, root config URI (empty to restore the default).
+const commandSelectRoot = "onr.selectRoot"
+
+type executeCommandParams struct {
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+type documentRootsParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+// documentRootsResult answers onr/documentRoots with the root configs that
+// include a document and the one currently used for analysis.
+type documentRootsResult struct {
+	Roots  []string `json:"roots"`
+	Active string   `json:"active,omitempty"`
+}
+
+func (s *Server) handleDocumentRoots(id *json.RawMessage, params json.RawMessage) error {
+	var p documentRootsParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return s.replyError(id, -32602, "invalid params for document roots")
+	}
+	res := documentRootsResult{Roots: []string{}}
+	path, ok := pathFromURI(p.TextDocument.URI)
+	if !ok {
+		return s.reply(id, res)
+	}
+	ws := s.loadWorkspace()
+	ws.Add(path)
+	for _, root := range ws.RootsFor(path) {
+		res.Roots = append(res.Roots, root.URI)
+	}
+	if active, ok := ws.ActiveRoot(path); ok {
+		res.Active = active.URI
+	}
+	return s.reply(id, res)
+}
+
+func (s *Server) handleExecuteCommand(id *json.RawMessage, params json.RawMessage) error {
+	var p executeCommandParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return s.replyError(id, -32602, "invalid params for executeCommand")
+	}
+	switch p.Command {
+	case commandSelectRoot:
+		var docURI, rootURI string
+		if len(p.Arguments) > 0 {
+			_ = json.Unmarshal(p.Arguments[0], &docURI)
+		}
+		if len(p.Arguments) > 1 {
+			_ = json.Unmarshal(p.Arguments[1], &rootURI)
+		}
+		docPath, ok := pathFromURI(docURI)
+		if !ok {
+			return s.replyError(id, -32602, "onr.selectRoot expects a file document URI")
+		}
+		rootPath, ok := pathFromURI(rootURI)
+		if ok {
+			s.activeRoots[docPath] = rootPath
+		} else {
+			delete(s.activeRoots, docPath)
+		}
+		if s.ws != nil {
+			s.ws.SetActiveRoot(docPath, rootPath)
+		}
+		if err := s.reply(id, nil); err != nil {
+			return err
+		}
+		return s.refreshDiagnostics()
+	case commandScaffold:
+		return s.scaffoldCommand(id, p.Arguments)
+	default:
+		return s.replyError(id, -32602, "unknown command: "+p.Command)
+	}
+}
+
+// presetLookup returns a function listing presets visible to uri under its
+// active root. The workspace is loaded on first use only.
+func (s *Server) presetLookup(uri string) func(kind string) []string {
+	path, ok := pathFromURI(uri)
+	if !ok {
+		return nil
+	}
+	var ws *workspace.Workspace
+	return func(kind string) []string {
+		if ws == nil {
+			ws = s.loadWorkspace()
+			ws.Add(path)
+		}
+		return ws.Presets(path, kind)
+	}
+}
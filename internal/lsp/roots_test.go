@@ -0,0 +1,96 @@
+package lsp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+)
+
+func TestHandle_DocumentRootsAndSelectRoot(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"a/onr.conf":         "include ../shared/p.conf;\ninclude modes.conf;\n",
+		"a/modes.conf":       "usage_mode \"only_a\" {\n  usage_extract custom;\n}\n",
+		"b/onr.conf":         "include ../shared/p.conf;\n",
+		"shared/p.conf":      "provider \"p\" {\n  defaults {\n    metrics { usage_extract only_a; }\n  }\n}\n",
+		"shared/unused.conf": "syntax \"next-router/0.1\";\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	docURI := uriFromPath(filepath.Join(dir, "shared", "p.conf"))
+	aRoot := uriFromPath(filepath.Join(dir, "a", "onr.conf"))
+	bRoot := uriFromPath(filepath.Join(dir, "b", "onr.conf"))
+
+	rawID := json.RawMessage("2")
+	req := json.RawMessage(`{"textDocument":{"uri":"` + docURI + `"}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "onr/documentRoots", Params: req}); err != nil {
+		t.Fatalf("handle documentRoots: %v", err)
+	}
+	res := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+	roots := res["roots"].([]any)
+	if len(roots) != 2 || roots[0] != aRoot || roots[1] != bRoot || res["active"] != aRoot {
+		t.Fatalf("unexpected roots: %+v", res)
+	}
+
+	countUndefined := func() int {
+		out.Reset()
+		if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/diagnostic", Params: req}); err != nil {
+			t.Fatalf("handle document diagnostic: %v", err)
+		}
+		report := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)
+		n := 0
+		items, _ := report["items"].([]any)
+		for _, item := range items {
+			if item.(map[string]any)["code"] == "undefined-preset" {
+				n++
+			}
+		}
+		return n
+	}
+	if n := countUndefined(); n != 0 {
+		t.Fatalf("preset from root a must resolve, got %d undefined", n)
+	}
+
+	out.Reset()
+	cmd := json.RawMessage(`{"command":"onr.selectRoot","arguments":["` + docURI + `","` + bRoot + `"]}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "workspace/executeCommand", Params: cmd}); err != nil {
+		t.Fatalf("handle executeCommand: %v", err)
+	}
+	msgs := readAllLSPMessages(t, out.Bytes())
+	if len(msgs) != 2 || msgs[1]["method"] != "workspace/diagnostic/refresh" {
+		t.Fatalf("expected reply and diagnostic refresh, got %+v", msgs)
+	}
+	if n := countUndefined(); n != 1 {
+		t.Fatalf("preset must be undefined under root b, got %d", n)
+	}
+}
+
+func TestHandle_CompletionOffersPresetsFromSiblingIncludes(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"onr.conf":         "include modes/*.conf;\ninclude providers;\n",
+		"modes/usage.conf": "usage_mode \"shared_usage\" {\n  usage_extract custom;\n}\n",
+		"providers/p.conf": "provider \"p\" {\n  defaults {\n    metrics { usage_extract  }\n  }\n}\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	docURI := uriFromPath(filepath.Join(dir, "providers", "p.conf"))
+	text := "provider \"p\" {\n  defaults {\n    metrics { usage_extract  }\n  }\n}\n"
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: docURI, Text: text}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+	rawID := json.RawMessage("3")
+	req := json.RawMessage(`{"textDocument":{"uri":"` + docURI + `"},"position":{"line":2,"character":28}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "textDocument/completion", Params: req}); err != nil {
+		t.Fatalf("handle completion: %v", err)
+	}
+	items := readAllLSPMessages(t, out.Bytes())[0]["result"].([]any)
+	var found bool
+	for _, item := range items {
+		if item.(map[string]any)["label"] == "shared_usage" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected preset from modes/usage.conf, got %+v", items)
+	}
+}
@@ -0,0 +1,162 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/scaffold"
+)
+
+// commandScaffold fills an empty provider document with a template.
+// Arguments: document URI, optional family (default: onrLsp.scaffold.family).
+const commandScaffold = "onr.scaffold"
+
+// providerFileGlob matches files that get a provider skeleton on creation.
+const providerFileGlob = "**/providers/*.conf"
+
+// scaffoldSettings holds the onrLsp.scaffold settings.
+type scaffoldSettings struct {
+	Family       string   `json:"family"`
+	TemplateDirs []string `json:"templateDirs"`
+}
+
+func scaffoldSettingsFrom(settings json.RawMessage) scaffoldSettings {
+	var raw struct {
+		OnrLsp struct {
+			Scaffold scaffoldSettings `json:"scaffold"`
+		} `json:"onrLsp"`
+	}
+	if len(settings) > 0 {
+		_ = json.Unmarshal(settings, &raw)
+	}
+	return raw.OnrLsp.Scaffold
+}
+
+type workspaceServerCapabilities struct {
+	FileOperations fileOperationsServerCapabilities `json:"fileOperations"`
+}
+
+type fileOperationsServerCapabilities struct {
+	WillCreate *fileOperationRegistrationOptions `json:"willCreate,omitempty"`
+	WillRename *fileOperationRegistrationOptions `json:"willRename,omitempty"`
+	DidRename  *fileOperationRegistrationOptions `json:"didRename,omitempty"`
+}
+
+type fileOperationRegistrationOptions struct {
+	Filters []fileOperationFilter `json:"filters"`
+}
+
+type fileOperationFilter struct {
+	Scheme  string               `json:"scheme,omitempty"`
+	Pattern fileOperationPattern `json:"pattern"`
+}
+
+type fileOperationPattern struct {
+	Glob string `json:"glob"`
+	// Matches is "file" or "folder"; empty matches both.
+	Matches string `json:"matches,omitempty"`
+}
+
+type fileCreate struct {
+	URI string `json:"uri"`
+}
+
+type createFilesParams struct {
+	Files []fileCreate `json:"files"`
+}
+
+type applyWorkspaceEditParams struct {
+	Label string        `json:"label,omitempty"`
+	Edit  workspaceEdit `json:"edit"`
+}
+
+// handleWillCreateFiles fills new files under a providers directory with a
+// provider named after the file.
+func (s *Server) handleWillCreateFiles(id *json.RawMessage, params json.RawMessage) error {
+	var p createFilesParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return s.replyError(id, -32602, "invalid params for willCreateFiles")
+	}
+	changes := map[string][]TextEdit{}
+	for _, f := range p.Files {
+		path, ok := pathFromURI(f.URI)
+		if !ok || filepath.Ext(path) != ".conf" || filepath.Base(filepath.Dir(path)) != "providers" {
+			continue
+		}
+		text, err := s.providerSkeleton(path, "")
+		if err != nil {
+			s.logger.Printf("scaffold %s: %v", path, err)
+			continue
+		}
+		changes[f.URI] = []TextEdit{{NewText: text}}
+	}
+	if len(changes) == 0 {
+		return s.reply(id, nil)
+	}
+	return s.reply(id, workspaceEdit{Changes: changes})
+}
+
+// scaffoldCommand asks the client to fill an empty document with a provider
+// skeleton.
+func (s *Server) scaffoldCommand(id *json.RawMessage, args []json.RawMessage) error {
+	var uri, family string
+	if len(args) > 0 {
+		_ = json.Unmarshal(args[0], &uri)
+	}
+	if len(args) > 1 {
+		_ = json.Unmarshal(args[1], &family)
+	}
+	path, ok := pathFromURI(uri)
+	if !ok {
+		return s.replyError(id, -32602, "onr.scaffold expects a file document URI")
+	}
+	if text, _ := s.documentText(uri); strings.TrimSpace(text) != "" {
+		return s.replyError(id, -32602, "onr.scaffold: document is not empty")
+	}
+	text, err := s.providerSkeleton(path, family)
+	if err != nil {
+		return s.replyError(id, -32602, "onr.scaffold: "+err.Error())
+	}
+	if err := s.request("workspace/applyEdit", applyWorkspaceEditParams{
+		Label: "Scaffold provider",
+		Edit:  workspaceEdit{Changes: map[string][]TextEdit{uri: {{NewText: text}}}},
+	}); err != nil {
+		return err
+	}
+	return s.reply(id, nil)
+}
+
+// providerSkeleton renders the provider for a file at path. Relative template
+// directories are resolved against each workspace root.
+func (s *Server) providerSkeleton(path, family string) (string, error) {
+	if family == "" {
+		family = s.scaffold.Family
+	}
+	if family == "" {
+		family = "openai"
+	}
+	var dirs []string
+	for _, dir := range s.scaffold.TemplateDirs {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+			continue
+		}
+		for _, root := range s.roots {
+			dirs = append(dirs, filepath.Join(root, dir))
+		}
+	}
+	text, err := scaffold.Render(scaffold.Options{
+		Name:         scaffold.NameFromPath(path),
+		Family:       family,
+		TemplateDirs: dirs,
+	})
+	if err != nil {
+		return "", err
+	}
+	if diags := scaffold.Validate(uriFromPath(path), text); len(diags) > 0 {
+		return "", fmt.Errorf("%s template does not validate: %s", family, diags[0].Message)
+	}
+	return text, nil
+}
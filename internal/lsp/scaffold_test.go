@@ -0,0 +1,96 @@
+package lsp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+)
+
+func TestHandle_WillCreateFilesFillsProviderSkeleton(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"onr.conf": "include providers;\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	providerURI := uriFromPath(filepath.Join(dir, "providers", "azure.conf"))
+	otherURI := uriFromPath(filepath.Join(dir, "modes", "usage.conf"))
+
+	rawID := json.RawMessage("2")
+	req := json.RawMessage(`{"files":[{"uri":"` + providerURI + `"},{"uri":"` + otherURI + `"}]}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "workspace/willCreateFiles", Params: req}); err != nil {
+		t.Fatalf("handle willCreateFiles: %v", err)
+	}
+	changes := readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)["changes"].(map[string]any)
+	if len(changes) != 1 {
+		t.Fatalf("expected an edit for the provider file only, got %+v", changes)
+	}
+	edit := changes[providerURI].([]any)[0].(map[string]any)
+	text := edit["newText"].(string)
+	if !strings.Contains(text, `provider "azure" {`) || !strings.Contains(text, "auth_bearer;") {
+		t.Fatalf("unexpected skeleton:\n%s", text)
+	}
+
+	out.Reset()
+	settings := json.RawMessage(`{"settings":{"onrLsp":{"scaffold":{"family":"anthropic"}}}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "workspace/didChangeConfiguration", Params: settings}); err != nil {
+		t.Fatalf("handle didChangeConfiguration: %v", err)
+	}
+	out.Reset()
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "workspace/willCreateFiles", Params: req}); err != nil {
+		t.Fatalf("handle willCreateFiles: %v", err)
+	}
+	changes = readAllLSPMessages(t, out.Bytes())[0]["result"].(map[string]any)["changes"].(map[string]any)
+	text = changes[providerURI].([]any)[0].(map[string]any)["newText"].(string)
+	if !strings.Contains(text, "req_map openai_chat_to_anthropic_messages;") {
+		t.Fatalf("expected anthropic skeleton from settings:\n%s", text)
+	}
+}
+
+func TestHandle_ScaffoldCommandAppliesEdit(t *testing.T) {
+	dir := testutil.WriteTree(t, map[string]string{
+		"templates/corp.conf.tmpl": "provider \"{{.Name}}\" {\ndefaults { upstream_config { base_url = \"https://llm.corp\"; } }\n}\n",
+	})
+	s, out := newPullServer(t, uriFromPath(dir))
+	settings := json.RawMessage(`{"settings":{"onrLsp":{"scaffold":{"templateDirs":["templates"]}}}}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "workspace/didChangeConfiguration", Params: settings}); err != nil {
+		t.Fatalf("handle didChangeConfiguration: %v", err)
+	}
+	docURI := uriFromPath(filepath.Join(dir, "providers", "corp.conf"))
+	params, _ := json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: docURI, Text: ""}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+
+	out.Reset()
+	rawID := json.RawMessage("4")
+	cmd := json.RawMessage(`{"command":"onr.scaffold","arguments":["` + docURI + `","corp"]}`)
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "workspace/executeCommand", Params: cmd}); err != nil {
+		t.Fatalf("handle executeCommand: %v", err)
+	}
+	msgs := readAllLSPMessages(t, out.Bytes())
+	if len(msgs) != 2 || msgs[0]["method"] != "workspace/applyEdit" {
+		t.Fatalf("expected applyEdit request and reply, got %+v", msgs)
+	}
+	edit := msgs[0]["params"].(map[string]any)["edit"].(map[string]any)["changes"].(map[string]any)[docURI].([]any)[0].(map[string]any)
+	if !strings.Contains(edit["newText"].(string), "provider \"corp\" {\n  defaults {") {
+		t.Fatalf("unexpected scaffold edit: %+v", edit)
+	}
+
+	params, _ = json.Marshal(didOpenParams{TextDocument: textDocumentItem{URI: docURI, Text: "syntax \"next-router/0.1\";\n"}})
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params}); err != nil {
+		t.Fatalf("handle didOpen: %v", err)
+	}
+	out.Reset()
+	if err := s.handle(inboundMessage{JSONRPC: "2.0", ID: &rawID, Method: "workspace/executeCommand", Params: cmd}); err != nil {
+		t.Fatalf("handle executeCommand: %v", err)
+	}
+	if msg := readAllLSPMessages(t, out.Bytes())[0]; msg["error"] == nil {
+		t.Fatalf("expected error for non-empty document, got %+v", msg)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "providers")); !os.IsNotExist(err) {
+		t.Fatalf("scaffold must not write files, stat err=%v", err)
+	}
+}
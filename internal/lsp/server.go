@@ -7,10 +7,14 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/r9s-ai/onr-lsp/internal/corespec"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
 	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
 	"github.com/r9s-ai/open-next-router/onr-core/pkg/dslspec"
 )
@@ -24,6 +28,36 @@ type Server struct {
 
 	docs         map[string]string
 	shuttingDown bool
+
+	// ws caches the workspace with open documents overlaid, and wsFiles the
+	// .conf files found under roots when it was loaded. Document changes are
+	// applied to ws in place; a nil ws is loaded again on next use.
+	ws      *workspace.Workspace
+	wsFiles []string
+
+	// lintSettings holds rule severities from workspace/didChangeConfiguration.
+	// Project config files override them per document.
+	lintSettings lint.Config
+	// lintConfigs caches the project lint config by document directory until
+	// a watched lint config file changes.
+	lintConfigs map[string]lint.Config
+	// scaffold holds onrLsp.scaffold settings for new provider files.
+	scaffold scaffoldSettings
+	// exclude holds onrLsp.exclude and files.exclude globs hidden from
+	// include completion.
+	exclude []string
+
+	// roots are workspace folder paths from initialize.
+	roots []string
+	// pullDiagnostics is set when the client supports textDocument/diagnostic;
+	// publishDiagnostics is only pushed to clients without pull support.
+	pullDiagnostics bool
+	refreshSupport  bool
+	nextRequestID   int
+
+	// activeRoots maps a document path to the root config chosen with
+	// onr.selectRoot.
+	activeRoots map[string]string
 }
 
 // NewServer returns a non-nil LSP server.
@@ -33,6 +67,8 @@ func NewServer(in io.Reader, out io.Writer, logger *log.Logger) *Server {
 		out:    out,
 		logger: logger,
 		docs:   map[string]string{},
+
+		activeRoots: map[string]string{},
 	}
 }
 
@@ -53,6 +89,28 @@ type publishDiagnosticsParams struct {
 	Diagnostics []Diagnostic `json:"diagnostics"`
 }
 
+type initializeParams struct {
+	RootURI          string             `json:"rootUri"`
+	WorkspaceFolders []workspaceFolder  `json:"workspaceFolders"`
+	Capabilities     clientCapabilities `json:"capabilities"`
+}
+
+type workspaceFolder struct {
+	URI  string `json:"uri"`
+	Name string `json:"name"`
+}
+
+type clientCapabilities struct {
+	TextDocument struct {
+		Diagnostic *json.RawMessage `json:"diagnostic"`
+	} `json:"textDocument"`
+	Workspace struct {
+		Diagnostics *struct {
+			RefreshSupport bool `json:"refreshSupport"`
+		} `json:"diagnostics"`
+	} `json:"workspace"`
+}
+
 type initializeResult struct {
 	Capabilities serverCapabilities `json:"capabilities"`
 	ServerInfo   serverInfo         `json:"serverInfo"`
@@ -64,11 +122,26 @@ type serverInfo struct {
 }
 
 type serverCapabilities struct {
-	TextDocumentSync       int                    `json:"textDocumentSync"`
-	CompletionProvider     *completionProvider    `json:"completionProvider,omitempty"`
-	HoverProvider          bool                   `json:"hoverProvider"`
-	DocumentFormatting     bool                   `json:"documentFormattingProvider"`
-	SemanticTokensProvider *semanticTokensOptions `json:"semanticTokensProvider,omitempty"`
+	TextDocumentSync       int                          `json:"textDocumentSync"`
+	CompletionProvider     *completionProvider          `json:"completionProvider,omitempty"`
+	HoverProvider          bool                         `json:"hoverProvider"`
+	DocumentFormatting     bool                         `json:"documentFormattingProvider"`
+	SemanticTokensProvider *semanticTokensOptions       `json:"semanticTokensProvider,omitempty"`
+	DiagnosticProvider     *diagnosticOptions           `json:"diagnosticProvider,omitempty"`
+	ExecuteCommandProvider *executeCommandOptions       `json:"executeCommandProvider,omitempty"`
+	CodeActionProvider     *codeActionOptions           `json:"codeActionProvider,omitempty"`
+	DocumentLinkProvider   *documentLinkOptions         `json:"documentLinkProvider,omitempty"`
+	Workspace              *workspaceServerCapabilities `json:"workspace,omitempty"`
+}
+
+type executeCommandOptions struct {
+	Commands []string `json:"commands"`
+}
+
+type diagnosticOptions struct {
+	Identifier            string `json:"identifier,omitempty"`
+	InterFileDependencies bool   `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool   `json:"workspaceDiagnostics"`
 }
 
 type semanticTokensOptions struct {
@@ -121,6 +194,19 @@ type hoverParams struct {
 	Position     Position               `json:"position"`
 }
 
+type didChangeConfigurationParams struct {
+	Settings json.RawMessage `json:"settings"`
+}
+
+type didChangeWatchedFilesParams struct {
+	Changes []fileEvent `json:"changes"`
+}
+
+type fileEvent struct {
+	URI  string `json:"uri"`
+	Type int    `json:"type"`
+}
+
 type formattingOptions = dsllang.FormatOptions
 
 type formattingParams struct {
@@ -132,7 +218,7 @@ type Position = dsllang.Position
 
 type Range = dsllang.Range
 
-type Diagnostic = dsllang.Diagnostic
+type Diagnostic = lint.Diagnostic
 
 type MarkupContent struct {
 	Kind  string `json:"kind"`
@@ -145,10 +231,11 @@ type Hover struct {
 }
 
 type CompletionItem struct {
-	Label         string `json:"label"`
-	Kind          int    `json:"kind,omitempty"`
-	Detail        string `json:"detail,omitempty"`
-	Documentation string `json:"documentation,omitempty"`
+	Label         string    `json:"label"`
+	Kind          int       `json:"kind,omitempty"`
+	Detail        string    `json:"detail,omitempty"`
+	Documentation string    `json:"documentation,omitempty"`
+	TextEdit      *TextEdit `json:"textEdit,omitempty"`
 }
 
 type TextEdit struct {
@@ -184,7 +271,7 @@ func (s *Server) Run() error {
 func (s *Server) handle(msg inboundMessage) error {
 	switch msg.Method {
 	case "initialize":
-		return s.handleInitialize(msg.ID)
+		return s.handleInitialize(msg.ID, msg.Params)
 	case "initialized":
 		return nil
 	case "shutdown":
@@ -200,8 +287,8 @@ func (s *Server) handle(msg inboundMessage) error {
 		if err := json.Unmarshal(msg.Params, &p); err != nil {
 			return err
 		}
-		s.docs[p.TextDocument.URI] = p.TextDocument.Text
-		return s.publishDiagnostics(p.TextDocument.URI)
+		s.setDocument(p.TextDocument.URI, p.TextDocument.Text)
+		return s.pushDiagnostics(p.TextDocument.URI)
 	case "textDocument/didChange":
 		var p didChangeParams
 		if err := json.Unmarshal(msg.Params, &p); err != nil {
@@ -210,8 +297,54 @@ func (s *Server) handle(msg inboundMessage) error {
 		if len(p.ContentChanges) == 0 {
 			return nil
 		}
-		s.docs[p.TextDocument.URI] = p.ContentChanges[len(p.ContentChanges)-1].Text
-		return s.publishDiagnostics(p.TextDocument.URI)
+		s.setDocument(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+		return s.pushDiagnostics(p.TextDocument.URI)
+	case "workspace/didChangeWatchedFiles":
+		var p didChangeWatchedFilesParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return err
+		}
+		// Files changed outside open buffers; include targets and the file
+		// list may differ, so load the workspace again.
+		s.ws = nil
+		for _, change := range p.Changes {
+			if path, ok := pathFromURI(change.URI); ok && filepath.Base(path) == lint.ConfigFileName {
+				s.lintConfigs = nil
+			}
+		}
+		return s.refreshDiagnostics()
+	case "workspace/didChangeConfiguration":
+		var p didChangeConfigurationParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return err
+		}
+		// Each section applies on its own, so a bad lint rule does not
+		// discard scaffold or exclude settings.
+		s.scaffold = scaffoldSettingsFrom(p.Settings)
+		s.exclude = excludeSettingsFrom(p.Settings)
+		cfg, lintErr := lint.ConfigFromSettings(p.Settings)
+		if lintErr == nil && cfg.TargetCore != "" {
+			if _, err := corespec.Resolve(cfg.TargetCore); err != nil {
+				lintErr = fmt.Errorf("onrLsp.targetCore: %w", err)
+			}
+		}
+		if lintErr == nil {
+			s.lintSettings = cfg
+		}
+		if err := s.refreshDiagnostics(); err != nil {
+			return err
+		}
+		return lintErr
+	case "textDocument/diagnostic":
+		return s.handleDocumentDiagnostic(msg.ID, msg.Params)
+	case "workspace/diagnostic":
+		return s.handleWorkspaceDiagnostic(msg.ID, msg.Params)
+	case "workspace/executeCommand":
+		return s.handleExecuteCommand(msg.ID, msg.Params)
+	case "onr/documentRoots":
+		return s.handleDocumentRoots(msg.ID, msg.Params)
+	case "onr/mergedDocument":
+		return s.handleMergedDocument(msg.ID, msg.Params)
 	case "textDocument/completion":
 		return s.handleCompletion(msg.ID, msg.Params)
 	case "textDocument/hover":
@@ -220,6 +353,16 @@ func (s *Server) handle(msg inboundMessage) error {
 		return s.handleFormatting(msg.ID, msg.Params)
 	case "textDocument/semanticTokens/full":
 		return s.handleSemanticTokensFull(msg.ID, msg.Params)
+	case "textDocument/codeAction":
+		return s.handleCodeAction(msg.ID, msg.Params)
+	case "textDocument/documentLink":
+		return s.handleDocumentLink(msg.ID, msg.Params)
+	case "workspace/willCreateFiles":
+		return s.handleWillCreateFiles(msg.ID, msg.Params)
+	case "workspace/willRenameFiles":
+		return s.handleWillRenameFiles(msg.ID, msg.Params)
+	case "workspace/didRenameFiles":
+		return s.handleDidRenameFiles(msg.Params)
 	default:
 		if msg.ID != nil {
 			return s.reply(msg.ID, nil)
@@ -228,13 +371,23 @@ func (s *Server) handle(msg inboundMessage) error {
 	}
 }
 
-func (s *Server) handleInitialize(id *json.RawMessage) error {
+func (s *Server) handleInitialize(id *json.RawMessage, params json.RawMessage) error {
+	var p initializeParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return s.replyError(id, -32602, "invalid params for initialize")
+		}
+	}
+	s.roots = workspaceRoots(p)
+	s.pullDiagnostics = p.Capabilities.TextDocument.Diagnostic != nil
+	s.refreshSupport = p.Capabilities.Workspace.Diagnostics != nil && p.Capabilities.Workspace.Diagnostics.RefreshSupport
+
 	res := initializeResult{
 		Capabilities: serverCapabilities{
 			TextDocumentSync: 1,
 			CompletionProvider: &completionProvider{
 				ResolveProvider:   false,
-				TriggerCharacters: []string{" ", "_"},
+				TriggerCharacters: []string{" ", "_", "/", "\"", "."},
 			},
 			HoverProvider:      true,
 			DocumentFormatting: true,
@@ -242,6 +395,27 @@ func (s *Server) handleInitialize(id *json.RawMessage) error {
 				Legend: dsllang.CollectSemanticTokenLegend(),
 				Full:   true,
 			},
+			DiagnosticProvider: &diagnosticOptions{
+				Identifier:            "onr-lsp",
+				InterFileDependencies: true,
+				WorkspaceDiagnostics:  true,
+			},
+			ExecuteCommandProvider: &executeCommandOptions{
+				Commands: []string{commandSelectRoot, commandScaffold},
+			},
+			CodeActionProvider: &codeActionOptions{
+				CodeActionKinds: []string{codeActionKindQuickFix},
+			},
+			DocumentLinkProvider: &documentLinkOptions{},
+			Workspace: &workspaceServerCapabilities{
+				FileOperations: fileOperationsServerCapabilities{
+					WillCreate: &fileOperationRegistrationOptions{
+						Filters: []fileOperationFilter{{Scheme: "file", Pattern: fileOperationPattern{Glob: providerFileGlob}}},
+					},
+					WillRename: &fileOperationRegistrationOptions{Filters: renameFilters},
+					DidRename:  &fileOperationRegistrationOptions{Filters: renameFilters},
+				},
+			},
 		},
 		ServerInfo: serverInfo{
 			Name:    "onr-lsp",
@@ -257,7 +431,16 @@ func (s *Server) handleCompletion(id *json.RawMessage, params json.RawMessage) e
 		return s.replyError(id, -32602, "invalid params for completion")
 	}
 	text := s.docs[p.TextDocument.URI]
-	items := complete(text, p.Position)
+	if items, ok := s.completeInclude(p.TextDocument.URI, text, p.Position); ok {
+		return s.reply(id, items)
+	}
+	if items, ok := completePath(text, p.Position); ok {
+		return s.reply(id, items)
+	}
+	if items, ok := completeHeader(text, p.Position); ok {
+		return s.reply(id, items)
+	}
+	items := completeWithPresets(text, p.Position, s.presetLookup(p.TextDocument.URI))
 	return s.reply(id, items)
 }
 
@@ -267,6 +450,9 @@ func (s *Server) handleHover(id *json.RawMessage, params json.RawMessage) error
 		return s.replyError(id, -32602, "invalid params for hover")
 	}
 	text := s.docs[p.TextDocument.URI]
+	if hover, ok := hoverHeader(text, p.Position); ok {
+		return s.reply(id, hover)
+	}
 	hover, ok := dsllang.CollectHover(text, p.Position)
 	if !ok {
 		return s.reply(id, nil)
@@ -305,19 +491,6 @@ func (s *Server) handleFormatting(id *json.RawMessage, params json.RawMessage) e
 	return s.reply(id, edits)
 }
 
-func (s *Server) publishDiagnostics(uri string) error {
-	text, ok := s.docs[uri]
-	if !ok {
-		return nil
-	}
-	diags := dsllang.CollectDiagnostics(uri, text)
-	params := publishDiagnosticsParams{
-		URI:         uri,
-		Diagnostics: diags,
-	}
-	return s.notify("textDocument/publishDiagnostics", params)
-}
-
 func (s *Server) reply(id *json.RawMessage, result interface{}) error {
 	if id == nil {
 		return nil
@@ -353,6 +526,17 @@ func (s *Server) replyError(id *json.RawMessage, code int, msg string) error {
 	return writeMessage(s.out, resp)
 }
 
+func (s *Server) request(method string, params interface{}) error {
+	s.nextRequestID++
+	payload := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      fmt.Sprintf("onr-lsp-%d", s.nextRequestID),
+		"method":  method,
+		"params":  params,
+	}
+	return writeMessage(s.out, payload)
+}
+
 func (s *Server) notify(method string, params interface{}) error {
 	payload := map[string]interface{}{
 		"jsonrpc": "2.0",
@@ -406,6 +590,12 @@ func writeMessage(w io.Writer, payload interface{}) error {
 }
 
 func complete(text string, pos Position) []CompletionItem {
+	return completeWithPresets(text, pos, nil)
+}
+
+// completeWithPresets completes like complete and also offers presets of a
+// registry kind returned by presets, e.g. those declared in sibling includes.
+func completeWithPresets(text string, pos Position, presets func(kind string) []string) []CompletionItem {
 	line := lineAt(text, pos.Line)
 	prefix := line
 	if pos.Character >= 0 && pos.Character <= len(line) {
@@ -422,7 +612,11 @@ func complete(text string, pos Position) []CompletionItem {
 
 	dir, dirPrefix, ok := modeCompletionPrefix(prefix, block)
 	if ok && directiveAllowedInPhase(dir, block) {
-		return completionItemsFromValues(modeListByDirective(text, block, dir), dirPrefix, dir+" mode", "Built-in or user-defined ONR mapping mode.", 3)
+		values := modeListByDirective(text, block, dir)
+		if kind := dslspec.DirectiveModeRegistryBlockInBlock(dir, block); kind != "" && presets != nil {
+			values = dedupeSortedStrings(append(values, presets(kind)...))
+		}
+		return completionItemsFromValues(values, dirPrefix, dir+" mode", "Built-in or user-defined ONR mapping mode.", 3)
 	}
 
 	wordPrefix := currentWordPrefix(prefix)
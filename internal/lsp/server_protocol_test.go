@@ -440,6 +440,60 @@ func TestHandle_InvalidFormattingParamsReplyError(t *testing.T) {
 	}
 }
 
+func TestHandle_DidChangeConfigurationAppliesLintSeverities(t *testing.T) {
+	var out bytes.Buffer
+	s := NewServer(stringsReader(""), &out, log.New(io.Discard, "", 0))
+	s.docs["untitled:a.conf"] = "unknown_top foo;\n# onr-lsp:ignore unknown-directive\nother_top bar;\n"
+
+	if err := s.handle(inboundMessage{
+		JSONRPC: "2.0",
+		Method:  "workspace/didChangeConfiguration",
+		Params:  json.RawMessage(`{"settings":{"onrLsp":{"lint":{"rules":{"unknown-directive":"warning"}}}}}`),
+	}); err != nil {
+		t.Fatalf("handle didChangeConfiguration: %v", err)
+	}
+
+	msgs := readAllLSPMessages(t, out.Bytes())
+	if len(msgs) != 1 || msgs[0]["method"] != "textDocument/publishDiagnostics" {
+		t.Fatalf("expected diagnostics republished, got: %+v", msgs)
+	}
+	diags := msgs[0]["params"].(map[string]any)["diagnostics"].([]any)
+	if len(diags) != 1 {
+		t.Fatalf("expected suppressed second diagnostic, got: %+v", diags)
+	}
+	d := diags[0].(map[string]any)
+	if d["code"] != "unknown-directive" || d["severity"] != float64(2) {
+		t.Fatalf("unexpected diagnostic: %+v", d)
+	}
+}
+
+func TestHandle_DidChangeConfigurationRejectsUnknownRule(t *testing.T) {
+	s := NewServer(stringsReader(""), io.Discard, log.New(io.Discard, "", 0))
+	err := s.handle(inboundMessage{
+		JSONRPC: "2.0",
+		Method:  "workspace/didChangeConfiguration",
+		Params:  json.RawMessage(`{"settings":{"onrLsp":{"lint":{"rules":{"nope":"off"}}}}}`),
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown lint rule") {
+		t.Fatalf("expected unknown rule error, got: %v", err)
+	}
+}
+
+func TestHandle_DidChangeConfigurationRejectsUnknownTargetCore(t *testing.T) {
+	s := NewServer(stringsReader(""), io.Discard, log.New(io.Discard, "", 0))
+	err := s.handle(inboundMessage{
+		JSONRPC: "2.0",
+		Method:  "workspace/didChangeConfiguration",
+		Params:  json.RawMessage(`{"settings":{"onrLsp":{"targetCore":"v1.0.x"}}}`),
+	})
+	if err == nil || !strings.Contains(err.Error(), "onrLsp.targetCore: no dslspec snapshot for onr-core v1.0.x") {
+		t.Fatalf("expected unknown target error, got: %v", err)
+	}
+	if s.lintSettings.TargetCore != "" {
+		t.Fatalf("unknown target must not be applied: %+v", s.lintSettings)
+	}
+}
+
 func writeLSPMessage(w *bytes.Buffer, payload any) {
 	b, _ := json.Marshal(payload)
 	_, _ = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(b))
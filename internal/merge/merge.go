@@ -0,0 +1,118 @@
+// Package merge flattens a root config and its includes into one document,
+// the same way onr-core expands includes before parsing.
+package merge
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// Options controls a merge.
+type Options struct {
+	// Overlay replaces file content on disk, e.g. with unsaved editor buffers.
+	Overlay map[string]string
+	// Format selects the indentation of the output.
+	Format dsllang.FormatOptions
+}
+
+// Merge returns path with every include replaced by the included files, in
+// order. Each inlined file starts with a `# from: <path>` comment relative to
+// path's directory. The result is formatted with dsllang.FormatText.
+func Merge(path string, opts Options) (string, error) {
+	ws := workspace.New(opts.Overlay)
+	doc, ok := ws.Document(path)
+	if !ok {
+		return "", fmt.Errorf("read file %q", path)
+	}
+	m := &merger{ws: ws, base: filepath.Dir(doc.Path)}
+	text, err := m.expand(doc, nil)
+	if err != nil {
+		return "", err
+	}
+	format := opts.Format
+	if format.TabSize <= 0 {
+		format.TabSize = 2
+		format.InsertSpaces = true
+	}
+	return collapseBlankLines(dsllang.FormatText(text, format)), nil
+}
+
+// collapseBlankLines keeps at most one empty line between statements, since
+// inlining leaves the blank lines of both the include site and the file.
+func collapseBlankLines(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" && len(out) > 0 && out[len(out)-1] == "" {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			line = ""
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+type merger struct {
+	ws   *workspace.Workspace
+	base string
+}
+
+func (m *merger) expand(doc *workspace.Document, stack []string) (string, error) {
+	for _, p := range stack {
+		if p == doc.Path {
+			return "", fmt.Errorf("include cycle: %s", m.chain(append(stack, doc.Path)))
+		}
+	}
+	if len(stack) > workspace.MaxIncludeDepth {
+		return "", fmt.Errorf("include depth exceeded (%d) at %s", workspace.MaxIncludeDepth, m.rel(doc.Path))
+	}
+	stack = append(stack, doc.Path)
+
+	var out strings.Builder
+	cursor := 0
+	for _, inc := range m.ws.Includes(doc) {
+		if inc.Err != nil {
+			pos := inc.Stmt.NameRange.Start
+			return "", fmt.Errorf("%s:%d:%d: %v", m.rel(doc.Path), pos.Line+1, pos.Character+1, inc.Err)
+		}
+		out.WriteString(doc.Text[cursor:inc.Stmt.Start])
+		cursor = inc.Stmt.End
+		for _, target := range inc.Targets {
+			child, ok := m.ws.Document(target)
+			if !ok {
+				return "", fmt.Errorf("read include file %q (from %s)", target, m.rel(doc.Path))
+			}
+			expanded, err := m.expand(child, stack)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString("\n# from: " + m.rel(target) + "\n")
+			out.WriteString(strings.TrimSpace(expanded))
+			out.WriteString("\n\n")
+		}
+	}
+	out.WriteString(doc.Text[cursor:])
+	return out.String(), nil
+}
+
+func (m *merger) rel(path string) string {
+	rel, err := filepath.Rel(m.base, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (m *merger) chain(paths []string) string {
+	names := make([]string, 0, len(paths))
+	for _, p := range paths {
+		names = append(names, m.rel(p))
+	}
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,87 @@
+package merge
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+)
+
+func TestMergeInlinesIncludesWithProvenance(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":           "syntax \"next-router/0.1\";\ninclude modes/*.conf;\n\ninclude providers;\n",
+		"modes/usage.conf":   "usage_mode \"u\" { usage_extract custom; }\n",
+		"providers/b.conf":   "provider \"b\" {\n}\n",
+		"providers/a.conf":   "include ../shared/common.conf;\nprovider \"a\" { defaults { auth { auth_bearer; } } }\n",
+		"shared/common.conf": "# shared\n",
+	})
+	got, err := Merge(filepath.Join(root, "onr.conf"), Options{})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	want := `syntax "next-router/0.1";
+
+# from: modes/usage.conf
+usage_mode "u" {
+  usage_extract custom;
+}
+
+# from: providers/a.conf
+# from: shared/common.conf
+# shared
+
+provider "a" {
+  defaults {
+    auth {
+      auth_bearer;
+    }
+  }
+}
+
+# from: providers/b.conf
+provider "b" {
+}
+`
+	if got != want {
+		t.Fatalf("unexpected merge output:\n%s", got)
+	}
+}
+
+func TestMergeUsesOverlay(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf": "include a.conf;\n",
+		"a.conf":   "provider \"disk\" {\n}\n",
+	})
+	got, err := Merge(filepath.Join(root, "onr.conf"), Options{Overlay: map[string]string{
+		filepath.Join(root, "a.conf"): "provider \"buffer\" {\n}\n",
+	}})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if !strings.Contains(got, `provider "buffer"`) {
+		t.Fatalf("expected overlay content, got:\n%s", got)
+	}
+}
+
+func TestMergeReportsCyclesAndMissingIncludes(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":     "include a.conf;\n",
+		"a.conf":       "include onr.conf;\n",
+		"missing.conf": "\n\ninclude nope/*.conf;\n",
+	})
+	_, err := Merge(filepath.Join(root, "onr.conf"), Options{})
+	if err == nil || err.Error() != "include cycle: onr.conf -> a.conf -> onr.conf" {
+		t.Fatalf("unexpected cycle error: %v", err)
+	}
+	_, err = Merge(filepath.Join(root, "missing.conf"), Options{})
+	if err == nil || !strings.HasPrefix(err.Error(), "missing.conf:3:1: include glob") {
+		t.Fatalf("unexpected missing include error: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+// Package migrate knows which directives changed between DSL syntax versions
+// and moves configs forward. The same table drives `onr-lsp migrate`, the
+// deprecated-directive diagnostic and its editor quick fix.
+package migrate
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/onr-lsp/internal/workspace"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dslspec"
+)
+
+// Versions lists the DSL syntax versions, oldest first. It is maintained by
+// hand together with table: dslspec snapshots only record the directives a
+// core accepts, not which directive replaced a removed one.
+var Versions = []string{"next-router/0.1"}
+
+// Latest returns the newest known syntax version.
+func Latest() string {
+	return Versions[len(Versions)-1]
+}
+
+// Entry is one directive that stopped working in a syntax version.
+type Entry struct {
+	// Syntax is the version that no longer accepts Name.
+	Syntax string
+	// Block restricts the entry to one block; empty means any block.
+	Block string
+	Name  string
+	// Replacement is the directive that takes the same arguments, or empty
+	// when there is no mechanical replacement and Note says what to do.
+	Replacement string
+	Note        string
+}
+
+// table mirrors the removed-directive errors of the onr-core parser. Add an
+// entry by hand when onr-core turns a directive into such an error; the
+// tests check every entry against the linked dslspec.
+var table = []Entry{
+	{Syntax: "next-router/0.1", Name: "header_set", Replacement: "set_header"},
+	{Syntax: "next-router/0.1", Name: "header_del", Replacement: "del_header"},
+	{Syntax: "next-router/0.1", Name: "proxy_set_header", Replacement: "set_header"},
+	{Syntax: "next-router/0.1", Block: "upstream", Name: "query_set", Replacement: "set_query"},
+	{Syntax: "next-router/0.1", Block: "metrics", Name: "input_tokens", Replacement: "input_tokens_expr"},
+	{Syntax: "next-router/0.1", Block: "metrics", Name: "output_tokens", Replacement: "output_tokens_expr"},
+	{Syntax: "next-router/0.1", Block: "metrics", Name: "cache_read_tokens", Replacement: "cache_read_tokens_expr"},
+	{Syntax: "next-router/0.1", Block: "metrics", Name: "cache_write_tokens", Replacement: "cache_write_tokens_expr"},
+	{Syntax: "next-router/0.1", Block: "metrics", Name: "total_tokens", Replacement: "total_tokens_expr"},
+	{Syntax: "next-router/0.1", Block: "balance", Name: "used", Replacement: "used_expr"},
+}
+
+// Table returns the entries that apply when migrating to target, in table
+// order. An empty target means Latest.
+func Table(target string) ([]Entry, error) {
+	if target == "" {
+		target = Latest()
+	}
+	idx := slices.Index(Versions, target)
+	if idx < 0 {
+		return nil, fmt.Errorf("unknown syntax version %q (known: %s)", target, strings.Join(Versions, ", "))
+	}
+	var out []Entry
+	for _, e := range table {
+		if slices.Index(Versions, e.Syntax) <= idx {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Finding is one statement that uses a directive from the table.
+type Finding struct {
+	Entry Entry
+	Stmt  *dslast.Statement
+	// Block is the name of the enclosing block, "top" at file level.
+	Block string
+	// Problem says why the statement cannot be migrated automatically; it is
+	// empty when it can.
+	Problem string
+}
+
+// Fixable reports whether the statement can be rewritten automatically.
+func (f Finding) Fixable() bool {
+	return f.Problem == ""
+}
+
+// Message describes the deprecation, e.g.
+// `header_set was removed in next-router/0.1; use set_header`.
+func (f Finding) Message() string {
+	msg := fmt.Sprintf("%s was removed in %s", f.Entry.Name, f.Entry.Syntax)
+	if f.Entry.Replacement != "" {
+		msg += "; use " + f.Entry.Replacement
+	}
+	if f.Entry.Note != "" {
+		msg += "; " + f.Entry.Note
+	}
+	return msg
+}
+
+// Edit returns the range of the directive name and its replacement.
+func (f Finding) Edit() (dsllang.Range, string) {
+	return f.Stmt.NameRange, f.Entry.Replacement
+}
+
+// Find returns the statements of text that the entries for target cover, in
+// source order.
+func Find(text, target string) ([]Finding, error) {
+	entries, err := Table(target)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string][]Entry{}
+	for _, e := range entries {
+		byName[e.Name] = append(byName[e.Name], e)
+	}
+	var out []Finding
+	dslast.Walk(dslast.Parse(text), func(stmt *dslast.Statement, parents []*dslast.Statement) bool {
+		block := dslast.BlockName(parents)
+		for _, e := range byName[stmt.Name] {
+			if e.Block != "" && e.Block != block {
+				continue
+			}
+			out = append(out, Finding{Entry: e, Stmt: stmt, Block: block, Problem: problem(e, stmt, block, parents)})
+			break
+		}
+		return true
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Stmt.Start < out[j].Stmt.Start })
+	return out, nil
+}
+
+// problem returns why stmt cannot simply be renamed to e.Replacement.
+func problem(e Entry, stmt *dslast.Statement, block string, parents []*dslast.Statement) string {
+	switch {
+	case e.Replacement == "":
+		return "no automatic replacement"
+	case stmt.IsBlock():
+		return fmt.Sprintf("%s is written as a block", stmt.Name)
+	case !slices.Contains(dslspec.DirectivesByBlock(block), e.Replacement):
+		return fmt.Sprintf("%s is not allowed in %s", e.Replacement, block)
+	}
+	if len(parents) == 0 || workspace.DirectiveKeyArgs(e.Replacement) != 0 {
+		return ""
+	}
+	for _, sibling := range parents[len(parents)-1].Children() {
+		if sibling.Name == e.Replacement {
+			return fmt.Sprintf("%s is already set in this %s block", e.Replacement, block)
+		}
+	}
+	return ""
+}
+
+// Apply rewrites every fixable finding for target and returns the new text,
+// the number of rewrites and the findings it could not migrate.
+func Apply(text, target string) (string, int, []Finding, error) {
+	findings, err := Find(text, target)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	var rest []Finding
+	for i := len(findings) - 1; i >= 0; i-- {
+		f := findings[i]
+		if !f.Fixable() {
+			rest = append([]Finding{f}, rest...)
+			continue
+		}
+		start := f.Stmt.Start
+		text = text[:start] + f.Entry.Replacement + text[start+len(f.Stmt.Name):]
+	}
+	return text, len(findings) - len(rest), rest, nil
+}
+
+// Diagnostics reports the findings in text against the latest syntax as
+// deprecated-directive diagnostics. Severities are left to lint.Filter; pass
+// the filtered result to Supersede.
+func Diagnostics(text string) []lint.Diagnostic {
+	findings, _ := Find(text, "")
+	out := make([]lint.Diagnostic, 0, len(findings))
+	for _, f := range findings {
+		out = append(out, lint.Diagnostic{
+			Range:   f.Stmt.NameRange,
+			Code:    lint.RuleDeprecatedDirective,
+			Source:  "onr-lsp",
+			Message: f.Message(),
+			Tags:    []int{lint.TagDeprecated},
+		})
+	}
+	return out
+}
+
+// superseded are the onr-core rules that fire on a removed directive and say
+// less than its deprecated-directive diagnostic.
+var superseded = []string{lint.RuleUnknownDirective, lint.RuleSemanticError}
+
+// Supersede drops the diagnostics of diags that onr-core reports at the start
+// of a directive name in deprecated, then appends deprecated, so each removed
+// directive gets one diagnostic that carries the migration hint and quick
+// fix. Core diagnostics stay when the deprecated-directive rule is off or
+// suppressed.
+func Supersede(diags, deprecated []lint.Diagnostic) []lint.Diagnostic {
+	names := map[dsllang.Position]bool{}
+	for _, d := range deprecated {
+		names[d.Range.Start] = true
+	}
+	out := make([]lint.Diagnostic, 0, len(diags)+len(deprecated))
+	for _, d := range diags {
+		if names[d.Range.Start] && slices.Contains(superseded, d.Code) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return append(out, deprecated...)
+}
@@ -0,0 +1,148 @@
+package migrate
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dslspec"
+)
+
+const legacy = `syntax "next-router/0.1";
+
+provider "p" {
+  defaults {
+    upstream_config {
+      base_url = "https://example.com";
+    }
+    request {
+      header_set "X-A" "1"; # keep
+      header_del "X-B";
+    }
+    metrics {
+      usage_extract custom;
+      input_tokens = $.usage.prompt_tokens;
+      output_tokens = $.usage.completion_tokens;
+      output_tokens_expr = $.usage.output;
+    }
+    balance {
+      balance_mode custom;
+      used = $.used;
+    }
+  }
+}
+`
+
+func TestApplyRenamesAndReportsConflicts(t *testing.T) {
+	t.Parallel()
+
+	got, n, rest, err := Apply(legacy, "")
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if n != 4 {
+		t.Fatalf("expected 4 rewrites, got %d", n)
+	}
+	for _, want := range []string{
+		`set_header "X-A" "1"; # keep`,
+		`del_header "X-B";`,
+		`input_tokens_expr = $.usage.prompt_tokens;`,
+		`used_expr = $.used;`,
+		`output_tokens = $.usage.completion_tokens;`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+	if len(rest) != 1 || rest[0].Entry.Name != "output_tokens" || rest[0].Problem != "output_tokens_expr is already set in this metrics block" {
+		t.Fatalf("unexpected unmigrated findings: %+v", rest)
+	}
+	if line := rest[0].Stmt.NameRange.Start.Line; line != 14 {
+		t.Fatalf("unexpected finding line %d", line)
+	}
+}
+
+func TestFindRespectsBlocks(t *testing.T) {
+	t.Parallel()
+
+	text := "provider \"p\" {\n  defaults {\n    request {\n      used = 1;\n    }\n    upstream {\n      query_set a \"b\";\n    }\n  }\n}\n"
+	found, err := Find(text, "next-router/0.1")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(found) != 1 || found[0].Entry.Name != "query_set" || !found[0].Fixable() {
+		t.Fatalf("unexpected findings: %+v", found)
+	}
+	if msg := found[0].Message(); msg != "query_set was removed in next-router/0.1; use set_query" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestFindRejectsUnknownVersion(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Find(legacy, "next-router/9.9"); err == nil || !strings.Contains(err.Error(), "unknown syntax version") {
+		t.Fatalf("expected unknown version error, got %v", err)
+	}
+}
+
+func TestDiagnosticsAreTaggedDeprecated(t *testing.T) {
+	t.Parallel()
+
+	diags := Diagnostics(legacy)
+	if len(diags) != 5 {
+		t.Fatalf("expected 5 diagnostics, got %+v", diags)
+	}
+	d := diags[0]
+	if d.Code != lint.RuleDeprecatedDirective || len(d.Tags) != 1 || d.Tags[0] != lint.TagDeprecated {
+		t.Fatalf("unexpected diagnostic: %+v", d)
+	}
+	if d.Range.Start.Line != 8 || d.Range.Start.Character != 6 || d.Range.End.Character != 16 {
+		t.Fatalf("diagnostic must cover the directive name: %+v", d.Range)
+	}
+}
+
+func TestSupersedeReplacesCoreDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	core := lint.Apply(legacy, dsllang.CollectDiagnostics("file:///p.conf", legacy), lint.Config{})
+	if len(core) == 0 {
+		t.Fatalf("expected onr-core to reject the legacy directives")
+	}
+	deprecated := lint.Filter(legacy, Diagnostics(legacy), lint.Config{})
+	got := Supersede(core, deprecated)
+	if len(got) != len(deprecated) {
+		t.Fatalf("expected only deprecated-directive diagnostics, got %+v", got)
+	}
+	for _, d := range got {
+		if d.Code != lint.RuleDeprecatedDirective || d.Severity != lint.SeverityError {
+			t.Fatalf("unexpected diagnostic: %+v", d)
+		}
+	}
+
+	if got := Supersede(core, nil); len(got) != len(core) {
+		t.Fatalf("core diagnostics must stay when the rule is off, got %+v", got)
+	}
+}
+
+func TestTableMatchesDslspec(t *testing.T) {
+	t.Parallel()
+
+	for _, e := range table {
+		if !slices.Contains(Versions, e.Syntax) {
+			t.Errorf("%s: unknown syntax %q", e.Name, e.Syntax)
+		}
+		blocks := dslspec.DirectiveAllowedBlocks(e.Name)
+		if e.Block != "" && slices.Contains(blocks, e.Block) {
+			t.Errorf("%s is still allowed in %s", e.Name, e.Block)
+		}
+		if e.Block == "" && len(blocks) != 0 {
+			t.Errorf("%s is still allowed in %v", e.Name, blocks)
+		}
+		if e.Replacement != "" && len(dslspec.DirectiveAllowedBlocks(e.Replacement)) == 0 {
+			t.Errorf("%s: replacement %s is not a directive", e.Name, e.Replacement)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package query
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+)
+
+// Match is one selected statement with its enclosing blocks, outermost first.
+type Match struct {
+	Stmt    *dslast.Statement
+	Parents []*dslast.Statement
+}
+
+// Label returns the statement header, e.g. `match api = "chat.completions"`.
+func Label(stmt *dslast.Statement) string {
+	if len(stmt.Args) == 0 {
+		return stmt.Name
+	}
+	return stmt.Name + " " + stmt.ArgsRaw()
+}
+
+// Context returns the labels of the enclosing blocks and the statement,
+// joined like `provider "openai" > defaults > auth > oauth_content_type form`.
+func (m Match) Context() string {
+	parts := make([]string, 0, len(m.Parents)+1)
+	for _, p := range m.Parents {
+		parts = append(parts, Label(p))
+	}
+	return strings.Join(append(parts, Label(m.Stmt)), " > ")
+}
+
+// Select returns the statements of f matched by the selector in source order.
+func (s *Selector) Select(f *dslast.File) []Match {
+	if f == nil || len(s.steps) == 0 {
+		return nil
+	}
+	seen := map[*dslast.Statement]bool{}
+	var out []Match
+	s.match(f.Statements, nil, 0, func(m Match) {
+		if !seen[m.Stmt] {
+			seen[m.Stmt] = true
+			out = append(out, m)
+		}
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Stmt.Start < out[j].Stmt.Start })
+	return out
+}
+
+func (s *Selector) match(stmts []*dslast.Statement, parents []*dslast.Statement, i int, emit func(Match)) {
+	st := s.steps[i]
+	if st.descend {
+		if i == len(s.steps)-1 {
+			// A trailing `**` selects everything below.
+			dslast.Walk(&dslast.File{Statements: stmts}, func(stmt *dslast.Statement, inner []*dslast.Statement) bool {
+				emit(Match{Stmt: stmt, Parents: append(append([]*dslast.Statement{}, parents...), inner...)})
+				return true
+			})
+			return
+		}
+		s.match(stmts, parents, i+1, emit)
+		for _, stmt := range stmts {
+			if stmt.IsBlock() {
+				s.match(stmt.Children(), with(parents, stmt), i, emit)
+			}
+		}
+		return
+	}
+	for _, stmt := range stmts {
+		if stmt.Name == "" || !st.name.MatchString(stmt.Name) || !st.accepts(stmt) {
+			continue
+		}
+		if i == len(s.steps)-1 {
+			emit(Match{Stmt: stmt, Parents: parents})
+			continue
+		}
+		if stmt.IsBlock() {
+			s.match(stmt.Children(), with(parents, stmt), i+1, emit)
+		}
+	}
+}
+
+func with(parents []*dslast.Statement, stmt *dslast.Statement) []*dslast.Statement {
+	out := make([]*dslast.Statement, len(parents)+1)
+	copy(out, parents)
+	out[len(parents)] = stmt
+	return out
+}
+
+func (st step) accepts(stmt *dslast.Statement) bool {
+	for _, f := range st.filters {
+		if f.holds(stmt) == f.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// holds reports whether the filter matches, ignoring negation.
+func (f filter) holds(stmt *dslast.Statement) bool {
+	switch {
+	case f.key != "":
+		for _, v := range pairValues(stmt.Args, f.key) {
+			if f.value.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	case f.index > 0:
+		return f.index <= len(stmt.Args) && f.value.MatchString(stmt.Args[f.index-1].Value())
+	default:
+		return len(stmt.Args) > 0 && f.value.MatchString(stmt.Args[0].Value())
+	}
+}
+
+// pairValues returns the values given to key as `key = value`, `key=value`
+// or `key= value` in an argument list.
+func pairValues(args []*dslast.Arg, key string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		raw := args[i].Raw
+		if args[i].Quoted {
+			continue
+		}
+		if k, v, ok := strings.Cut(raw, "="); ok && k != "" {
+			if k != key {
+				continue
+			}
+			if v == "" && i+1 < len(args) {
+				i++
+				v = args[i].Raw
+			}
+			out = append(out, dslast.Unquote(v))
+			continue
+		}
+		if raw == key && i+2 < len(args) && args[i+1].Raw == "=" {
+			out = append(out, args[i+2].Value())
+			i += 2
+		}
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+)
+
+const sample = `provider "openai" {
+  defaults {
+    auth {
+      oauth_content_type form;
+    }
+    metrics {
+      usage_extract openai;
+    }
+  }
+  match api = "chat.completions" stream=false {
+    upstream {
+      set_path "/v1/chat/completions";
+    }
+  }
+  match api = "embeddings" {
+    upstream {
+      set_path "/v1/embeddings";
+    }
+  }
+}
+provider "azure" {
+  defaults {
+    auth {
+      oauth_content_type json;
+    }
+    metrics {
+      usage_extract custom_usage;
+    }
+  }
+}
+`
+
+func selectContexts(t *testing.T, expr string) []string {
+	t.Helper()
+	sel, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", expr, err)
+	}
+	var out []string
+	for _, m := range sel.Select(dslast.Parse(sample)) {
+		out = append(out, m.Context())
+	}
+	return out
+}
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]string{
+		"provider[*].defaults.auth.oauth_content_type[form]": {
+			`provider "openai" > defaults > auth > oauth_content_type form`,
+		},
+		`provider[azure].defaults.auth.oauth_content_type`: {
+			`provider "azure" > defaults > auth > oauth_content_type json`,
+		},
+		`provider.match[api="chat.*"][stream=false].upstream.set_path`: {
+			`provider "openai" > match api = "chat.completions" stream=false > upstream > set_path "/v1/chat/completions"`,
+		},
+		`**.set_path[1="/v1/e*"]`: {
+			`provider "openai" > match api = "embeddings" > upstream > set_path "/v1/embeddings"`,
+		},
+		`**.usage_extract[1!=openai]`: {
+			`provider "azure" > defaults > metrics > usage_extract custom_usage`,
+		},
+		`provider[openai].defaults.**`: {
+			`provider "openai" > defaults > auth`,
+			`provider "openai" > defaults > auth > oauth_content_type form`,
+			`provider "openai" > defaults > metrics`,
+			`provider "openai" > defaults > metrics > usage_extract openai`,
+		},
+		`provider[*].*.oauth_*`: nil,
+		`provider[gemini]`:      nil,
+	}
+	for expr, want := range cases {
+		if got := selectContexts(t, expr); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s:\n got: %q\nwant: %q", expr, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{"", "provider.", "provider[", `provider["x]`, "provider[0=x]", "provider[=x]", "a]b"} {
+		if _, err := Parse(expr); err == nil {
+			t.Fatalf("expected error for %q", expr)
+		}
+	}
+}
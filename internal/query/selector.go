@@ -0,0 +1,294 @@
+// Package query selects statements from parsed DSL documents with a small
+// path language:
+//
+//	provider[*].defaults.auth.oauth_content_type[form]
+//	provider[openai].match[api="chat.*"].upstream.set_path
+//	**.usage_extract[1!=openai]
+//
+// A selector is a dot-separated list of steps. Each step matches statements
+// by name (`*` matches any name, and names may use `*` and `?` globs) in the
+// blocks matched by the previous step, starting at file level. `**` matches
+// any number of nested blocks, including none. Filters in brackets narrow a
+// step further; all filters must hold:
+//
+//	[*]           any statement
+//	[value]       first argument equals value
+//	[2=value]     second argument equals value (1-based)
+//	[key=value]   a `key = value` or `key=value` argument pair
+//	[a!=b]        negates either of the two forms above
+//
+// Values may be quoted and may use `*` and `?` globs; arguments are compared
+// without their quotes.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+)
+
+// Selector is a parsed selector expression.
+type Selector struct {
+	expr  string
+	steps []step
+}
+
+type step struct {
+	// descend is set for `**`.
+	descend bool
+	literal string
+	name    *regexp.Regexp
+	filters []filter
+}
+
+type filter struct {
+	// index is the 1-based argument position, or 0 for first-argument and
+	// key/value filters.
+	index  int
+	key    string
+	raw    string
+	value  *regexp.Regexp
+	negate bool
+}
+
+// String returns the selector source.
+func (s *Selector) String() string {
+	return s.expr
+}
+
+// Parse parses a selector expression.
+func Parse(expr string) (*Selector, error) {
+	parts, err := splitOutside(strings.TrimSpace(expr), '.')
+	if err != nil {
+		return nil, err
+	}
+	sel := &Selector{expr: expr}
+	for _, part := range parts {
+		st, err := parseStep(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", expr, err)
+		}
+		sel.steps = append(sel.steps, st)
+	}
+	return sel, nil
+}
+
+func parseStep(part string) (step, error) {
+	if part == "" {
+		return step{}, fmt.Errorf("empty step")
+	}
+	if part == "**" {
+		return step{descend: true}, nil
+	}
+	name, rest, _ := strings.Cut(part, "[")
+	if name == "" || strings.ContainsAny(name, "]\"'=") {
+		return step{}, fmt.Errorf("invalid step %q", part)
+	}
+	st := step{literal: name, name: glob(name)}
+	if rest == "" {
+		return st, nil
+	}
+	rest = "[" + rest
+	for rest != "" {
+		end := closingBracket(rest)
+		if rest[0] != '[' || end < 0 {
+			return step{}, fmt.Errorf("unbalanced brackets in %q", part)
+		}
+		f, err := parseFilter(strings.TrimSpace(rest[1:end]))
+		if err != nil {
+			return step{}, err
+		}
+		if f != nil {
+			st.filters = append(st.filters, *f)
+		}
+		rest = rest[end+1:]
+	}
+	return st, nil
+}
+
+// parseFilter parses the inside of one bracket; `*` yields no filter.
+func parseFilter(src string) (*filter, error) {
+	if src == "" {
+		return nil, fmt.Errorf("empty filter")
+	}
+	if src == "*" {
+		return nil, nil
+	}
+	lhs, rhs, negate, ok := cutOperator(src)
+	if !ok {
+		raw := dslast.Unquote(src)
+		return &filter{raw: raw, value: glob(raw)}, nil
+	}
+	lhs, rhs = strings.TrimSpace(lhs), strings.TrimSpace(rhs)
+	if lhs == "" {
+		return nil, fmt.Errorf("missing argument name in filter %q", src)
+	}
+	raw := dslast.Unquote(rhs)
+	f := &filter{raw: raw, value: glob(raw), negate: negate}
+	if n, err := strconv.Atoi(lhs); err == nil {
+		if n < 1 {
+			return nil, fmt.Errorf("argument positions start at 1 in filter %q", src)
+		}
+		f.index = n
+	} else {
+		f.key = dslast.Unquote(lhs)
+	}
+	return f, nil
+}
+
+// Len returns the number of steps.
+func (s *Selector) Len() int {
+	return len(s.steps)
+}
+
+// Prefix returns a selector made of the first n steps.
+func (s *Selector) Prefix(n int) *Selector {
+	n = max(0, min(n, len(s.steps)))
+	parts, _ := splitOutside(strings.TrimSpace(s.expr), '.')
+	return &Selector{expr: strings.Join(parts[:n], "."), steps: s.steps[:n]}
+}
+
+// Header returns the statement header that step i pins down, such as
+// `provider "openai"` or `match api = "chat.completions"`, so a missing block
+// can be created from it. It fails for `**`, globs, negated filters and
+// argument positions other than the first.
+func (s *Selector) Header(i int) (string, error) {
+	st := s.steps[i]
+	if st.descend || strings.ContainsAny(st.literal, "*?") {
+		return "", fmt.Errorf("step %d of %q does not name one statement", i+1, s.expr)
+	}
+	header := st.literal
+	var first string
+	var pairs []string
+	for _, f := range st.filters {
+		switch {
+		case f.negate || strings.ContainsAny(f.raw, "*?"):
+			return "", fmt.Errorf("filter on %s in %q does not pin one value", st.literal, s.expr)
+		case f.key != "":
+			pairs = append(pairs, f.key+" = "+strconv.Quote(f.raw))
+		case f.index > 1:
+			return "", fmt.Errorf("cannot create %s from a filter on argument %d", st.literal, f.index)
+		default:
+			first = strconv.Quote(f.raw)
+		}
+	}
+	if first != "" {
+		header += " " + first
+	}
+	for _, p := range pairs {
+		header += " " + p
+	}
+	return header, nil
+}
+
+// Name returns the literal statement name of step i, or "" for `**` and
+// globs.
+func (s *Selector) Name(i int) string {
+	st := s.steps[i]
+	if st.descend || strings.ContainsAny(st.literal, "*?") {
+		return ""
+	}
+	return st.literal
+}
+
+// HasFilters reports whether step i has bracket filters other than `[*]`.
+func (s *Selector) HasFilters(i int) bool {
+	return len(s.steps[i].filters) > 0
+}
+
+// cutOperator splits `a=b` or `a!=b` at the first operator outside quotes.
+func cutOperator(src string) (lhs, rhs string, negate, ok bool) {
+	var quote byte
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '!' && i+1 < len(src) && src[i+1] == '=':
+			return src[:i], src[i+2:], true, true
+		case c == '=':
+			return src[:i], src[i+1:], false, true
+		}
+	}
+	return "", "", false, false
+}
+
+// splitOutside splits s at sep outside brackets and quotes.
+func splitOutside(s string, sep byte) ([]string, error) {
+	var out []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == sep && depth == 0:
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	if quote != 0 || depth != 0 {
+		return nil, fmt.Errorf("invalid selector %q: unbalanced quotes or brackets", s)
+	}
+	return append(out, s[start:]), nil
+}
+
+// closingBracket returns the index of the ']' matching s[0], skipping quotes.
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// glob compiles a pattern where `*` matches any run of characters and `?`
+// any single character.
+func glob(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
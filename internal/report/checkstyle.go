@@ -0,0 +1,61 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func writeCheckstyle(w io.Writer, res check.Result) error {
+	out := checkstyleReport{Version: "4.3"}
+	for _, file := range res.Files {
+		cf := checkstyleFile{Name: displayPath(file.Path)}
+		for _, d := range file.Diagnostics {
+			source := toolName
+			if d.Code != "" {
+				source += "." + d.Code
+			}
+			cf.Errors = append(cf.Errors, checkstyleError{
+				Line:     d.Range.Start.Line + 1,
+				Column:   d.Range.Start.Character + 1,
+				Severity: checkstyleSeverity(d.Severity),
+				Message:  d.Message,
+				Source:   source,
+			})
+		}
+		out.Files = append(out.Files, cf)
+	}
+	return writeXML(w, out)
+}
+
+func checkstyleSeverity(severity int) string {
+	switch lint.SeverityName(severity) {
+	case "warning":
+		return "warning"
+	case "info", "hint":
+		return "info"
+	default:
+		return "error"
+	}
+}
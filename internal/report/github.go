@@ -0,0 +1,65 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+// writeGitHub emits GitHub Actions workflow commands, which Gitea runners
+// also understand.
+func writeGitHub(w io.Writer, res check.Result) error {
+	for _, file := range res.Files {
+		for _, d := range file.Diagnostics {
+			_, err := fmt.Fprintf(
+				w,
+				"::%s file=%s,line=%d,col=%d,endLine=%d,endColumn=%d,title=%s::%s\n",
+				githubCommand(d.Severity),
+				escapeGitHubProperty(displayPath(file.Path)),
+				d.Range.Start.Line+1,
+				d.Range.Start.Character+1,
+				d.Range.End.Line+1,
+				d.Range.End.Character+1,
+				escapeGitHubProperty(githubTitle(d.Code)),
+				escapeGitHubData(d.Message),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func githubTitle(code string) string {
+	if code == "" {
+		return toolName
+	}
+	return toolName + "/" + code
+}
+
+func githubCommand(severity int) string {
+	switch lint.SeverityName(severity) {
+	case "warning":
+		return "warning"
+	case "info", "hint":
+		return "notice"
+	default:
+		return "error"
+	}
+}
+
+func escapeGitHubData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+func escapeGitHubProperty(s string) string {
+	s = escapeGitHubData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
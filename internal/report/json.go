@@ -0,0 +1,88 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+type jsonReport struct {
+	Files   []jsonFile  `json:"files"`
+	Summary jsonSummary `json:"summary"`
+}
+
+type jsonFile struct {
+	Path        string           `json:"path"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+}
+
+// jsonDiagnostic keeps the zero-based LSP range and adds a severity name so
+// consumers don't need to know the LSP numbering.
+type jsonDiagnostic struct {
+	Range    dsllang.Range `json:"range"`
+	Severity string        `json:"severity"`
+	Code     string        `json:"code,omitempty"`
+	Source   string        `json:"source,omitempty"`
+	Message  string        `json:"message"`
+	Related  []jsonRelated `json:"related,omitempty"`
+}
+
+// jsonRelated is another location that explains a diagnostic, such as the
+// second declaration of a duplicate provider.
+type jsonRelated struct {
+	Path    string        `json:"path"`
+	Range   dsllang.Range `json:"range"`
+	Message string        `json:"message"`
+}
+
+type jsonSummary struct {
+	Files    int `json:"files"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Infos    int `json:"infos"`
+	Hints    int `json:"hints"`
+}
+
+func writeJSON(w io.Writer, res check.Result) error {
+	out := jsonReport{Files: make([]jsonFile, 0, len(res.Files))}
+	out.Summary.Files = len(res.Files)
+	for _, file := range res.Files {
+		jf := jsonFile{Path: displayPath(file.Path), Diagnostics: make([]jsonDiagnostic, 0, len(file.Diagnostics))}
+		for _, d := range file.Diagnostics {
+			sev := lint.SeverityName(d.Severity)
+			switch sev {
+			case "warning":
+				out.Summary.Warnings++
+			case "info":
+				out.Summary.Infos++
+			case "hint":
+				out.Summary.Hints++
+			default:
+				out.Summary.Errors++
+			}
+			jd := jsonDiagnostic{
+				Range:    d.Range,
+				Severity: sev,
+				Code:     d.Code,
+				Source:   d.Source,
+				Message:  d.Message,
+			}
+			for _, rel := range d.RelatedInformation {
+				jd.Related = append(jd.Related, jsonRelated{
+					Path:    relatedPath(rel.Location.URI),
+					Range:   rel.Location.Range,
+					Message: rel.Message,
+				})
+			}
+			jf.Diagnostics = append(jf.Diagnostics, jd)
+		}
+		out.Files = append(out.Files, jf)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
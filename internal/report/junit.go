@@ -0,0 +1,87 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit reports one test case per file. A file with diagnostics fails and
+// lists every diagnostic in the failure body.
+func writeJUnit(w io.Writer, res check.Result) error {
+	suite := junitTestSuite{Name: toolName + " check", Tests: len(res.Files)}
+	for _, file := range res.Files {
+		tc := junitTestCase{Name: displayPath(file.Path), ClassName: toolName}
+		if len(file.Diagnostics) > 0 {
+			suite.Failures++
+			lines := make([]string, 0, len(file.Diagnostics))
+			worst := lint.SeverityHint
+			for _, d := range file.Diagnostics {
+				sev := d.Severity
+				if sev <= 0 {
+					sev = lint.SeverityError
+				}
+				if sev < worst {
+					worst = sev
+				}
+				lines = append(lines, fmt.Sprintf(
+					"%s:%d:%d: %s: %s%s",
+					displayPath(file.Path),
+					d.Range.Start.Line+1,
+					d.Range.Start.Character+1,
+					lint.SeverityName(d.Severity),
+					d.Message,
+					codeSuffix(d.Code),
+				))
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d problem(s)", len(file.Diagnostics)),
+				Type:    lint.SeverityName(worst),
+				Body:    strings.Join(lines, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	return writeXML(w, junitTestSuites{Suites: []junitTestSuite{suite}})
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+)
+
+// Tool identity reported by machine-readable encoders.
+const (
+	toolName = "onr-lsp"
+	toolURI  = "https://github.com/r9s-ai/onr-lsp"
+)
+
+type encoder func(w io.Writer, res check.Result) error
+
+var encoders = map[string]encoder{
+	"text":       writeText,
+	"json":       writeJSON,
+	"sarif":      writeSARIF,
+	"junit":      writeJUnit,
+	"github":     writeGitHub,
+	"checkstyle": writeCheckstyle,
+}
+
+// Formats returns supported output format names in display order.
+func Formats() []string {
+	return []string{"text", "json", "sarif", "junit", "github", "checkstyle"}
+}
+
+// Supported reports whether format names a known output format.
+func Supported(format string) bool {
+	_, ok := encoders[strings.ToLower(strings.TrimSpace(format))]
+	return ok
+}
+
+// Write encodes res to w using the named output format.
+func Write(w io.Writer, format string, res check.Result) error {
+	enc, ok := encoders[strings.ToLower(strings.TrimSpace(format))]
+	if !ok {
+		return fmt.Errorf("unsupported output format %q (want %s)", format, strings.Join(Formats(), ", "))
+	}
+	return enc(w, res)
+}
+
+// getwd returns the directory report paths are relative to; tests replace
+// it to get stable output.
+var getwd = os.Getwd
+
+// codeSuffix returns the " [rule-id]" suffix used by line-oriented formats.
+func codeSuffix(code string) string {
+	if code == "" {
+		return ""
+	}
+	return " [" + code + "]"
+}
+
+// displayPath returns path with forward slashes for tools that expect URIs or
+// repository-relative paths.
+func displayPath(path string) string {
+	return strings.ReplaceAll(path, "\\", "/")
+}
+
+// relatedPath turns a related-information URI back into a display path,
+// relative to the working directory when the file lies below it.
+func relatedPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := filepath.FromSlash(u.Path)
+	if wd, err := getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return displayPath(path)
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestMain(m *testing.M) {
+	getwd = func() (string, error) { return "/work/repo", nil }
+	os.Exit(m.Run())
+}
+
+func sampleResult() check.Result {
+	return check.Result{Files: []check.FileResult{
+		{
+			Path: "providers/bad.conf",
+			Diagnostics: []lint.Diagnostic{
+				{
+					Range:    dsllang.Range{Start: dsllang.Position{Line: 2, Character: 22}, End: dsllang.Position{Line: 2, Character: 26}},
+					Severity: lint.SeverityError,
+					Code:     lint.RuleUnsupportedMode,
+					Source:   "onr-lsp",
+					Message:  `unsupported req_map mode "nope"`,
+				},
+				{
+					Range:    dsllang.Range{Start: dsllang.Position{Line: 4, Character: 4}, End: dsllang.Position{Line: 4, Character: 5}},
+					Severity: lint.SeverityWarning,
+					Code:     lint.RuleSemanticError,
+					Source:   "onr-lsp",
+					Message:  "50% done, a:b <x> & \"y\"\nsecond line",
+				},
+			},
+		},
+		{
+			Path:        "providers/openai.conf",
+			Diagnostics: nil,
+		},
+		{
+			Path: "modes/usage.conf",
+			Diagnostics: []lint.Diagnostic{
+				{
+					Range:    dsllang.Range{Start: dsllang.Position{Line: 0, Character: 0}, End: dsllang.Position{Line: 0, Character: 1}},
+					Severity: lint.SeverityHint,
+					Message:  "hint only",
+				},
+			},
+		},
+	}}
+}
+
+func TestWriteGolden(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			if err := Write(&out, format, sampleResult()); err != nil {
+				t.Fatalf("write %s: %v", format, err)
+			}
+			golden := filepath.Join("testdata", format+".golden")
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+					t.Fatalf("update golden: %v", err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			if out.String() != string(want) {
+				t.Fatalf("unexpected %s output\n--- got ---\n%s\n--- want ---\n%s", format, out.String(), string(want))
+			}
+		})
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	t.Parallel()
+
+	err := Write(&bytes.Buffer{}, "yaml", check.Result{})
+	if err == nil || !strings.Contains(err.Error(), "unsupported output format") {
+		t.Fatalf("expected unsupported format error, got: %v", err)
+	}
+}
+
+func TestWriteEmptyResult(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := Write(&out, "sarif", check.Result{}); err != nil {
+		t.Fatalf("write sarif: %v", err)
+	}
+	if !strings.Contains(out.String(), `"results": []`) {
+		t.Fatalf("expected empty SARIF results array, got:\n%s", out.String())
+	}
+}
+
+func TestSARIFLocatesFilesAgainstSourceRoot(t *testing.T) {
+	t.Parallel()
+
+	res := check.Result{Files: []check.FileResult{{
+		Path: "/elsewhere/my provider.conf",
+		Diagnostics: []lint.Diagnostic{{
+			Message: "outside",
+			RelatedInformation: []lint.RelatedInformation{{
+				Location: lint.Location{URI: "file:///work/repo/providers/a%20b.conf"},
+				Message:  "inside",
+			}},
+		}},
+	}}}
+	var out bytes.Buffer
+	if err := Write(&out, "sarif", res); err != nil {
+		t.Fatalf("write sarif: %v", err)
+	}
+	for _, want := range []string{
+		`"originalUriBaseIds": {
+        "%SRCROOT%": {
+          "uri": "file:///work/repo/"
+        }
+      }`,
+		`"uri": "file:///elsewhere/my%20provider.conf"
+`,
+		`"uri": "providers/a%20b.conf",
+                  "uriBaseId": "%SRCROOT%"`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %s in:\n%s", want, out.String())
+		}
+	}
+}
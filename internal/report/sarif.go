@@ -0,0 +1,191 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// sarifSrcRoot is the uriBaseId of files below the working directory, so
+	// code scanning tools resolve them against the checkout.
+	sarifSrcRoot = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	// RelatedLocations carry LSP related information.
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+// sarifArtifactLocation is a relative URI against URIBaseID, or an absolute
+// file URI for files outside the working directory.
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// sarifRegion uses SARIF's one-based lines and columns.
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+func writeSARIF(w io.Writer, res check.Result) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: toolName, InformationURI: toolURI}},
+		Results: []sarifResult{},
+	}
+	root, err := getwd()
+	if err == nil {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			sarifSrcRoot: {URI: strings.TrimSuffix(check.FileURI(root), "/") + "/"},
+		}
+	}
+	ruleIndex := map[string]int{}
+	for _, file := range res.Files {
+		for _, d := range file.Diagnostics {
+			result := sarifResult{
+				RuleID:  d.Code,
+				Level:   sarifLevel(d.Severity),
+				Message: sarifMessage{Text: d.Message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: newSARIFArtifact(root, file.Path),
+						Region:           newSARIFRegion(d.Range),
+					},
+				}},
+			}
+			for i, rel := range d.RelatedInformation {
+				id := i + 1
+				result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+					ID: &id,
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: relatedSARIFArtifact(root, rel.Location.URI),
+						Region:           newSARIFRegion(rel.Location.Range),
+					},
+					Message: &sarifMessage{Text: rel.Message},
+				})
+			}
+			if d.Code != "" {
+				idx, ok := ruleIndex[d.Code]
+				if !ok {
+					idx = len(run.Tool.Driver.Rules)
+					ruleIndex[d.Code] = idx
+					desc := d.Code
+					if r, ok := lint.LookupRule(d.Code); ok {
+						desc = r.Description
+					}
+					run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: d.Code, ShortDescription: sarifMessage{Text: desc}})
+				}
+				result.RuleIndex = &idx
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// newSARIFArtifact locates path relative to root when it lies below it. An
+// empty root means the working directory is unknown.
+func newSARIFArtifact(root, path string) sarifArtifactLocation {
+	if root == "" {
+		return sarifArtifactLocation{URI: check.FileURI(path)}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return sarifArtifactLocation{URI: check.FileURI(path)}
+	}
+	return sarifArtifactLocation{URI: (&url.URL{Path: filepath.ToSlash(rel)}).String(), URIBaseID: sarifSrcRoot}
+}
+
+// relatedSARIFArtifact locates a related-information URI like a result file.
+func relatedSARIFArtifact(root, uri string) sarifArtifactLocation {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return sarifArtifactLocation{URI: uri}
+	}
+	return newSARIFArtifact(root, filepath.FromSlash(u.Path))
+}
+
+func newSARIFRegion(r dsllang.Range) sarifRegion {
+	return sarifRegion{
+		StartLine:   r.Start.Line + 1,
+		StartColumn: r.Start.Character + 1,
+		EndLine:     r.End.Line + 1,
+		EndColumn:   r.End.Character + 1,
+	}
+}
+
+func sarifLevel(severity int) string {
+	switch lint.SeverityName(severity) {
+	case "warning":
+		return "warning"
+	case "info", "hint":
+		return "note"
+	default:
+		return "error"
+	}
+}
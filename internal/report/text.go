@@ -0,0 +1,30 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/r9s-ai/onr-lsp/internal/check"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+func writeText(w io.Writer, res check.Result) error {
+	for _, file := range res.Files {
+		for _, d := range file.Diagnostics {
+			_, err := fmt.Fprintf(
+				w,
+				"%s:%d:%d: %s: %s%s\n",
+				file.Path,
+				d.Range.Start.Line+1,
+				d.Range.Start.Character+1,
+				lint.SeverityName(d.Severity),
+				d.Message,
+				codeSuffix(d.Code),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package rewrite
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `provider "p" {
+  defaults {
+    request {
+      req_map openai_chat; # legacy
+      set_header X-A "1";
+    }
+  }
+  match api = "chat.completions" {
+    request {
+      req_map "openai_chat";
+      set_header X-B "2" "extra";
+    }
+  }
+}
+`
+
+func apply(t *testing.T, src string, rules ...string) (string, int) {
+	t.Helper()
+	total := 0
+	for _, s := range rules {
+		r, err := ParseRule(s)
+		if err != nil {
+			t.Fatalf("ParseRule(%q): %v", s, err)
+		}
+		var n int
+		src, n = r.Apply(src)
+		total += n
+	}
+	return src, total
+}
+
+func TestApplyRewritesEveryMatch(t *testing.T) {
+	t.Parallel()
+
+	got, n := apply(t, sample, "req_map openai_chat -> req_map openai_chat_to_openai_responses")
+	if n != 2 || strings.Count(got, "req_map openai_chat_to_openai_responses;") != 2 || !strings.Contains(got, "; # legacy") {
+		t.Fatalf("unexpected rewrite (%d):\n%s", n, got)
+	}
+}
+
+func TestApplyScopesToBlockPath(t *testing.T) {
+	t.Parallel()
+
+	got, n := apply(t, sample, "provider.match.request.req_map $m -> req_map openai_chat_to_openai_responses")
+	if n != 1 || !strings.Contains(got, "      req_map openai_chat; # legacy") {
+		t.Fatalf("expected only the match block to change (%d):\n%s", n, got)
+	}
+}
+
+func TestApplyBindsWildcards(t *testing.T) {
+	t.Parallel()
+
+	got, n := apply(t, sample, `set_header $name $value... -> set_header $name "0"`)
+	if n != 2 || !strings.Contains(got, `set_header X-A "0";`) || !strings.Contains(got, `set_header X-B "0";`) {
+		t.Fatalf("unexpected rewrite (%d):\n%s", n, got)
+	}
+
+	got, n = apply(t, sample, `set_header $name $value -> del_header $name`)
+	if n != 1 || !strings.Contains(got, "del_header X-A;") || !strings.Contains(got, `set_header X-B "2" "extra";`) {
+		t.Fatalf("$value must match exactly one argument (%d):\n%s", n, got)
+	}
+
+	got, n = apply(t, sample, `match api = $api -> match api = $api stream = true`)
+	if n != 1 || !strings.Contains(got, `match api = "chat.completions" stream = true {`) {
+		t.Fatalf("unexpected header rewrite (%d):\n%s", n, got)
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	t.Parallel()
+
+	for src, want := range map[string]string{
+		"req_map openai_chat":           "want 'pattern -> replacement'",
+		"-> req_map x":                  "empty pattern",
+		"req_map $a -> req_map $b":      "$b is not bound",
+		"req_map $a... $b -> req_map":   "must be the last argument",
+		"req_map $a $a -> req_map $a":   "bound twice",
+		"provider.** -> req_map x":      "must end in a directive name",
+		"req_map x -> provider.req_map": "replacement must start with a directive name",
+	} {
+		if _, err := ParseRule(src); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected %q, got %v", src, want, err)
+		}
+	}
+}
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	newText := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	want := `--- a/x.conf
++++ b/x.conf
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+	if got := Unified("x.conf", oldText, newText); got != want {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+	if got := Unified("x.conf", oldText, oldText); got != "" {
+		t.Fatalf("expected no diff for equal text, got:\n%s", got)
+	}
+}
@@ -0,0 +1,218 @@
+// Package rewrite applies structural search-and-replace rules to DSL text,
+// in the spirit of `gofmt -r`.
+//
+// A rule is `pattern -> replacement`. The pattern starts with a directive
+// name, or with a query selector ending in one to restrict where it applies,
+// followed by argument patterns:
+//
+//	req_map openai_chat -> req_map openai_chat_to_openai_responses
+//	provider[*].match.request.req_map $m -> resp_map $m
+//	set_header $name $value... -> header_set $name $value...
+//
+// A plain name matches the directive at any depth. `$x` matches exactly one
+// argument and `$x...` any remaining arguments; other arguments must match
+// exactly, ignoring quotes. The replacement is a directive name followed by
+// literal arguments and wildcards bound by the pattern. Only the name and
+// arguments are rewritten, so terminators, block bodies and comments stay.
+package rewrite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/query"
+)
+
+// Rule is a parsed rewrite rule.
+type Rule struct {
+	src     string
+	sel     *query.Selector
+	pattern []patternArg
+	name    string
+	replace []patternArg
+}
+
+type patternArg struct {
+	// wildcard is the name after `$`, or "" for a literal.
+	wildcard string
+	rest     bool
+	raw      string
+}
+
+// String returns the rule source.
+func (r *Rule) String() string {
+	return r.src
+}
+
+// ParseRule parses `pattern -> replacement`.
+func ParseRule(src string) (*Rule, error) {
+	lhs, rhs, ok := strings.Cut(src, "->")
+	if !ok {
+		return nil, fmt.Errorf("invalid rewrite rule %q: want 'pattern -> replacement'", src)
+	}
+	head, args := splitHead(strings.TrimSpace(lhs))
+	if head == "" {
+		return nil, fmt.Errorf("invalid rewrite rule %q: empty pattern", src)
+	}
+	if !strings.Contains(head, ".") {
+		head = "**." + head
+	}
+	sel, err := query.Parse(head)
+	if err != nil {
+		return nil, err
+	}
+	if sel.Name(sel.Len()-1) == "" {
+		return nil, fmt.Errorf("invalid rewrite rule %q: pattern must end in a directive name", src)
+	}
+	r := &Rule{src: src, sel: sel}
+	if r.pattern, err = parseArgs(args, nil); err != nil {
+		return nil, fmt.Errorf("invalid rewrite rule %q: %w", src, err)
+	}
+	bound := map[string]bool{}
+	for _, a := range r.pattern {
+		if a.wildcard != "" {
+			if bound[a.wildcard] {
+				return nil, fmt.Errorf("invalid rewrite rule %q: $%s is bound twice", src, a.wildcard)
+			}
+			bound[a.wildcard] = true
+		}
+	}
+	r.name, args = splitHead(strings.TrimSpace(rhs))
+	if r.name == "" || strings.ContainsAny(r.name, ".[]$") {
+		return nil, fmt.Errorf("invalid rewrite rule %q: replacement must start with a directive name", src)
+	}
+	if r.replace, err = parseArgs(args, bound); err != nil {
+		return nil, fmt.Errorf("invalid rewrite rule %q: %w", src, err)
+	}
+	return r, nil
+}
+
+// splitHead splits the first word, which may contain quoted selector
+// filters, from the arguments that follow it.
+func splitHead(s string) (head, rest string) {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0 && (c == ' ' || c == '\t'):
+			return s[:i], strings.TrimSpace(s[i+1:])
+		}
+	}
+	return s, ""
+}
+
+// parseArgs tokenizes arguments like the DSL does. When bound is non-nil,
+// wildcards must be in it.
+func parseArgs(src string, bound map[string]bool) ([]patternArg, error) {
+	if src == "" {
+		return nil, nil
+	}
+	f := dslast.Parse("_ " + src + ";")
+	if len(f.Statements) != 1 || f.Statements[0].IsBlock() {
+		return nil, fmt.Errorf("cannot parse arguments %q", src)
+	}
+	args := f.Statements[0].Args
+	out := make([]patternArg, 0, len(args))
+	for i, a := range args {
+		p := patternArg{raw: a.Raw}
+		if !a.Quoted && strings.HasPrefix(a.Raw, "$") && len(a.Raw) > 1 {
+			p.wildcard = strings.TrimPrefix(a.Raw, "$")
+			if name, ok := strings.CutSuffix(p.wildcard, "..."); ok {
+				if bound == nil && i != len(args)-1 {
+					return nil, fmt.Errorf("%s must be the last argument", a.Raw)
+				}
+				p.wildcard, p.rest = name, true
+			}
+			if bound != nil && !bound[p.wildcard] {
+				return nil, fmt.Errorf("%s is not bound by the pattern", a.Raw)
+			}
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Apply rewrites every matching statement and returns the new text and the
+// number of rewrites.
+func (r *Rule) Apply(src string) (string, int) {
+	type textEdit struct {
+		start, end int
+		text       string
+	}
+	var edits []textEdit
+	for _, m := range r.sel.Select(dslast.Parse(src)) {
+		bindings, ok := r.match(m.Stmt.Args)
+		if !ok {
+			continue
+		}
+		end := m.Stmt.Start + len(m.Stmt.Name)
+		if n := len(m.Stmt.Args); n > 0 {
+			end = m.Stmt.Args[n-1].End
+		}
+		text := r.render(bindings)
+		if text == src[m.Stmt.Start:end] {
+			continue
+		}
+		edits = append(edits, textEdit{start: m.Stmt.Start, end: end, text: text})
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		src = src[:e.start] + e.text + src[e.end:]
+	}
+	return src, len(edits)
+}
+
+// match binds wildcards to the source text of arguments.
+func (r *Rule) match(args []*dslast.Arg) (map[string]string, bool) {
+	bindings := map[string]string{}
+	for i, p := range r.pattern {
+		if p.rest {
+			raws := make([]string, 0, len(args)-i)
+			for _, a := range args[i:] {
+				raws = append(raws, a.Raw)
+			}
+			bindings[p.wildcard] = strings.Join(raws, " ")
+			return bindings, true
+		}
+		if i >= len(args) {
+			return nil, false
+		}
+		if p.wildcard != "" {
+			bindings[p.wildcard] = args[i].Raw
+			continue
+		}
+		if dslast.Unquote(p.raw) != args[i].Value() {
+			return nil, false
+		}
+	}
+	return bindings, len(args) == len(r.pattern)
+}
+
+func (r *Rule) render(bindings map[string]string) string {
+	parts := []string{r.name}
+	for _, p := range r.replace {
+		text := p.raw
+		if p.wildcard != "" {
+			text = bindings[p.wildcard]
+		}
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
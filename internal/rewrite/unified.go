@@ -0,0 +1,114 @@
+package rewrite
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines around each hunk.
+const contextLines = 3
+
+// Unified returns a unified diff of two versions of path, or "" when they
+// are equal.
+func Unified(path, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	a, b := splitLines(oldText), splitLines(newText)
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", path, path)
+	for start := 0; start < len(ops); {
+		// Find the next change and the extent of its hunk.
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*contextLines {
+				break
+			}
+		}
+		from, to := max(0, start-contextLines), min(len(ops), end+contextLines)
+		oldStart, newStart := ops[from].oldLine, ops[from].newLine
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, op := range ops[from:to] {
+			body.WriteString(string(op.kind) + op.text + "\n")
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		out.WriteString(body.String())
+		start = to
+	}
+	return out.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lineOp is one line of a diff; oldLine and newLine are the zero-based
+// positions before the op in each version.
+type lineOp struct {
+	kind    byte
+	text    string
+	oldLine int
+	newLine int
+}
+
+// diffLines computes a shortest line edit script from the longest common
+// subsequence of a and b.
+func diffLines(a, b []string) []lineOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var ops []lineOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, lineOp{kind: ' ', text: a[i], oldLine: i, newLine: j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, lineOp{kind: '-', text: a[i], oldLine: i, newLine: j})
+			i++
+		default:
+			ops = append(ops, lineOp{kind: '+', text: b[j], oldLine: i, newLine: j})
+			j++
+		}
+	}
+	return ops
+}
@@ -0,0 +1,201 @@
+// Package scaffold renders new provider files from built-in or user
+// templates.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dslspec"
+)
+
+// TemplateExt is the file extension of provider templates. A template for
+// family "acme" is named "acme.conf.tmpl".
+const TemplateExt = ".conf.tmpl"
+
+//go:embed templates/*.conf.tmpl
+var builtinFS embed.FS
+
+// Data is passed to provider templates.
+type Data struct {
+	// Name is the provider name, also the file name without ".conf".
+	Name string
+	// Family is the template name, e.g. "anthropic"; Title is its display form.
+	Family string
+	Title  string
+	// BaseURL is the upstream base URL.
+	BaseURL string
+	// ReqMap, RespMap and SSEParse are the built-in modes that convert between
+	// OpenAI chat and the family's API, or "" for the openai family.
+	ReqMap   string
+	RespMap  string
+	SSEParse string
+}
+
+// Options controls Render.
+type Options struct {
+	Name   string
+	Family string
+	// BaseURL overrides the family's default upstream base URL.
+	BaseURL string
+	// TemplateDirs are searched, in order, before the built-in templates.
+	TemplateDirs []string
+}
+
+type family struct {
+	title   string
+	baseURL string
+}
+
+var families = map[string]family{
+	"openai":    {title: "OpenAI", baseURL: "https://api.openai.com"},
+	"anthropic": {title: "Anthropic", baseURL: "https://api.anthropic.com"},
+	"gemini":    {title: "Gemini", baseURL: "https://generativelanguage.googleapis.com"},
+}
+
+var providerNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ValidName reports an error unless name is a valid onr-core provider name.
+func ValidName(name string) error {
+	if !providerNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid provider name %q, expected pattern %s", name, providerNamePattern.String())
+	}
+	return nil
+}
+
+// NameFromPath returns the provider name a file at path must declare.
+func NameFromPath(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), ".conf")
+}
+
+// Families returns the families with a template in dirs or built in, sorted.
+func Families(dirs []string) []string {
+	var out []string
+	add := func(names []string) {
+		for _, n := range names {
+			if strings.HasSuffix(n, TemplateExt) && !slices.Contains(out, strings.TrimSuffix(n, TemplateExt)) {
+				out = append(out, strings.TrimSuffix(n, TemplateExt))
+			}
+		}
+	}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+		add(names)
+	}
+	entries, _ := builtinFS.ReadDir("templates")
+	for _, e := range entries {
+		add([]string{e.Name()})
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Render executes the family template for opts and formats the result.
+func Render(opts Options) (string, error) {
+	if err := ValidName(opts.Name); err != nil {
+		return "", err
+	}
+	src, err := loadTemplate(opts.Family, opts.TemplateDirs)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(opts.Family + TemplateExt).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("parse template %q: %w", opts.Family, err)
+	}
+	data, err := dataFor(opts)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render template %q: %w", opts.Family, err)
+	}
+	return dsllang.FormatText(buf.String(), dsllang.FormatOptions{TabSize: 2, InsertSpaces: true}), nil
+}
+
+// Validate returns the error diagnostics of text as if it were saved at uri.
+func Validate(uri, text string) []dsllang.Diagnostic {
+	var out []dsllang.Diagnostic
+	for _, d := range dsllang.CollectDiagnostics(uri, text) {
+		if d.Severity == 1 {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func loadTemplate(name string, dirs []string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid family %q", name)
+	}
+	for _, dir := range dirs {
+		data, err := os.ReadFile(filepath.Join(dir, name+TemplateExt))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("read template: %w", err)
+		}
+	}
+	data, err := builtinFS.ReadFile("templates/" + name + TemplateExt)
+	if err != nil {
+		return "", fmt.Errorf("unknown family %q (known: %s)", name, strings.Join(Families(dirs), ", "))
+	}
+	return string(data), nil
+}
+
+func dataFor(opts Options) (Data, error) {
+	f, builtin := families[opts.Family]
+	data := Data{Name: opts.Name, Family: opts.Family, Title: f.title, BaseURL: f.baseURL}
+	if !builtin {
+		data.Title = opts.Family
+	}
+	if opts.BaseURL != "" {
+		data.BaseURL = opts.BaseURL
+	}
+	if !builtin || opts.Family == "openai" {
+		return data, nil
+	}
+	var err error
+	if data.ReqMap, err = modeFor("req_map", "request", "openai_chat_to_"+opts.Family); err != nil {
+		return Data{}, err
+	}
+	if data.RespMap, err = modeFor("resp_map", "response", opts.Family+"_to_openai"); err != nil {
+		return Data{}, err
+	}
+	if data.SSEParse, err = modeFor("sse_parse", "response", opts.Family+"_to_openai"); err != nil {
+		return Data{}, err
+	}
+	return data, nil
+}
+
+// modeFor returns the first built-in mode of directive with the given prefix.
+func modeFor(directive, block, prefix string) (string, error) {
+	for _, m := range dslspec.ModesByDirectiveInBlock(directive, block) {
+		if strings.HasPrefix(m, prefix) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("onr-core has no %s mode starting with %q", directive, prefix)
+}
@@ -0,0 +1,72 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderBuiltinFamiliesValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		family string
+		want   []string
+	}{
+		{family: "openai", want: []string{"auth_bearer;", "resp_passthrough;"}},
+		{family: "anthropic", want: []string{"req_map openai_chat_to_anthropic_messages;", "resp_map anthropic_to_openai_chat;", "sse_parse anthropic_to_openai_chunks;"}},
+		{family: "gemini", want: []string{"req_map openai_chat_to_gemini_generate_content;", "resp_map gemini_to_openai_chat;", "sse_parse gemini_to_openai_chat_chunks;"}},
+	} {
+		text, err := Render(Options{Name: "acme", Family: tc.family})
+		if err != nil {
+			t.Fatalf("%s: render: %v", tc.family, err)
+		}
+		if !strings.Contains(text, `provider "acme" {`) {
+			t.Fatalf("%s: provider name not rendered:\n%s", tc.family, text)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(text, want) {
+				t.Fatalf("%s: missing %q:\n%s", tc.family, want, text)
+			}
+		}
+		if diags := Validate("file:///tmp/providers/acme.conf", text); len(diags) > 0 {
+			t.Fatalf("%s: generated provider does not validate: %+v", tc.family, diags)
+		}
+	}
+}
+
+func TestRenderUserTemplateDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tmpl := "syntax \"next-router/0.1\";\nprovider \"{{.Name}}\" {\ndefaults { upstream_config { base_url = \"{{.BaseURL}}\"; } }\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "internal"+TemplateExt), []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	text, err := Render(Options{Name: "corp", Family: "internal", BaseURL: "https://llm.corp", TemplateDirs: []string{dir}})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	want := "syntax \"next-router/0.1\";\nprovider \"corp\" {\n  defaults {\n    upstream_config {\n      base_url = \"https://llm.corp\";\n    }\n  }\n}\n"
+	if text != want {
+		t.Fatalf("unexpected render:\n%s", text)
+	}
+	if got := Families([]string{dir}); strings.Join(got, ",") != "anthropic,gemini,internal,openai" {
+		t.Fatalf("unexpected families: %v", got)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Render(Options{Name: "Acme", Family: "openai"}); err == nil || !strings.Contains(err.Error(), "invalid provider name") {
+		t.Fatalf("expected invalid name error, got %v", err)
+	}
+	if _, err := Render(Options{Name: "acme", Family: "nope"}); err == nil || !strings.Contains(err.Error(), `unknown family "nope" (known: anthropic, gemini, openai)`) {
+		t.Fatalf("expected unknown family error, got %v", err)
+	}
+	if _, err := Render(Options{Name: "acme", Family: "../openai"}); err == nil {
+		t.Fatal("expected error for family with a path separator")
+	}
+}
@@ -0,0 +1,208 @@
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/httpheader"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// keyedDirectives replace an earlier directive with the same first argument,
+// e.g. two `set_header X-A ...;` lines in one block.
+var keyedDirectives = map[string]bool{
+	"set_header":         true,
+	"set_query":          true,
+	"oauth_form":         true,
+	"model_map":          true,
+	"json_set":           true,
+	"json_replace":       true,
+	"json_set_if_absent": true,
+}
+
+// listDirectives accumulate; only an exact repeat is redundant.
+var listDirectives = map[string]bool{
+	"include":                 true,
+	"del_header":              true,
+	"pass_header":             true,
+	"filter_header_values":    true,
+	"del_query":               true,
+	"json_del":                true,
+	"json_del_if_missing":     true,
+	"json_del_with_condition": true,
+	"json_rename":             true,
+	"json_wrap_input_text":    true,
+	"json_set_header_values":  true,
+	"json_filter_values":      true,
+	"sse_json_del_if":         true,
+	"usage_root":              true,
+	"usage_fact":              true,
+}
+
+// DirectiveKeyArgs returns how many leading arguments identify a directive
+// within its block: 0 for single-valued directives, 1 for keyed directives
+// such as set_header, and -1 for list directives where every argument counts.
+func DirectiveKeyArgs(name string) int {
+	switch {
+	case listDirectives[name]:
+		return -1
+	case keyedDirectives[name]:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// DirectiveKey returns the form of a keyed directive's first argument that
+// identifies it. Header names fold case as HTTP does; JSON paths, model names
+// and query or form keys are case-sensitive and compare exactly.
+func DirectiveKey(name, arg string) string {
+	if i, ok := httpheader.ArgIndex(name); ok && i == 0 {
+		return strings.ToLower(arg)
+	}
+	return arg
+}
+
+// overrideKey returns the key under which a later statement replaces stmt.
+func overrideKey(stmt *dslast.Statement) string {
+	switch DirectiveKeyArgs(stmt.Name) {
+	case -1:
+		return stmt.Name + " " + stmt.ArgsRaw()
+	case 1:
+		return stmt.Name + " " + DirectiveKey(stmt.Name, stmt.FirstArg())
+	default:
+		return stmt.Name
+	}
+}
+
+func (w *Workspace) unusedPresetDiagnostics(add addFunc) {
+	for _, doc := range w.Documents() {
+		used := map[string]bool{}
+		for _, user := range w.usersOf(doc) {
+			for _, ref := range PresetReferences(user) {
+				used[ref.Kind+" "+ref.Name] = true
+			}
+		}
+		for _, decl := range Declarations(doc) {
+			if decl.Kind == "provider" || used[decl.Kind+" "+decl.Name] {
+				continue
+			}
+			add(doc, lint.Diagnostic{
+				Range:   decl.Stmt.Range,
+				Code:    lint.RuleUnusedPreset,
+				Message: fmt.Sprintf("%s preset %q is never referenced", decl.Kind, decl.Name),
+				Tags:    []int{lint.TagUnnecessary},
+			})
+		}
+	}
+}
+
+// usersOf returns the documents that may reference presets declared in doc:
+// everything reached by any root that includes doc, or the whole workspace
+// when no root does.
+func (w *Workspace) usersOf(doc *Document) []*Document {
+	roots := w.RootsFor(doc.Path)
+	if len(roots) == 0 {
+		return w.Documents()
+	}
+	seen := map[string]bool{}
+	var out []*Document
+	for _, root := range roots {
+		for _, d := range w.closureDocs(root) {
+			if !seen[d.Path] {
+				seen[d.Path] = true
+				out = append(out, d)
+			}
+		}
+	}
+	return out
+}
+
+func (w *Workspace) overriddenDiagnostics(add addFunc) {
+	for _, doc := range w.Documents() {
+		overriddenIn(doc, doc.File.Statements, add)
+		dslast.Walk(doc.File, func(stmt *dslast.Statement, _ []*dslast.Statement) bool {
+			if stmt.IsBlock() {
+				overriddenIn(doc, stmt.Children(), add)
+			}
+			return true
+		})
+	}
+}
+
+// overriddenIn reports directives in one block that a later directive with
+// the same override key makes dead.
+func overriddenIn(doc *Document, stmts []*dslast.Statement, add addFunc) {
+	last := map[string]*dslast.Statement{}
+	for i := len(stmts) - 1; i >= 0; i-- {
+		stmt := stmts[i]
+		if stmt.IsBlock() || stmt.Name == "" {
+			continue
+		}
+		key := overrideKey(stmt)
+		later, ok := last[key]
+		if !ok {
+			last[key] = stmt
+			continue
+		}
+		verb := "overridden by"
+		if DirectiveKeyArgs(stmt.Name) < 0 {
+			verb = "repeated by"
+		}
+		add(doc, lint.Diagnostic{
+			Range:   stmt.Range,
+			Code:    lint.RuleOverriddenDirective,
+			Message: fmt.Sprintf("%s is %s the %s at %d:%d", stmt.Name, verb, later.Name, later.Range.Start.Line+1, later.Range.Start.Character+1),
+			Tags:    []int{lint.TagUnnecessary},
+			RelatedInformation: []lint.RelatedInformation{{
+				Location: lint.Location{URI: doc.URI, Range: later.Range},
+				Message:  "later " + later.Name + " takes effect",
+			}},
+		})
+	}
+}
+
+func (w *Workspace) unincludedDiagnostics(add addFunc) {
+	reached := map[string]bool{}
+	for _, root := range w.Roots() {
+		if filepath.Base(root.Path) == RootConfigName {
+			w.reach(root, reached)
+		}
+	}
+	for _, doc := range w.Documents() {
+		if reached[doc.Path] || filepath.Base(filepath.Dir(doc.Path)) != "providers" {
+			continue
+		}
+		root := ContextRoot(doc.Path)
+		if root == "" {
+			continue
+		}
+		rootDoc, ok := w.Document(root)
+		if !ok {
+			continue
+		}
+		add(doc, lint.Diagnostic{
+			Range:   fileHeadRange(doc),
+			Code:    lint.RuleUnincludedFile,
+			Message: fmt.Sprintf("provider file is not included by %s", relPath(filepath.Dir(doc.Path), root)),
+			RelatedInformation: []lint.RelatedInformation{{
+				Location: lint.Location{URI: rootDoc.URI},
+				Message:  "root config",
+			}},
+		})
+	}
+}
+
+// fileHeadRange returns the name of the first declaration, or the start of
+// the file when there is none.
+func fileHeadRange(doc *Document) dsllang.Range {
+	for _, stmt := range doc.File.Statements {
+		if stmt.Name == "provider" && len(stmt.Args) > 0 {
+			return stmt.Args[0].Range
+		}
+	}
+	return dsllang.Range{}
+}
@@ -0,0 +1,279 @@
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+const diagnosticSource = "onr-lsp"
+
+// Diagnostics runs the cross-file checks over every loaded document and
+// returns diagnostics keyed by absolute path. Severities are left unset;
+// callers apply lint configuration with lint.Filter. The result is shared and
+// must not be modified.
+func (w *Workspace) Diagnostics() map[string][]lint.Diagnostic {
+	if w.diags != nil {
+		return w.diags
+	}
+	out := map[string][]lint.Diagnostic{}
+	add := func(doc *Document, d lint.Diagnostic) {
+		d.Source = diagnosticSource
+		out[doc.Path] = append(out[doc.Path], d)
+	}
+	w.includeDiagnostics(add)
+	w.duplicateDiagnostics(add)
+	w.undefinedPresetDiagnostics(add)
+	w.unusedPresetDiagnostics(add)
+	w.overriddenDiagnostics(add)
+	w.unincludedDiagnostics(add)
+	for path := range out {
+		sortDiagnostics(out[path])
+	}
+	w.diags = out
+	return out
+}
+
+type addFunc func(doc *Document, d lint.Diagnostic)
+
+func (w *Workspace) includeDiagnostics(add addFunc) {
+	for _, doc := range w.Documents() {
+		for _, inc := range w.Includes(doc) {
+			if inc.Err == nil {
+				continue
+			}
+			add(doc, lint.Diagnostic{
+				Range:   inc.Range(),
+				Code:    lint.RuleMissingInclude,
+				Message: inc.Err.Error(),
+			})
+		}
+	}
+	for _, cycle := range w.includeCycles() {
+		last := cycle[len(cycle)-1]
+		dir := filepath.Dir(last.doc.Path)
+		names := []string{relPath(dir, last.doc.Path)}
+		for _, edge := range cycle {
+			names = append(names, relPath(dir, edge.doc.Path))
+		}
+		d := lint.Diagnostic{
+			Range:   last.inc.Range(),
+			Code:    lint.RuleIncludeCycle,
+			Message: "include cycle: " + strings.Join(names, " -> "),
+		}
+		for _, edge := range cycle[:len(cycle)-1] {
+			d.RelatedInformation = append(d.RelatedInformation, lint.RelatedInformation{
+				Location: lint.Location{URI: edge.doc.URI, Range: edge.inc.Range()},
+				Message:  "included from here",
+			})
+		}
+		add(last.doc, d)
+	}
+}
+
+type includeEdge struct {
+	doc *Document
+	inc *Include
+}
+
+// includeCycles returns each include cycle once as the chain of include
+// statements that form it, starting at the lowest path.
+func (w *Workspace) includeCycles() [][]includeEdge {
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := map[string]int{}
+	seen := map[string]struct{}{}
+	var (
+		stack  []includeEdge
+		cycles [][]includeEdge
+		visit  func(doc *Document)
+	)
+	visit = func(doc *Document) {
+		state[doc.Path] = active
+		for _, inc := range w.Includes(doc) {
+			for _, target := range inc.Targets {
+				edge := includeEdge{doc: doc, inc: inc}
+				switch state[target] {
+				case active:
+					start := 0
+					for i, e := range stack {
+						if e.doc.Path == target {
+							start = i
+							break
+						}
+					}
+					cycle := append(append([]includeEdge(nil), stack[start:]...), edge)
+					cycle = rotateCycle(cycle)
+					key := cycleKey(cycle)
+					if _, dup := seen[key]; !dup {
+						seen[key] = struct{}{}
+						cycles = append(cycles, cycle)
+					}
+				case unvisited:
+					next, ok := w.Document(target)
+					if !ok {
+						continue
+					}
+					stack = append(stack, edge)
+					visit(next)
+					stack = stack[:len(stack)-1]
+				}
+			}
+		}
+		state[doc.Path] = done
+	}
+	for _, doc := range w.Documents() {
+		if state[doc.Path] == unvisited {
+			visit(doc)
+		}
+	}
+	return cycles
+}
+
+// rotateCycle starts a cycle at its lowest path so the same cycle found from
+// different entry points is reported once and at a stable location.
+func rotateCycle(cycle []includeEdge) []includeEdge {
+	first := 0
+	for i, e := range cycle {
+		if e.doc.Path < cycle[first].doc.Path {
+			first = i
+		}
+	}
+	return append(append([]includeEdge(nil), cycle[first:]...), cycle[:first]...)
+}
+
+func cycleKey(cycle []includeEdge) string {
+	parts := make([]string, 0, len(cycle))
+	for _, e := range cycle {
+		parts = append(parts, fmt.Sprintf("%s:%d", e.doc.Path, e.inc.Stmt.Start))
+	}
+	return strings.Join(parts, "|")
+}
+
+// duplicateDiagnostics reports names declared twice within the scope of the
+// declaring document, so separate root configs may reuse provider names.
+func (w *Workspace) duplicateDiagnostics(add addFunc) {
+	for _, doc := range w.Documents() {
+		var decls map[string]map[string][]Symbol
+		for _, sym := range Declarations(doc) {
+			if decls == nil {
+				decls = declarationsIn(w.Scope(doc.Path))
+			}
+			syms := decls[sym.Kind][sym.Name]
+			if len(syms) < 2 {
+				continue
+			}
+			code, label := lint.RuleDuplicatePreset, sym.Kind+" preset"
+			if sym.Kind == "provider" {
+				code, label = lint.RuleDuplicateProvider, "provider"
+			}
+			d := lint.Diagnostic{
+				Range:   sym.Arg.Range,
+				Code:    code,
+				Message: fmt.Sprintf("duplicate %s %q; also declared in %s", label, sym.Name, otherFiles(sym, syms)),
+			}
+			for _, other := range syms {
+				if other.Stmt == sym.Stmt {
+					continue
+				}
+				d.RelatedInformation = append(d.RelatedInformation, lint.RelatedInformation{
+					Location: lint.Location{URI: other.Doc.URI, Range: other.Arg.Range},
+					Message:  fmt.Sprintf("%s %q is also declared here", label, sym.Name),
+				})
+			}
+			add(doc, d)
+		}
+	}
+}
+
+func otherFiles(sym Symbol, syms []Symbol) string {
+	dir := filepath.Dir(sym.Doc.Path)
+	var names []string
+	for _, other := range syms {
+		if other.Stmt == sym.Stmt {
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s:%d", relPath(dir, other.Doc.Path), other.Arg.Range.Start.Line+1))
+	}
+	return strings.Join(names, ", ")
+}
+
+func (w *Workspace) undefinedPresetDiagnostics(add addFunc) {
+	for _, doc := range w.Documents() {
+		var decls map[string]map[string][]Symbol
+		for _, ref := range PresetReferences(doc) {
+			if decls == nil {
+				decls = declarationsIn(w.Scope(doc.Path))
+			}
+			if IsBuiltinMode(ref) || len(decls[ref.Kind][ref.Name]) > 0 {
+				continue
+			}
+			d := lint.Diagnostic{
+				Range:   ref.Arg.Range,
+				Code:    lint.RuleUndefinedPreset,
+				Message: fmt.Sprintf("%s %q is neither a built-in mode nor a declared %s preset", ref.Stmt.Name, ref.Name, ref.Kind),
+			}
+			if near, ok := closestDeclaration(ref.Name, decls[ref.Kind]); ok {
+				d.Message += fmt.Sprintf("; did you mean %q?", near.Name)
+				d.RelatedInformation = []lint.RelatedInformation{{
+					Location: lint.Location{URI: near.Doc.URI, Range: near.Arg.Range},
+					Message:  fmt.Sprintf("%s preset %q is declared here", ref.Kind, near.Name),
+				}}
+			}
+			add(doc, d)
+		}
+	}
+}
+
+// closestDeclaration returns the declaration whose name is within a small
+// edit distance of name.
+func closestDeclaration(name string, decls map[string][]Symbol) (Symbol, bool) {
+	best, bestDist := Symbol{}, 3
+	names := make([]string, 0, len(decls))
+	for n := range decls {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if d := editDistance(name, n); d < bestDist {
+			best, bestDist = decls[n][0], d
+		}
+	}
+	return best, best.Stmt != nil
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func sortDiagnostics(diags []lint.Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Range.Start, diags[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+}
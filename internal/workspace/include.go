@@ -0,0 +1,144 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// MaxIncludeDepth mirrors the onr-core include depth limit.
+const MaxIncludeDepth = 20
+
+// Include is one resolved include statement.
+type Include struct {
+	Stmt *dslast.Statement
+	// Pattern is the include path as written, without quotes.
+	Pattern string
+	// Targets are the absolute files the include expands to, sorted.
+	Targets []string
+	// Err explains why the include could not be resolved.
+	Err error
+}
+
+// Range spans the include arguments.
+func (inc *Include) Range() (r dsllang.Range) {
+	args := inc.Stmt.Args
+	r.Start = args[0].Range.Start
+	r.End = args[len(args)-1].Range.End
+	return r
+}
+
+// Includes returns the include statements of doc with their targets.
+func (w *Workspace) Includes(doc *Document) []*Include {
+	if incs, ok := w.includes[doc.Path]; ok {
+		return incs
+	}
+	var incs []*Include
+	dslast.Walk(doc.File, func(stmt *dslast.Statement, _ []*dslast.Statement) bool {
+		if stmt.Name != "include" || stmt.IsBlock() {
+			return true
+		}
+		pattern := IncludePattern(doc.Text, stmt)
+		if pattern == "" {
+			return true
+		}
+		inc := &Include{Stmt: stmt, Pattern: pattern}
+		inc.Targets, inc.Err = w.expandInclude(doc.Path, pattern)
+		incs = append(incs, inc)
+		return true
+	})
+	w.includes[doc.Path] = incs
+	return incs
+}
+
+// IncludePattern returns the include path of stmt as onr-core reads it: a
+// single quoted string, or the raw text of all unquoted arguments.
+func IncludePattern(text string, stmt *dslast.Statement) string {
+	if len(stmt.Args) == 0 {
+		return ""
+	}
+	first := stmt.Args[0]
+	if first.Quoted {
+		return strings.TrimSpace(first.Value())
+	}
+	last := stmt.Args[len(stmt.Args)-1]
+	return strings.TrimSpace(text[first.Start:last.End])
+}
+
+// expandInclude follows onr-core include expansion: paths are relative to the
+// including file, globs must match, and directories expand to their direct
+// *.conf children. Overlay files that are not on disk are matched too.
+func (w *Workspace) expandInclude(from, pattern string) ([]string, error) {
+	full := pattern
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(filepath.Dir(from), full)
+	}
+	if strings.ContainsAny(full, "*?[") {
+		matches, err := filepath.Glob(full)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include glob %q", pattern)
+		}
+		for path := range w.overlay {
+			if ok, _ := filepath.Match(full, path); ok {
+				matches = append(matches, path)
+			}
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("include glob %q matched no files", pattern)
+		}
+		var files []string
+		for _, match := range matches {
+			if st, err := os.Stat(match); err == nil && st.IsDir() {
+				files = append(files, w.expandDir(match)...)
+				continue
+			}
+			files = append(files, match)
+		}
+		return dedupeSorted(files), nil
+	}
+	if _, ok := w.overlay[full]; ok {
+		return []string{full}, nil
+	}
+	st, err := os.Stat(full)
+	if err != nil {
+		return nil, fmt.Errorf("include target %q does not exist", pattern)
+	}
+	if st.IsDir() {
+		return w.expandDir(full), nil
+	}
+	return []string{full}, nil
+}
+
+func (w *Workspace) expandDir(dir string) []string {
+	var files []string
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() && filepath.Ext(e.Name()) == ".conf" {
+				files = append(files, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	for path := range w.overlay {
+		if filepath.Dir(path) == dir && filepath.Ext(path) == ".conf" {
+			files = append(files, path)
+		}
+	}
+	return dedupeSorted(files)
+}
+
+func dedupeSorted(in []string) []string {
+	sort.Strings(in)
+	out := in[:0]
+	for i, s := range in {
+		if i > 0 && s == in[i-1] {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package workspace
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dsllang"
+)
+
+// Rename moves a file or directory. Both paths are absolute.
+type Rename struct {
+	Old string
+	New string
+}
+
+// IncludeEdit replaces the arguments of one include statement.
+type IncludeEdit struct {
+	// Path is the including document before the renames.
+	Path    string
+	Range   dsllang.Range
+	NewText string
+}
+
+// DanglingInclude is an include that loses Target after the renames and
+// cannot be rewritten, such as a glob that no longer matches a moved file.
+type DanglingInclude struct {
+	Path    string
+	Include *Include
+	Target  string
+}
+
+// RenameIncludes returns edits that keep every resolved include pointing at
+// the same files after renames, and the includes no edit can keep intact.
+// Includes in moved documents are rewritten relative to their new location.
+func (w *Workspace) RenameIncludes(renames []Rename) ([]IncludeEdit, []DanglingInclude) {
+	var edits []IncludeEdit
+	var dangling []DanglingInclude
+	for _, doc := range w.Documents() {
+		oldDir := filepath.Dir(doc.Path)
+		newDir := filepath.Dir(RenamedPath(doc.Path, renames))
+		for _, inc := range w.Includes(doc) {
+			if inc.Err != nil {
+				continue
+			}
+			full := inc.Pattern
+			if !filepath.IsAbs(full) {
+				full = filepath.Join(oldDir, full)
+			}
+			newFull, match := renamedPattern(filepath.Clean(full), renames)
+			for _, target := range inc.Targets {
+				if moved := RenamedPath(target, renames); !match(moved) {
+					dangling = append(dangling, DanglingInclude{Path: doc.Path, Include: inc, Target: target})
+				}
+			}
+			if newFull == filepath.Clean(full) && newDir == oldDir {
+				continue
+			}
+			pattern := newFull
+			if !filepath.IsAbs(inc.Pattern) {
+				if rel, err := filepath.Rel(newDir, newFull); err == nil {
+					pattern = filepath.ToSlash(rel)
+					if strings.HasPrefix(inc.Pattern, "./") && !strings.HasPrefix(pattern, "../") {
+						pattern = "./" + pattern
+					}
+				}
+			}
+			if pattern == inc.Pattern {
+				continue
+			}
+			text := pattern
+			if inc.Stmt.Args[0].Quoted || strings.ContainsAny(pattern, " \t;{}#") {
+				text = `"` + pattern + `"`
+			}
+			edits = append(edits, IncludeEdit{Path: doc.Path, Range: inc.Range(), NewText: text})
+		}
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Path < edits[j].Path })
+	sort.SliceStable(dangling, func(i, j int) bool { return dangling[i].Path < dangling[j].Path })
+	return edits, dangling
+}
+
+// RenamedPath returns where path ends up after renames.
+func RenamedPath(path string, renames []Rename) string {
+	for _, r := range renames {
+		if path == r.Old {
+			return r.New
+		}
+		if strings.HasPrefix(path, r.Old+string(filepath.Separator)) {
+			return r.New + path[len(r.Old):]
+		}
+	}
+	return path
+}
+
+// renamedPattern moves the literal directory prefix of an absolute include
+// pattern and returns it with a function reporting whether a moved target
+// is still included by the new pattern.
+func renamedPattern(full string, renames []Rename) (string, func(string) bool) {
+	parts := strings.Split(full, string(filepath.Separator))
+	glob := len(parts)
+	for i, p := range parts {
+		if strings.ContainsAny(p, "*?[") {
+			glob = i
+			break
+		}
+	}
+	if glob == len(parts) {
+		moved := RenamedPath(full, renames)
+		return moved, func(target string) bool {
+			return target == moved || filepath.Dir(target) == moved
+		}
+	}
+	prefix := strings.Join(parts[:glob], string(filepath.Separator))
+	moved := RenamedPath(prefix, renames) + string(filepath.Separator) + strings.Join(parts[glob:], string(filepath.Separator))
+	return moved, func(target string) bool {
+		if ok, _ := filepath.Match(moved, target); ok {
+			return true
+		}
+		ok, _ := filepath.Match(moved, filepath.Dir(target))
+		return ok
+	}
+}
@@ -0,0 +1,134 @@
+package workspace
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// Roots returns the root configs among loaded documents: files that include
+// others without being included themselves, and every onr.conf.
+func (w *Workspace) Roots() []*Document {
+	included := map[string]bool{}
+	hasIncludes := map[string]bool{}
+	for _, doc := range w.Documents() {
+		for _, inc := range w.Includes(doc) {
+			hasIncludes[doc.Path] = true
+			for _, target := range inc.Targets {
+				if target != doc.Path {
+					included[target] = true
+				}
+			}
+		}
+	}
+	var out []*Document
+	for _, doc := range w.Documents() {
+		if filepath.Base(doc.Path) == RootConfigName || (hasIncludes[doc.Path] && !included[doc.Path]) {
+			out = append(out, doc)
+		}
+	}
+	return out
+}
+
+// RootsFor returns the root configs that reach path through includes. A root
+// is not listed for itself.
+func (w *Workspace) RootsFor(path string) []*Document {
+	path = absPath(path)
+	var out []*Document
+	for _, root := range w.Roots() {
+		if root.Path != path && w.closure(root)[path] {
+			out = append(out, root)
+		}
+	}
+	return out
+}
+
+// SetActiveRoot selects root as the context for analysing path when several
+// roots include it. An empty root restores the default choice.
+func (w *Workspace) SetActiveRoot(path, root string) {
+	path = absPath(path)
+	if root == "" {
+		delete(w.active, path)
+	} else {
+		w.active[path] = absPath(root)
+	}
+	w.diags = nil
+}
+
+// ActiveRoot returns the root config path is analysed under: the root chosen
+// with SetActiveRoot, else the conventional onr.conf next to it, else the
+// first root by path. Root configs are their own context.
+func (w *Workspace) ActiveRoot(path string) (*Document, bool) {
+	path = absPath(path)
+	roots := w.RootsFor(path)
+	if len(roots) == 0 {
+		for _, root := range w.Roots() {
+			if root.Path == path {
+				return root, true
+			}
+		}
+		return nil, false
+	}
+	preferred := []string{w.active[path], ContextRoot(path)}
+	for _, want := range preferred {
+		for _, root := range roots {
+			if want != "" && root.Path == want {
+				return root, true
+			}
+		}
+	}
+	return roots[0], true
+}
+
+// Scope returns the documents visible when analysing path: everything the
+// active root reaches, or the whole workspace for files outside any root.
+func (w *Workspace) Scope(path string) []*Document {
+	root, ok := w.ActiveRoot(path)
+	if !ok {
+		return w.Documents()
+	}
+	return w.closureDocs(root)
+}
+
+// closure returns the set of paths reachable from root, including root.
+func (w *Workspace) closure(root *Document) map[string]bool {
+	if set, ok := w.closures[root.Path]; ok {
+		return set
+	}
+	set := map[string]bool{}
+	w.reach(root, set)
+	w.closures[root.Path] = set
+	return set
+}
+
+// Reachable returns doc and every document it includes, directly or
+// transitively, sorted by path.
+func (w *Workspace) Reachable(doc *Document) []*Document {
+	return w.closureDocs(doc)
+}
+
+func (w *Workspace) closureDocs(root *Document) []*Document {
+	set := w.closure(root)
+	out := make([]*Document, 0, len(set))
+	for path := range set {
+		if doc, ok := w.docs[path]; ok {
+			out = append(out, doc)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// reach marks every file reachable from doc through includes.
+func (w *Workspace) reach(doc *Document, reached map[string]bool) {
+	if reached[doc.Path] {
+		return
+	}
+	reached[doc.Path] = true
+	for _, inc := range w.Includes(doc) {
+		for _, target := range inc.Targets {
+			if next, ok := w.Document(target); ok {
+				w.reach(next, reached)
+			}
+		}
+	}
+}
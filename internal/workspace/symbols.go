@@ -0,0 +1,123 @@
+package workspace
+
+import (
+	"sort"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/open-next-router/onr-core/pkg/dslspec"
+)
+
+// Symbol is a named declaration or reference in a document.
+type Symbol struct {
+	// Kind is "provider" or a preset registry block such as "usage_mode".
+	Kind string
+	Name string
+	Doc  *Document
+	Stmt *dslast.Statement
+	// Arg is the argument holding the name.
+	Arg *dslast.Arg
+	// Block is the DSL block containing a reference, e.g. "metrics".
+	Block string
+	// Owner is the file-level statement containing a reference, such as the
+	// provider or preset block that uses a preset.
+	Owner *dslast.Statement
+}
+
+// PresetKinds returns the preset registry blocks that can be declared at top
+// level and referenced by mode directives, sorted.
+func PresetKinds() []string {
+	seen := map[string]struct{}{}
+	for _, meta := range dslspec.DirectiveMetadataList() {
+		if meta.ModeRegistryBlock != "" {
+			seen[meta.ModeRegistryBlock] = struct{}{}
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for kind := range seen {
+		out = append(out, kind)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func isPresetKind(name string) bool {
+	for _, kind := range PresetKinds() {
+		if kind == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Declarations returns provider and preset blocks declared at file level.
+func Declarations(doc *Document) []Symbol {
+	var out []Symbol
+	for _, stmt := range doc.File.Statements {
+		if !stmt.IsBlock() || len(stmt.Args) == 0 {
+			continue
+		}
+		if stmt.Name != "provider" && !isPresetKind(stmt.Name) {
+			continue
+		}
+		out = append(out, Symbol{Kind: stmt.Name, Name: stmt.FirstArg(), Doc: doc, Stmt: stmt, Arg: stmt.Args[0]})
+	}
+	return out
+}
+
+// PresetReferences returns mode directives whose value is resolved through a
+// preset registry, e.g. `usage_extract my_preset;` in a metrics block.
+func PresetReferences(doc *Document) []Symbol {
+	var out []Symbol
+	dslast.Walk(doc.File, func(stmt *dslast.Statement, parents []*dslast.Statement) bool {
+		if stmt.IsBlock() || len(stmt.Args) == 0 {
+			return true
+		}
+		block := dslast.BlockName(parents)
+		kind := dslspec.DirectiveModeRegistryBlockInBlock(stmt.Name, block)
+		if kind == "" {
+			return true
+		}
+		sym := Symbol{Kind: kind, Name: stmt.FirstArg(), Doc: doc, Stmt: stmt, Arg: stmt.Args[0], Block: block}
+		if len(parents) > 0 {
+			sym.Owner = parents[0]
+		}
+		out = append(out, sym)
+		return true
+	})
+	return out
+}
+
+// IsBuiltinMode reports whether ref names a built-in mode rather than a preset.
+func IsBuiltinMode(ref Symbol) bool {
+	for _, mode := range dslspec.ModesByDirectiveInBlock(ref.Stmt.Name, ref.Block) {
+		if mode == ref.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// declarationsIn groups the declarations of docs by kind and name.
+func declarationsIn(docs []*Document) map[string]map[string][]Symbol {
+	out := map[string]map[string][]Symbol{}
+	for _, doc := range docs {
+		for _, sym := range Declarations(doc) {
+			if out[sym.Kind] == nil {
+				out[sym.Kind] = map[string][]Symbol{}
+			}
+			out[sym.Kind][sym.Name] = append(out[sym.Kind][sym.Name], sym)
+		}
+	}
+	return out
+}
+
+// Presets returns the preset names of kind visible when analysing path.
+func (w *Workspace) Presets(path, kind string) []string {
+	decls := declarationsIn(w.Scope(path))[kind]
+	out := make([]string, 0, len(decls))
+	for name := range decls {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,255 @@
+// Package workspace loads a tree of DSL files, resolves includes and runs
+// analyses that need more than one file, such as preset references and
+// duplicate provider names.
+package workspace
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/r9s-ai/onr-lsp/internal/dslast"
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+)
+
+// RootConfigName is the conventional root config file name.
+const RootConfigName = "onr.conf"
+
+// Document is one loaded DSL file.
+type Document struct {
+	// Path is absolute and clean.
+	Path string
+	URI  string
+	Text string
+	File *dslast.File
+}
+
+// Workspace holds parsed documents keyed by absolute path. Documents are read
+// from an overlay first (unsaved editor buffers), then from disk.
+type Workspace struct {
+	docs     map[string]*Document
+	missing  map[string]struct{}
+	overlay  map[string]string
+	includes map[string][]*Include
+	// active holds root configs chosen with SetActiveRoot, by document path.
+	active map[string]string
+	// closures caches include closures by root path and diags caches
+	// Diagnostics; both are reset when another document is loaded.
+	closures map[string]map[string]bool
+	diags    map[string][]lint.Diagnostic
+}
+
+// New returns an empty workspace. overlay maps file paths to text that
+// replaces the file content on disk.
+func New(overlay map[string]string) *Workspace {
+	w := &Workspace{
+		docs:     map[string]*Document{},
+		missing:  map[string]struct{}{},
+		overlay:  map[string]string{},
+		includes: map[string][]*Include{},
+		active:   map[string]string{},
+		closures: map[string]map[string]bool{},
+	}
+	for path, text := range overlay {
+		w.overlay[absPath(path)] = text
+	}
+	return w
+}
+
+// Load collects .conf files under paths, adds the root config that gives each
+// of them context, and follows includes until every reachable file is loaded.
+func Load(paths []string, overlay map[string]string) (*Workspace, error) {
+	files, err := CollectFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	w := New(overlay)
+	for path := range w.overlay {
+		files = append(files, path)
+	}
+	for _, path := range files {
+		w.Add(path)
+	}
+	return w, nil
+}
+
+// Add loads path, the root config next to it (if any) and everything they
+// include. It reports whether path itself could be loaded.
+func (w *Workspace) Add(path string) bool {
+	path = absPath(path)
+	doc, ok := w.Document(path)
+	if root := ContextRoot(path); root != "" {
+		if rootDoc, ok := w.Document(root); ok {
+			w.loadIncludes(rootDoc, 0)
+		}
+	}
+	if ok {
+		w.loadIncludes(doc, 0)
+	}
+	return ok
+}
+
+// SetOverlay replaces the text of path, as after an edit in an unsaved
+// buffer, and loads it again. Other documents are not re-read; include
+// targets are resolved again in case the new text or a new overlay file
+// changes them.
+func (w *Workspace) SetOverlay(path, text string) {
+	path = absPath(path)
+	if old, ok := w.overlay[path]; ok && old == text {
+		return
+	}
+	w.overlay[path] = text
+	delete(w.docs, path)
+	delete(w.missing, path)
+	w.includes = map[string][]*Include{}
+	w.closures = map[string]map[string]bool{}
+	w.diags = nil
+	w.Add(path)
+}
+
+func (w *Workspace) loadIncludes(doc *Document, depth int) {
+	if depth > MaxIncludeDepth {
+		return
+	}
+	for _, inc := range w.Includes(doc) {
+		for _, target := range inc.Targets {
+			if _, loaded := w.docs[target]; loaded {
+				continue
+			}
+			if next, ok := w.Document(target); ok {
+				w.loadIncludes(next, depth+1)
+			}
+		}
+	}
+}
+
+// Document returns the document at path, loading it when needed.
+func (w *Workspace) Document(path string) (*Document, bool) {
+	path = absPath(path)
+	if doc, ok := w.docs[path]; ok {
+		return doc, true
+	}
+	if _, ok := w.missing[path]; ok {
+		return nil, false
+	}
+	text, ok := w.overlay[path]
+	if !ok {
+		// #nosec G304 -- workspace files are chosen by the user or reached by includes.
+		b, err := os.ReadFile(path)
+		if err != nil {
+			w.missing[path] = struct{}{}
+			return nil, false
+		}
+		text = string(b)
+	}
+	doc := &Document{Path: path, URI: FileURI(path), Text: text, File: dslast.Parse(text)}
+	w.docs[path] = doc
+	w.closures = map[string]map[string]bool{}
+	w.diags = nil
+	return doc, true
+}
+
+// Documents returns loaded documents sorted by path.
+func (w *Workspace) Documents() []*Document {
+	out := make([]*Document, 0, len(w.docs))
+	for _, doc := range w.docs {
+		out = append(out, doc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// ContextRoot returns the root config that gives path its context: onr.conf
+// in the same directory, or in the parent of a providers/ or modes/
+// directory. It returns "" when there is none or path is the root itself.
+func ContextRoot(path string) string {
+	path = absPath(path)
+	dir := filepath.Dir(path)
+	candidates := []string{filepath.Join(dir, RootConfigName)}
+	switch filepath.Base(dir) {
+	case "providers", "modes":
+		candidates = append(candidates, filepath.Join(filepath.Dir(dir), RootConfigName))
+	}
+	for _, c := range candidates {
+		if c == path {
+			continue
+		}
+		if st, err := os.Stat(c); err == nil && !st.IsDir() {
+			return c
+		}
+	}
+	return ""
+}
+
+// CollectFiles expands files and directories into a sorted list of .conf files.
+// Hidden directories are skipped when walking.
+func CollectFiles(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	seen := map[string]struct{}{}
+	out := make([]string, 0, 16)
+	add := func(path string) {
+		if _, ok := seen[path]; ok {
+			return
+		}
+		seen[path] = struct{}{}
+		out = append(out, path)
+	}
+	for _, root := range paths {
+		st, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("stat %q: %w", root, err)
+		}
+		if !st.IsDir() {
+			add(filepath.Clean(root))
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(path) == ".conf" {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk %q: %w", root, err)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// FileURI returns a file:// URI for path.
+func FileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath(path))}).String()
+}
+
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// relPath returns path relative to dir for messages, falling back to path.
+func relPath(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,367 @@
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r9s-ai/onr-lsp/internal/lint"
+	"github.com/r9s-ai/onr-lsp/internal/testutil"
+)
+
+func loadDiagnostics(t *testing.T, root string, overlay map[string]string) map[string][]lint.Diagnostic {
+	t.Helper()
+	ws, err := Load([]string{root}, overlay)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	out := map[string][]lint.Diagnostic{}
+	for path, diags := range ws.Diagnostics() {
+		rel, _ := filepath.Rel(root, path)
+		out[filepath.ToSlash(rel)] = diags
+	}
+	return out
+}
+
+func findCode(diags []lint.Diagnostic, code string) (lint.Diagnostic, bool) {
+	for _, d := range diags {
+		if d.Code == code {
+			return d, true
+		}
+	}
+	return lint.Diagnostic{}, false
+}
+
+func TestUndefinedPresetResolvesAcrossFiles(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":         "include modes/*.conf;\ninclude providers;\n",
+		"modes/usage.conf": "usage_mode \"shared_usage\" {\n  usage_extract custom;\n}\n",
+		"providers/a.conf": "provider \"a\" {\n  defaults {\n    metrics { usage_extract shared_usage; }\n  }\n}\n",
+		"providers/b.conf": "provider \"b\" {\n  defaults {\n    metrics { usage_extract shared_usag; finish_reason_extract custom; }\n  }\n}\n",
+	})
+	diags := loadDiagnostics(t, root, nil)
+	if len(diags["providers/a.conf"]) != 0 {
+		t.Fatalf("expected no diagnostics for a.conf, got %+v", diags["providers/a.conf"])
+	}
+	d, ok := findCode(diags["providers/b.conf"], lint.RuleUndefinedPreset)
+	if !ok {
+		t.Fatalf("expected undefined-preset in b.conf, got %+v", diags["providers/b.conf"])
+	}
+	if d.Range.Start.Line != 2 || d.Range.Start.Character != 28 {
+		t.Fatalf("unexpected range: %+v", d.Range)
+	}
+	if !strings.Contains(d.Message, `did you mean "shared_usage"`) || len(d.RelatedInformation) != 1 {
+		t.Fatalf("expected suggestion with related information, got %+v", d)
+	}
+	if !strings.HasSuffix(d.RelatedInformation[0].Location.URI, "/modes/usage.conf") {
+		t.Fatalf("unexpected related location: %+v", d.RelatedInformation[0])
+	}
+	if len(diags["providers/b.conf"]) != 1 {
+		t.Fatalf("built-in mode must not be reported: %+v", diags["providers/b.conf"])
+	}
+}
+
+func TestDuplicateProvidersAndPresets(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"providers/a.conf": "provider \"openai\" {\n}\n",
+		"providers/b.conf": "provider \"openai\" {\n}\n",
+		"modes/m.conf":     "usage_mode \"u\" {\n}\nfinish_reason_mode \"u\" {\n}\nusage_mode \"u\" {\n}\n",
+	})
+	diags := loadDiagnostics(t, root, nil)
+	for _, name := range []string{"providers/a.conf", "providers/b.conf"} {
+		d, ok := findCode(diags[name], lint.RuleDuplicateProvider)
+		if !ok || len(d.RelatedInformation) != 1 {
+			t.Fatalf("expected duplicate-provider with related information in %s, got %+v", name, diags[name])
+		}
+	}
+	var dups int
+	for _, d := range diags["modes/m.conf"] {
+		if d.Code == lint.RuleDuplicatePreset {
+			dups++
+			if d.Range.Start.Line == 2 {
+				t.Fatalf("presets of different kinds must not clash: %+v", d)
+			}
+		}
+	}
+	if dups != 2 {
+		t.Fatalf("expected 2 duplicate-preset diagnostics, got %+v", diags["modes/m.conf"])
+	}
+}
+
+func TestIncludeDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":   "include a.conf;\ninclude \"missing.conf\";\ninclude nothing/*.conf;\n",
+		"a.conf":     "include b.conf;\n",
+		"b.conf":     "include a.conf;\n",
+		"other.conf": "syntax \"next-router/0.1\";\n",
+	})
+	diags := loadDiagnostics(t, root, nil)
+	var missing int
+	for _, d := range diags["onr.conf"] {
+		if d.Code == lint.RuleMissingInclude {
+			missing++
+		}
+	}
+	if missing != 2 {
+		t.Fatalf("expected 2 missing-include diagnostics, got %+v", diags["onr.conf"])
+	}
+	var cycles []lint.Diagnostic
+	for _, name := range []string{"a.conf", "b.conf"} {
+		for _, d := range diags[name] {
+			if d.Code == lint.RuleIncludeCycle {
+				cycles = append(cycles, d)
+			}
+		}
+	}
+	if len(cycles) != 1 {
+		t.Fatalf("expected one include-cycle diagnostic, got %+v", cycles)
+	}
+	if cycles[0].Message != "include cycle: b.conf -> a.conf -> b.conf" || len(cycles[0].RelatedInformation) != 1 {
+		t.Fatalf("unexpected cycle diagnostic: %+v", cycles[0])
+	}
+}
+
+func TestOverlayReplacesDiskContent(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"providers/a.conf": "provider \"a\" {\n}\n",
+		"providers/b.conf": "provider \"b\" {\n}\n",
+	})
+	overlay := map[string]string{filepath.Join(root, "providers", "b.conf"): "provider \"a\" {\n}\n"}
+	diags := loadDiagnostics(t, root, overlay)
+	if _, ok := findCode(diags["providers/a.conf"], lint.RuleDuplicateProvider); !ok {
+		t.Fatalf("expected overlay text to be analysed, got %+v", diags)
+	}
+}
+
+func TestSetOverlayReloadsOneDocument(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":         "include providers/a.conf;\n",
+		"providers/a.conf": "provider \"a\" {\n}\n",
+		"providers/b.conf": "provider \"b\" {\n}\n",
+	})
+	ws, err := Load([]string{root}, nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b := filepath.Join(root, "providers", "b.conf")
+	before, _ := ws.Document(filepath.Join(root, "onr.conf"))
+	if _, ok := findCode(ws.Diagnostics()[b], lint.RuleUnincludedFile); !ok {
+		t.Fatalf("expected b.conf to be unincluded, got %+v", ws.Diagnostics()[b])
+	}
+
+	ws.SetOverlay(filepath.Join(root, "onr.conf"), "include providers/*.conf;\n")
+	after, _ := ws.Document(filepath.Join(root, "onr.conf"))
+	if after == before || after.Text != "include providers/*.conf;\n" {
+		t.Fatalf("expected the edited document to be parsed again")
+	}
+	if other, _ := ws.Document(b); other.Text != "provider \"b\" {\n}\n" {
+		t.Fatalf("unexpected b.conf text %q", other.Text)
+	}
+	if diags := ws.Diagnostics()[b]; len(diags) != 0 {
+		t.Fatalf("expected cached diagnostics to be recomputed, got %+v", diags)
+	}
+}
+
+func TestContextRoot(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":         "",
+		"providers/a.conf": "",
+		"extra/x.conf":     "",
+	})
+	if got := ContextRoot(filepath.Join(root, "providers", "a.conf")); got != filepath.Join(root, "onr.conf") {
+		t.Fatalf("unexpected context root: %q", got)
+	}
+	if got := ContextRoot(filepath.Join(root, "extra", "x.conf")); got != "" {
+		t.Fatalf("expected no context root, got %q", got)
+	}
+	if got := ContextRoot(filepath.Join(root, "onr.conf")); got != "" {
+		t.Fatalf("root config must not be its own context, got %q", got)
+	}
+}
+
+func TestUnusedPresetIsTaggedUnnecessary(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":         "include modes;\ninclude providers;\n",
+		"modes/usage.conf": "usage_mode \"used\" {\n  usage_extract custom;\n}\nusage_mode \"stale\" {\n  usage_extract used;\n}\n",
+		"providers/a.conf": "provider \"a\" {\n  defaults {\n    metrics { usage_extract used; }\n  }\n}\n",
+	})
+	diags := loadDiagnostics(t, root, nil)
+	var unused []lint.Diagnostic
+	for _, d := range diags["modes/usage.conf"] {
+		if d.Code == lint.RuleUnusedPreset {
+			unused = append(unused, d)
+		}
+	}
+	if len(unused) != 1 || !strings.Contains(unused[0].Message, `"stale"`) {
+		t.Fatalf("expected only stale preset to be unused, got %+v", unused)
+	}
+	d := unused[0]
+	if len(d.Tags) != 1 || d.Tags[0] != lint.TagUnnecessary {
+		t.Fatalf("expected Unnecessary tag, got %+v", d.Tags)
+	}
+	if d.Range.Start.Line != 3 || d.Range.End.Line != 5 {
+		t.Fatalf("expected the whole preset block, got %+v", d.Range)
+	}
+}
+
+func TestOverriddenDirectives(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"a.conf": "provider \"a\" {\n  defaults {\n    request {\n" +
+			"      req_map openai_chat_to_openai_responses;\n" +
+			"      set_header X-A \"1\";\n" +
+			"      set_header x-a \"2\";\n" +
+			"      set_header X-B \"3\";\n" +
+			"      json_del $.a;\n" +
+			"      json_del $.b;\n" +
+			"      json_del $.a;\n" +
+			"      req_map anthropic_to_openai_chat;\n" +
+			"      json_set $.Model \"a\";\n" +
+			"      json_set $.model \"b\";\n" +
+			"      model_map GPT-4 gpt-4o;\n" +
+			"      model_map gpt-4 gpt-4o-mini;\n" +
+			"    }\n  }\n}\n",
+	})
+	diags := loadDiagnostics(t, root, nil)
+	var lines []int
+	for _, d := range diags["a.conf"] {
+		if d.Code != lint.RuleOverriddenDirective {
+			continue
+		}
+		lines = append(lines, d.Range.Start.Line)
+		if len(d.RelatedInformation) != 1 || len(d.Tags) != 1 {
+			t.Fatalf("expected related location and tag, got %+v", d)
+		}
+	}
+	want := []int{3, 4, 7}
+	if len(lines) != len(want) {
+		t.Fatalf("unexpected overridden lines: %v", lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("unexpected overridden lines: %v", lines)
+		}
+	}
+}
+
+func TestUnincludedProviderFiles(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":               "include providers/used.conf;\n",
+		"providers/used.conf":    "provider \"used\" {\n}\n",
+		"providers/stale.conf":   "provider \"stale\" {\n}\n",
+		"other/providers/x.conf": "provider \"x\" {\n}\n",
+	})
+	diags := loadDiagnostics(t, root, nil)
+	if _, ok := findCode(diags["providers/used.conf"], lint.RuleUnincludedFile); ok {
+		t.Fatalf("included provider must not be reported")
+	}
+	d, ok := findCode(diags["providers/stale.conf"], lint.RuleUnincludedFile)
+	if !ok || d.Range.Start.Character != 9 {
+		t.Fatalf("expected unincluded-file at provider name, got %+v", diags["providers/stale.conf"])
+	}
+	if _, ok := findCode(diags["other/providers/x.conf"], lint.RuleUnincludedFile); ok {
+		t.Fatalf("provider without a root config must not be reported")
+	}
+}
+
+func TestRootsScopeAnalysisToActiveRoot(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"prod/onr.conf":        "include ../shared/openai.conf;\ninclude modes.conf;\n",
+		"prod/modes.conf":      "usage_mode \"tuned\" {\n  usage_extract custom;\n}\n",
+		"prod/extra.conf":      "provider \"openai\" {\n}\n",
+		"staging/onr.conf":     "include ../shared/openai.conf;\n",
+		"staging/copy.conf":    "provider \"openai\" {\n}\n",
+		"shared/openai.conf":   "provider \"openai\" {\n  defaults {\n    metrics { usage_extract tuned; }\n  }\n}\n",
+		"shared/notes.conf":    "syntax \"next-router/0.1\";\n",
+		"standalone/only.conf": "provider \"solo\" {\n}\n",
+	})
+	ws, err := Load([]string{root}, nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	shared := filepath.Join(root, "shared", "openai.conf")
+	roots := ws.RootsFor(shared)
+	if len(roots) != 2 {
+		t.Fatalf("expected two roots for shared file, got %d", len(roots))
+	}
+	active, ok := ws.ActiveRoot(shared)
+	if !ok || active.Path != filepath.Join(root, "prod", "onr.conf") {
+		t.Fatalf("expected first root by path to be active, got %+v", active)
+	}
+	if _, ok := findCode(ws.Diagnostics()[shared], lint.RuleUndefinedPreset); ok {
+		t.Fatalf("preset from the prod root must be visible: %+v", ws.Diagnostics()[shared])
+	}
+	if got := ws.Presets(shared, "usage_mode"); len(got) != 1 || got[0] != "tuned" {
+		t.Fatalf("unexpected presets in scope: %v", got)
+	}
+
+	ws.SetActiveRoot(shared, filepath.Join(root, "staging", "onr.conf"))
+	if _, ok := findCode(ws.Diagnostics()[shared], lint.RuleUndefinedPreset); !ok {
+		t.Fatalf("preset must be undefined under the staging root: %+v", ws.Diagnostics()[shared])
+	}
+	if _, ok := findCode(ws.Diagnostics()[shared], lint.RuleDuplicateProvider); ok {
+		t.Fatalf("unincluded files of the root must not clash: %+v", ws.Diagnostics()[shared])
+	}
+	if roots := ws.RootsFor(filepath.Join(root, "standalone", "only.conf")); len(roots) != 0 {
+		t.Fatalf("standalone file must have no roots, got %d", len(roots))
+	}
+}
+
+func TestRenameIncludes(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.WriteTree(t, map[string]string{
+		"onr.conf":              "include \"providers/azure.conf\";\ninclude modes/*.conf;\ninclude shared;\n",
+		"providers/azure.conf":  "provider \"azure\" {\n}\n",
+		"modes/usage.conf":      "include ../shared/presets.conf;\n",
+		"modes/finish.conf":     "syntax \"next-router/0.1\";\n",
+		"shared/presets.conf":   "syntax \"next-router/0.1\";\n",
+		"shared/leftovers.conf": "syntax \"next-router/0.1\";\n",
+	})
+	ws, err := Load([]string{root}, nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	abs := func(name string) string { return filepath.Join(root, filepath.FromSlash(name)) }
+	edits, dangling := ws.RenameIncludes([]Rename{
+		{Old: abs("providers/azure.conf"), New: abs("providers/azure-openai.conf")},
+		{Old: abs("modes"), New: abs("presets/modes")},
+		{Old: abs("shared/leftovers.conf"), New: abs("old/leftovers.conf")},
+	})
+	var got []string
+	for _, e := range edits {
+		rel, _ := filepath.Rel(root, e.Path)
+		got = append(got, fmt.Sprintf("%s:%d: %s", filepath.ToSlash(rel), e.Range.Start.Line+1, e.NewText))
+	}
+	want := []string{
+		`modes/usage.conf:1: ../../shared/presets.conf`,
+		`onr.conf:1: "providers/azure-openai.conf"`,
+		`onr.conf:2: presets/modes/*.conf`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected edits:\n%s", strings.Join(got, "\n"))
+	}
+	if len(dangling) != 1 || dangling[0].Include.Pattern != "shared" || dangling[0].Target != abs("shared/leftovers.conf") {
+		t.Fatalf("unexpected dangling includes: %+v", dangling)
+	}
+}
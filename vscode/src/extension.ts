@@ -27,15 +27,117 @@ export async function activate(context: vscode.ExtensionContext): Promise<void>
       { scheme: "file", language: "onr-dsl", pattern: "**/providers.conf" },
     ],
     synchronize: {
-      configurationSection: "onrLsp",
+      configurationSection: ["onrLsp", "files.exclude"],
+      fileEvents: [
+        vscode.workspace.createFileSystemWatcher("**/*.conf"),
+        vscode.workspace.createFileSystemWatcher("**/.onr-lsp.json"),
+      ],
     },
   };
 
   client = new LanguageClient("onr-lsp", "ONR LSP", serverOptions, clientOptions);
   context.subscriptions.push(client);
+  context.subscriptions.push(
+    vscode.commands.registerCommand("onrLsp.selectActiveRoot", selectActiveRoot),
+    vscode.workspace.registerTextDocumentContentProvider(mergedScheme, mergedProvider),
+    vscode.commands.registerCommand("onrLsp.showMergedConfig", showMergedConfig),
+    vscode.commands.registerCommand("onrLsp.scaffoldProvider", scaffoldProvider),
+  );
   await client.start();
 }
 
+interface DocumentRoots {
+  roots: string[];
+  active?: string;
+}
+
+// selectActiveRoot lets the user pick which root config includes the current
+// fragment when several do; diagnostics and completion follow that root.
+async function selectActiveRoot(): Promise<void> {
+  const editor = vscode.window.activeTextEditor;
+  if (!client || !editor) {
+    return;
+  }
+  const uri = editor.document.uri.toString();
+  const res = await client.sendRequest<DocumentRoots>("onr/documentRoots", { textDocument: { uri } });
+  if (res.roots.length === 0) {
+    void vscode.window.showInformationMessage("No root config includes this file.");
+    return;
+  }
+  const items = res.roots.map((root) => ({
+    label: vscode.workspace.asRelativePath(vscode.Uri.parse(root)),
+    description: root === res.active ? "active" : undefined,
+    root,
+  }));
+  const picked = await vscode.window.showQuickPick(items, { placeHolder: "Root config used to analyse this file" });
+  if (!picked) {
+    return;
+  }
+  await client.sendRequest("workspace/executeCommand", { command: "onr.selectRoot", arguments: [uri, picked.root] });
+}
+
+interface MergedDocument {
+  uri: string;
+  text: string;
+}
+
+const mergedScheme = "onr-merged";
+
+// mergedProvider serves onr-merged: documents whose query holds the URI of
+// the file to merge; the server merges its active root config.
+const mergedProvider: vscode.TextDocumentContentProvider = {
+  async provideTextDocumentContent(uri: vscode.Uri): Promise<string> {
+    if (!client) {
+      return "";
+    }
+    try {
+      const res = await client.sendRequest<MergedDocument>("onr/mergedDocument", { textDocument: { uri: uri.query } });
+      return res.text;
+    } catch (err) {
+      return `# merge failed: ${err instanceof Error ? err.message : String(err)}\n`;
+    }
+  },
+};
+
+async function showMergedConfig(): Promise<void> {
+  const editor = vscode.window.activeTextEditor;
+  if (!editor) {
+    return;
+  }
+  const source = editor.document.uri;
+  const uri = vscode.Uri.from({
+    scheme: mergedScheme,
+    path: `${path.basename(source.fsPath, ".conf")}.merged.conf`,
+    query: source.toString(),
+  });
+  const doc = await vscode.workspace.openTextDocument(uri);
+  await vscode.languages.setTextDocumentLanguage(doc, "onr-dsl");
+  await vscode.window.showTextDocument(doc, { preview: true, viewColumn: vscode.ViewColumn.Beside });
+}
+
+// scaffoldProvider fills the current empty provider file from a family
+// template; new providers/*.conf files are filled by the server on creation.
+async function scaffoldProvider(): Promise<void> {
+  const editor = vscode.window.activeTextEditor;
+  if (!client || !editor) {
+    return;
+  }
+  const family = await vscode.window.showQuickPick(["openai", "anthropic", "gemini"], {
+    placeHolder: "Provider family template",
+  });
+  if (!family) {
+    return;
+  }
+  try {
+    await client.sendRequest("workspace/executeCommand", {
+      command: "onr.scaffold",
+      arguments: [editor.document.uri.toString(), family],
+    });
+  } catch (err) {
+    void vscode.window.showErrorMessage(err instanceof Error ? err.message : String(err));
+  }
+}
+
 export async function deactivate(): Promise<void> {
   if (!client) {
     return;